@@ -122,3 +122,55 @@ func (h *AccountHandler) DeleteAccount(c *gin.Context) {
 	}
 	c.Status(http.StatusNoContent)
 }
+
+func (h *AccountHandler) ListTransactions(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+	txns, err := h.AccountService.ListTransactions(userID.(string), c.Param("id"))
+	if err != nil {
+		respondWithError(c, err, "Failed to fetch account transactions")
+		return
+	}
+	c.JSON(http.StatusOK, txns)
+}
+
+func (h *AccountHandler) CreateTransaction(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+	var req models.AccountTransactionCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+	txn, err := h.AccountService.CreateTransaction(userID.(string), c.Param("id"), req)
+	if err != nil {
+		respondWithError(c, err, "Failed to create account transaction")
+		return
+	}
+	c.JSON(http.StatusCreated, txn)
+}
+
+func (h *AccountHandler) DeleteTransaction(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+	txnID := c.Param("transactionId")
+	deleted, err := h.AccountService.DeleteTransaction(userID.(string), c.Param("id"), txnID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to delete account transaction"))
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, models.NewAppError(models.ErrCodeNotFound, "Account transaction not found"))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"asset-diary/models"
+	"asset-diary/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AllocationHandler struct {
+	service services.AllocationServiceInterface
+}
+
+func NewAllocationHandler(service services.AllocationServiceInterface) *AllocationHandler {
+	return &AllocationHandler{service: service}
+}
+
+// GetAllocation handles GET /allocation
+// Returns current value grouped by asset type, currency, account, market and tag in the default currency
+func (h *AllocationHandler) GetAllocation(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	report, err := h.service.GetAllocation(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to calculate allocation"))
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
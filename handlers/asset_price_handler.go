@@ -11,11 +11,11 @@ import (
 
 // AssetPriceHandler handles requests related to asset prices (stocks and cryptocurrencies)
 type AssetPriceHandler struct {
-	assetPriceService interfaces.AssetPriceServiceInterface
+	assetPriceService interfaces.BatchAssetPriceServiceInterface
 }
 
 // NewAssetPriceHandler creates a new instance of AssetPriceHandler
-func NewAssetPriceHandler(assetPriceService interfaces.AssetPriceServiceInterface) *AssetPriceHandler {
+func NewAssetPriceHandler(assetPriceService interfaces.BatchAssetPriceServiceInterface) *AssetPriceHandler {
 	return &AssetPriceHandler{
 		assetPriceService: assetPriceService,
 	}
@@ -59,6 +59,18 @@ func (h *AssetPriceHandler) GetCryptoPrice(c *gin.Context) {
 	c.JSON(http.StatusOK, tickerInfo)
 }
 
+// GetPrices handles POST /prices/batch
+// Quotes up to 100 symbols at once; each item carries either its quote or the error that prevented it
+func (h *AssetPriceHandler) GetPrices(c *gin.Context) {
+	var req models.PriceBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": h.assetPriceService.GetPrices(req.Items)})
+}
+
 // handlePriceError handles common price-related errors
 func (h *AssetPriceHandler) handlePriceError(c *gin.Context, err error) {
 	errMsg := err.Error()
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"asset-diary/models"
+	"asset-diary/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BenchmarkHandler struct {
+	service services.BenchmarkServiceInterface
+}
+
+func NewBenchmarkHandler(service services.BenchmarkServiceInterface) *BenchmarkHandler {
+	return &BenchmarkHandler{service: service}
+}
+
+// ListBenchmarks handles GET /benchmarks
+// Returns the available benchmarks with the user's selection flagged
+func (h *BenchmarkHandler) ListBenchmarks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	benchmarks, err := h.service.ListBenchmarks(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to fetch benchmarks"))
+		return
+	}
+
+	c.JSON(http.StatusOK, benchmarks)
+}
+
+// UpdateBenchmarks handles PUT /benchmarks
+// Replaces the user's selected benchmarks with the given codes
+func (h *BenchmarkHandler) UpdateBenchmarks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var req models.UserBenchmarksUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	benchmarks, err := h.service.SetUserBenchmarks(userID.(string), req.Codes)
+	if err != nil {
+		respondWithError(c, err, "Failed to update benchmarks")
+		return
+	}
+
+	c.JSON(http.StatusOK, benchmarks)
+}
+
+// GetComparison handles GET /benchmarks/comparison?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD&codes=TAIEX,SP500
+// Returns the portfolio and benchmark series normalized to 100 at the start of the range
+func (h *BenchmarkHandler) GetComparison(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	startDate, endDate, ok := bindDateRange(c)
+	if !ok {
+		return
+	}
+
+	var codes []string
+	if raw := c.Query("codes"); raw != "" {
+		codes = strings.Split(raw, ",")
+	}
+
+	comparison, err := h.service.GetComparison(userID.(string), codes, startDate, endDate)
+	if err != nil {
+		respondWithError(c, err, "Failed to compare with benchmarks")
+		return
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
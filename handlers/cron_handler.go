@@ -12,15 +12,24 @@ import (
 type CronHandler struct {
 	exchangeRateService services.ExchangeRateServiceInterface
 	assetValueService   services.DailyTotalAssetValueServiceInterface
+	snapshotJobService  services.SnapshotJobServiceInterface
+	historicalPriceSvc  services.HistoricalPriceServiceInterface
+	symbolService       services.SymbolServiceInterface
 }
 
 func NewCronHandler(
 	exchangeRateService services.ExchangeRateServiceInterface,
 	assetValueService services.DailyTotalAssetValueServiceInterface,
+	snapshotJobService services.SnapshotJobServiceInterface,
+	historicalPriceSvc services.HistoricalPriceServiceInterface,
+	symbolService services.SymbolServiceInterface,
 ) *CronHandler {
 	return &CronHandler{
 		exchangeRateService: exchangeRateService,
 		assetValueService:   assetValueService,
+		snapshotJobService:  snapshotJobService,
+		historicalPriceSvc:  historicalPriceSvc,
+		symbolService:       symbolService,
 	}
 }
 
@@ -61,3 +70,107 @@ func (h *CronHandler) RecordDailyAssets(c *gin.Context) {
 
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// RecordClosingPrices godoc
+// @Summary Record closing prices
+// @Description Stores the recent daily prices of every symbol held by any user and of the benchmarks
+// @Tags cron
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /cron/record-closing-prices [post]
+func (h *CronHandler) RecordClosingPrices(c *gin.Context) {
+	if err := h.historicalPriceSvc.RecordClosingPrices(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "failed to record closing prices"))
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// RefreshSymbols godoc
+// @Summary Refresh the symbol master
+// @Description Reloads the symbol master from the exchange code lists and the symbols data file
+// @Tags cron
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /cron/refresh-symbols [post]
+func (h *CronHandler) RefreshSymbols(c *gin.Context) {
+	if err := h.symbolService.RefreshSymbols(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "failed to refresh symbols"))
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ImportSymbols godoc
+// @Summary Import symbols
+// @Description Stores the symbols of a CSV data file with the header asset_type,symbol,name_en,name_zh,exchange,currency
+// @Tags cron
+// @Accept text/csv
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]int
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /cron/import-symbols [post]
+func (h *CronHandler) ImportSymbols(c *gin.Context) {
+	imported, err := h.symbolService.ImportSymbols(c.Request.Body)
+	if err != nil {
+		respondWithError(c, err, "failed to import symbols")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"imported": imported})
+}
+
+// BackfillDailyAssets godoc
+// @Summary Backfill daily asset values
+// @Description Queues a rebuild of past daily asset values for one user, or for all users when userId is empty
+// @Tags cron
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.CronSnapshotBackfillRequest false "Backfill range"
+// @Success 202 {array} models.SnapshotJobResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /cron/backfill-daily-assets [post]
+func (h *CronHandler) BackfillDailyAssets(c *gin.Context) {
+	var req models.CronSnapshotBackfillRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+			return
+		}
+	}
+
+	var jobs []models.SnapshotJob
+	if req.UserID != "" {
+		job, err := h.snapshotJobService.StartBackfill(req.UserID, req.SnapshotBackfillRequest)
+		if err != nil {
+			respondWithError(c, err, "failed to start backfill")
+			return
+		}
+		jobs = []models.SnapshotJob{*job}
+	} else {
+		var err error
+		jobs, err = h.snapshotJobService.StartBackfillForAllUsers(req.SnapshotBackfillRequest)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "failed to start backfill"))
+			return
+		}
+	}
+
+	response := make([]models.SnapshotJobResponse, len(jobs))
+	for i, job := range jobs {
+		response[i] = newSnapshotJobResponse(job)
+	}
+	c.JSON(http.StatusAccepted, response)
+}
@@ -21,8 +21,8 @@ func NewDailyTotalAssetValueHandler(service services.DailyTotalAssetValueService
 }
 
 type GetUserDailyTotalAssetValuesRequest struct {
-	StartDate string `form:"start_date" binding:"required,datetime=2006-01-02"`
-	EndDate   string `form:"end_date" binding:"required,datetime=2006-01-02"`
+	StartDate string `form:"start_date" binding:"omitempty,datetime=2006-01-02"` // defaults to 30 days before end_date
+	EndDate   string `form:"end_date" binding:"omitempty,datetime=2006-01-02"`   // defaults to today in the user's timezone
 }
 
 func (h *DailyTotalAssetValueHandler) GetUserDailyTotalAssetValues(c *gin.Context) {
@@ -38,28 +38,94 @@ func (h *DailyTotalAssetValueHandler) GetUserDailyTotalAssetValues(c *gin.Contex
 		return
 	}
 
-	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	var startDate, endDate time.Time
+	if req.StartDate != "" {
+		startDate, _ = time.Parse("2006-01-02", req.StartDate)
+	}
+	if req.EndDate != "" {
+		endDate, _ = time.Parse("2006-01-02", req.EndDate)
+	}
+
+	if !startDate.IsZero() && !endDate.IsZero() && endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, "end_date must be after or equal to start_date"))
+		return
+	}
+
+	assetValues, err := h.DailyTotalAssetValueService.GetUserDailyTotalAssetValues(userID.(string), startDate, endDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, "Invalid start_date format. Use YYYY-MM-DD"))
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to fetch daily total asset values"))
 		return
 	}
 
-	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	c.JSON(http.StatusOK, assetValues)
+}
+
+// GetBreakdown handles GET /daily-total-assets/:date/breakdown
+// Returns the holding and account components of the snapshot recorded on the date
+func (h *DailyTotalAssetValueHandler) GetBreakdown(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", c.Param("date"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, "Invalid end_date format. Use YYYY-MM-DD"))
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, "Invalid date format. Use YYYY-MM-DD"))
 		return
 	}
 
-	if endDate.Before(startDate) {
-		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, "end_date must be after or equal to start_date"))
+	breakdown, err := h.DailyTotalAssetValueService.GetBreakdown(userID.(string), date)
+	if err != nil {
+		respondWithError(c, err, "Failed to fetch daily asset breakdown")
 		return
 	}
 
-	assetValues, err := h.DailyTotalAssetValueService.GetUserDailyTotalAssetValues(userID.(string), startDate, endDate)
+	c.JSON(http.StatusOK, breakdown)
+}
+
+// GetTickerSeries handles GET /daily-total-assets/tickers/:ticker?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD&asset_type=stock
+// Returns the daily quantity, price and value of a ticker summed over all accounts
+func (h *DailyTotalAssetValueHandler) GetTickerSeries(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	startDate, endDate, ok := bindDateRange(c)
+	if !ok {
+		return
+	}
+
+	points, err := h.DailyTotalAssetValueService.GetTickerSeries(userID.(string), c.Query("asset_type"), c.Param("ticker"), startDate, endDate)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to fetch daily total asset values"))
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to fetch ticker series"))
 		return
 	}
 
-	c.JSON(http.StatusOK, assetValues)
+	c.JSON(http.StatusOK, points)
+}
+
+// GetAccountSeries handles GET /daily-total-assets/accounts/:id?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD
+// Returns the daily cash and holdings value of an account in the default currency
+func (h *DailyTotalAssetValueHandler) GetAccountSeries(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	startDate, endDate, ok := bindDateRange(c)
+	if !ok {
+		return
+	}
+
+	points, err := h.DailyTotalAssetValueService.GetAccountSeries(userID.(string), c.Param("id"), startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to fetch account series"))
+		return
+	}
+
+	c.JSON(http.StatusOK, points)
 }
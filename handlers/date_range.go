@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"asset-diary/models"
+	"asset-diary/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DateRangeRequest struct {
+	StartDate string `form:"start_date" binding:"required,datetime=2006-01-02"`
+	EndDate   string `form:"end_date" binding:"required,datetime=2006-01-02"`
+}
+
+// bindDateRange parses the required start_date and end_date query parameters and writes a
+// 400 response when they are missing or out of order
+func bindDateRange(c *gin.Context) (time.Time, time.Time, bool) {
+	var req DateRangeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+		return time.Time{}, time.Time{}, false
+	}
+
+	startDate, _ := time.Parse("2006-01-02", req.StartDate)
+	endDate, _ := time.Parse("2006-01-02", req.EndDate)
+	if endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, "end_date must be after or equal to start_date"))
+		return time.Time{}, time.Time{}, false
+	}
+
+	return startDate, endDate, true
+}
+
+// parseLocalDate reads a YYYY-MM-DD calendar date, or an RFC 3339 timestamp which is reduced to
+// the calendar date it falls on in loc
+func parseLocalDate(value string, loc *time.Location) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return services.LocalDate(timestamp, loc), nil
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"asset-diary/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondWithError writes AppErrors returned by services with a matching status code,
+// and hides any other error behind a generic internal error message
+func respondWithError(c *gin.Context, err error, internalMessage string) {
+	if appErr, ok := err.(*models.AppError); ok {
+		switch appErr.Code {
+		case models.ErrCodeInvalidRequest:
+			c.JSON(http.StatusBadRequest, appErr)
+			return
+		case models.ErrCodeNotFound:
+			c.JSON(http.StatusNotFound, appErr)
+			return
+		case models.ErrCodeUnauthorized:
+			c.JSON(http.StatusUnauthorized, appErr)
+			return
+		}
+	}
+	c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, internalMessage))
+}
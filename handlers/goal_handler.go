@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+
+	"asset-diary/models"
+	"asset-diary/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GoalHandler struct {
+	service services.GoalServiceInterface
+}
+
+func NewGoalHandler(service services.GoalServiceInterface) *GoalHandler {
+	return &GoalHandler{service: service}
+}
+
+type GoalProjectionRequest struct {
+	Simulations int      `form:"simulations" binding:"omitempty,gte=1,lte=10000"`
+	Seed        *int64   `form:"seed"`
+	Volatility  *float64 `form:"volatility" binding:"omitempty,gte=0,lte=200"`
+	Years       int      `form:"years" binding:"omitempty,gte=1,lte=60"`
+}
+
+// ListGoals handles GET /goals
+func (h *GoalHandler) ListGoals(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	goals, err := h.service.ListGoals(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to fetch goals"))
+		return
+	}
+
+	c.JSON(http.StatusOK, goals)
+}
+
+// CreateGoal handles POST /goals
+func (h *GoalHandler) CreateGoal(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var req models.GoalCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	goal, err := h.service.CreateGoal(userID.(string), req)
+	if err != nil {
+		respondWithError(c, err, "Failed to create goal")
+		return
+	}
+
+	c.JSON(http.StatusCreated, goal)
+}
+
+// UpdateGoal handles PUT /goals/:id
+func (h *GoalHandler) UpdateGoal(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var req models.GoalUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	goal, err := h.service.UpdateGoal(userID.(string), c.Param("id"), req)
+	if err != nil {
+		respondWithError(c, err, "Failed to update goal")
+		return
+	}
+
+	c.JSON(http.StatusOK, goal)
+}
+
+// DeleteGoal handles DELETE /goals/:id
+func (h *GoalHandler) DeleteGoal(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	id := c.Param("id")
+	deleted, err := h.service.DeleteGoal(userID.(string), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to delete goal"))
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, models.NewAppError(models.ErrCodeNotFound, "Goal not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "deleted": true})
+}
+
+// GetProjection handles GET /goals/projection?simulations=1000&seed=42&volatility=15&years=10
+// Runs a Monte Carlo simulation and returns the probability of reaching each goal with percentile paths
+func (h *GoalHandler) GetProjection(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var req GoalProjectionRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	projection, err := h.service.GetProjection(userID.(string), services.ProjectionOptions{
+		Simulations:          req.Simulations,
+		Seed:                 req.Seed,
+		VolatilityPercentage: req.Volatility,
+		Years:                req.Years,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to project goals"))
+		return
+	}
+
+	c.JSON(http.StatusOK, projection)
+}
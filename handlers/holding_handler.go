@@ -19,6 +19,7 @@ func NewHoldingHandler(holdingService services.HoldingServiceInterface) *Holding
 }
 
 // ListHoldings handles GET /holdings
+// With ?tag=<id or name> the holdings are aggregated from the trades carrying that tag only
 func (h *HoldingHandler) ListHoldings(c *gin.Context) {
 	userID, ok := c.Get("user_id")
 	if !ok {
@@ -26,7 +27,13 @@ func (h *HoldingHandler) ListHoldings(c *gin.Context) {
 		return
 	}
 
-	holdings, err := h.holdingService.ListHoldings(userID.(string))
+	var holdings []models.Holding
+	var err error
+	if tag := c.Query("tag"); tag != "" {
+		holdings, err = h.holdingService.ListHoldingsByTag(userID.(string), tag)
+	} else {
+		holdings, err = h.holdingService.ListHoldings(userID.(string))
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, err.Error()))
 		return
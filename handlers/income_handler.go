@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"asset-diary/models"
+	"asset-diary/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IncomeHandler struct {
+	service services.IncomeServiceInterface
+}
+
+func NewIncomeHandler(service services.IncomeServiceInterface) *IncomeHandler {
+	return &IncomeHandler{service: service}
+}
+
+type IncomeRequest struct {
+	StartDate string `form:"start_date" binding:"omitempty,datetime=2006-01-02"`
+	EndDate   string `form:"end_date" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// GetIncome handles GET /income?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD
+// Dates default to the trailing year; the projection always covers the coming twelve months
+func (h *IncomeHandler) GetIncome(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var req IncomeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	// missing dates are left zero for the service, which defaults them in the user's timezone
+	var startDate, endDate time.Time
+	if req.StartDate != "" {
+		startDate, _ = time.Parse("2006-01-02", req.StartDate)
+	}
+	if req.EndDate != "" {
+		endDate, _ = time.Parse("2006-01-02", req.EndDate)
+	}
+	if !startDate.IsZero() && !endDate.IsZero() && endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, "end_date must be after or equal to start_date"))
+		return
+	}
+
+	report, err := h.service.GetIncome(userID.(string), startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to calculate income"))
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
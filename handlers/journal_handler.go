@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"asset-diary/models"
+	"asset-diary/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JournalHandler struct {
+	service services.JournalServiceInterface
+}
+
+func NewJournalHandler(journalService services.JournalServiceInterface) *JournalHandler {
+	return &JournalHandler{
+		service: journalService,
+	}
+}
+
+type ListJournalEntriesRequest struct {
+	Query     string `form:"q"`
+	Tag       string `form:"tag"`
+	Ticker    string `form:"ticker"`
+	TradeID   string `form:"trade_id"`
+	StartDate string `form:"start_date" binding:"omitempty,datetime=2006-01-02"`
+	EndDate   string `form:"end_date" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// ListEntries handles GET /journal-entries
+// Supports full-text search with ?q= and filtering by tag, ticker, trade_id and entry date range
+func (h *JournalHandler) ListEntries(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var req ListJournalEntriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	filter := models.JournalEntryFilter{
+		Query:   req.Query,
+		Tag:     req.Tag,
+		Ticker:  req.Ticker,
+		TradeID: req.TradeID,
+	}
+	if req.StartDate != "" {
+		startDate, _ := time.Parse("2006-01-02", req.StartDate)
+		filter.StartDate = &startDate
+	}
+	if req.EndDate != "" {
+		endDate, _ := time.Parse("2006-01-02", req.EndDate)
+		filter.EndDate = &endDate
+	}
+
+	entries, err := h.service.ListEntries(userID.(string), filter)
+	if err != nil {
+		respondWithError(c, err, "Failed to fetch journal entries")
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// GetEntry handles GET /journal-entries/:id
+func (h *JournalHandler) GetEntry(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	entry, err := h.service.GetEntry(userID.(string), c.Param("id"))
+	if err != nil {
+		respondWithError(c, err, "Failed to fetch journal entry")
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// CreateEntry handles POST /journal-entries
+func (h *JournalHandler) CreateEntry(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var req models.JournalEntryCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	entry, err := h.service.CreateEntry(userID.(string), req)
+	if err != nil {
+		respondWithError(c, err, "Failed to create journal entry")
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// UpdateEntry handles PUT /journal-entries/:id
+func (h *JournalHandler) UpdateEntry(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var req models.JournalEntryUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	entry, err := h.service.UpdateEntry(userID.(string), c.Param("id"), req)
+	if err != nil {
+		respondWithError(c, err, "Failed to update journal entry")
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteEntry handles DELETE /journal-entries/:id
+func (h *JournalHandler) DeleteEntry(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	id := c.Param("id")
+	if err := h.service.DeleteEntry(userID.(string), id); err != nil {
+		respondWithError(c, err, "Failed to delete journal entry")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "deleted": true})
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+
+	"asset-diary/models"
+	"asset-diary/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PerformanceHandler struct {
+	service services.PerformanceServiceInterface
+}
+
+func NewPerformanceHandler(service services.PerformanceServiceInterface) *PerformanceHandler {
+	return &PerformanceHandler{service: service}
+}
+
+// GetPerformance handles GET /performance?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD
+// Returns time-weighted and money-weighted (XIRR) returns for the portfolio, accounts and holdings
+func (h *PerformanceHandler) GetPerformance(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	startDate, endDate, ok := bindDateRange(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.GetPerformance(userID.(string), startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to calculate performance"))
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetNetWorthChange handles GET /performance/change?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD
+// Explains the change in net worth between the first and last snapshot of the period
+func (h *PerformanceHandler) GetNetWorthChange(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	startDate, endDate, ok := bindDateRange(c)
+	if !ok {
+		return
+	}
+
+	change, err := h.service.GetNetWorthChange(userID.(string), startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to decompose net worth change"))
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+// GetReturnCalendar handles GET /performance/calendar?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD
+// Returns daily, monthly and yearly time-weighted returns shaped for calendar heatmaps
+func (h *PerformanceHandler) GetReturnCalendar(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	startDate, endDate, ok := bindDateRange(c)
+	if !ok {
+		return
+	}
+
+	calendar, err := h.service.GetReturnCalendar(userID.(string), startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to calculate return calendar"))
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
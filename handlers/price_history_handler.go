@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"asset-diary/models"
+	"asset-diary/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PriceHistoryHandler struct {
+	service        services.HistoricalPriceServiceInterface
+	profileService services.ProfileServiceInterface
+}
+
+func NewPriceHistoryHandler(service services.HistoricalPriceServiceInterface, profileService services.ProfileServiceInterface) *PriceHistoryHandler {
+	return &PriceHistoryHandler{service: service, profileService: profileService}
+}
+
+type PriceHistoryRequest struct {
+	StartDate string `form:"start_date" binding:"omitempty,datetime=2006-01-02"`
+	EndDate   string `form:"end_date" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// GetPriceHistory handles GET /prices/:asset_type/:symbol/history?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD
+// Dates default to the year up to the user's local today; ranges not yet stored are fetched from the upstream provider
+func (h *PriceHistoryHandler) GetPriceHistory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	assetType := c.Param("asset_type")
+	if assetType != "stock" && assetType != "crypto" {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, "asset_type must be stock or crypto"))
+		return
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(c.Param("symbol")))
+
+	var req PriceHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	loc, err := h.profileService.GetLocation(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to get user timezone"))
+		return
+	}
+	endDate := services.LocalDate(time.Now(), loc)
+	if req.EndDate != "" {
+		endDate, _ = time.Parse("2006-01-02", req.EndDate)
+	}
+	startDate := endDate.AddDate(-1, 0, 0)
+	if req.StartDate != "" {
+		startDate, _ = time.Parse("2006-01-02", req.StartDate)
+	}
+	if endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, "end_date must be after or equal to start_date"))
+		return
+	}
+
+	prices, err := h.service.GetPriceHistory(assetType, symbol, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to get price history"))
+		return
+	}
+
+	c.JSON(http.StatusOK, prices)
+}
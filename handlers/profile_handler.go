@@ -45,6 +45,7 @@ func (h *ProfileHandler) GetProfile(c *gin.Context) {
 				YearsInvesting:                       profile.InvestmentProfile.YearsInvesting,
 				MonthlyCashFlow:                      profile.InvestmentProfile.MonthlyCashFlow,
 				DefaultCurrency:                      profile.InvestmentProfile.DefaultCurrency,
+				Timezone:                             profile.InvestmentProfile.Timezone,
 			},
 		})
 	} else {
@@ -92,7 +93,7 @@ func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
 
 	profile, err := h.profileService.UpdateProfile(userID.(string), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, err.Error()))
+		respondWithError(c, err, err.Error())
 		return
 	}
 
@@ -107,6 +108,7 @@ func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
 			YearsInvesting:                       profile.InvestmentProfile.YearsInvesting,
 			MonthlyCashFlow:                      profile.InvestmentProfile.MonthlyCashFlow,
 			DefaultCurrency:                      profile.InvestmentProfile.DefaultCurrency,
+			Timezone:                             profile.InvestmentProfile.Timezone,
 		},
 	})
 }
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+
+	"asset-diary/models"
+	"asset-diary/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RebalanceHandler struct {
+	service services.RebalanceServiceInterface
+}
+
+func NewRebalanceHandler(service services.RebalanceServiceInterface) *RebalanceHandler {
+	return &RebalanceHandler{service: service}
+}
+
+type RebalanceRequest struct {
+	NewCash float64 `form:"new_cash" binding:"gte=0"`
+	OddLots bool    `form:"odd_lots"`
+}
+
+// ListTargets handles GET /allocation/targets
+func (h *RebalanceHandler) ListTargets(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	targets, err := h.service.ListTargets(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to fetch allocation targets"))
+		return
+	}
+
+	c.JSON(http.StatusOK, targets)
+}
+
+// UpdateTargets handles PUT /allocation/targets
+// Replaces all targets; weights are percentages of the whole portfolio and may not exceed 100 in total
+func (h *RebalanceHandler) UpdateTargets(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var req models.AllocationTargetsUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	targets, err := h.service.SetTargets(userID.(string), req)
+	if err != nil {
+		respondWithError(c, err, "Failed to update allocation targets")
+		return
+	}
+
+	c.JSON(http.StatusOK, targets)
+}
+
+// GetRebalance handles GET /rebalance?new_cash=0&odd_lots=false
+// Suggests buy/sell orders for targets outside their tolerance band. Taiwan stocks are sized in
+// board lots of 1000 shares unless odd_lots is set.
+func (h *RebalanceHandler) GetRebalance(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var req RebalanceRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	report, err := h.service.GetRebalance(userID.(string), req.NewCash, req.OddLots)
+	if err != nil {
+		respondWithError(c, err, "Failed to calculate rebalancing")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
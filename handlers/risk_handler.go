@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"asset-diary/models"
+	"asset-diary/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RiskHandler struct {
+	service services.RiskServiceInterface
+}
+
+func NewRiskHandler(service services.RiskServiceInterface) *RiskHandler {
+	return &RiskHandler{service: service}
+}
+
+type RiskRequest struct {
+	StartDate    string  `form:"start_date" binding:"omitempty,datetime=2006-01-02"`
+	EndDate      string  `form:"end_date" binding:"omitempty,datetime=2006-01-02"`
+	RiskFreeRate float64 `form:"risk_free_rate"`
+}
+
+// GetRisk handles GET /risk?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD&risk_free_rate=1.5
+// Dates default to the trailing year; risk_free_rate is an annual percentage used for Sharpe and Sortino
+func (h *RiskHandler) GetRisk(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var req RiskRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	// missing dates are left zero for the service, which defaults them in the user's timezone
+	var startDate, endDate time.Time
+	if req.StartDate != "" {
+		startDate, _ = time.Parse("2006-01-02", req.StartDate)
+	}
+	if req.EndDate != "" {
+		endDate, _ = time.Parse("2006-01-02", req.EndDate)
+	}
+	if !startDate.IsZero() && !endDate.IsZero() && endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, "end_date must be after or equal to start_date"))
+		return
+	}
+
+	report, err := h.service.GetRisk(userID.(string), startDate, endDate, req.RiskFreeRate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to calculate risk metrics"))
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
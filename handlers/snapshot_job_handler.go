@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+
+	"asset-diary/models"
+	"asset-diary/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SnapshotJobHandler struct {
+	service services.SnapshotJobServiceInterface
+}
+
+func NewSnapshotJobHandler(service services.SnapshotJobServiceInterface) *SnapshotJobHandler {
+	return &SnapshotJobHandler{service: service}
+}
+
+func newSnapshotJobResponse(job models.SnapshotJob) models.SnapshotJobResponse {
+	return models.SnapshotJobResponse{
+		SnapshotJob:        job,
+		ProgressPercentage: job.ProgressPercentage(),
+	}
+}
+
+// StartBackfill handles POST /daily-total-assets/backfill
+func (h *SnapshotJobHandler) StartBackfill(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var req models.SnapshotBackfillRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+			return
+		}
+	}
+
+	job, err := h.service.StartBackfill(userID.(string), req)
+	if err != nil {
+		respondWithError(c, err, "Failed to start backfill")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, newSnapshotJobResponse(*job))
+}
+
+// ListJobs handles GET /daily-total-assets/backfill
+func (h *SnapshotJobHandler) ListJobs(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	jobs, err := h.service.ListJobs(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to fetch backfill jobs"))
+		return
+	}
+
+	response := make([]models.SnapshotJobResponse, len(jobs))
+	for i, job := range jobs {
+		response[i] = newSnapshotJobResponse(job)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetJob handles GET /daily-total-assets/backfill/:id
+func (h *SnapshotJobHandler) GetJob(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	job, err := h.service.GetJob(userID.(string), c.Param("id"))
+	if err != nil {
+		respondWithError(c, err, "Failed to fetch backfill job")
+		return
+	}
+
+	c.JSON(http.StatusOK, newSnapshotJobResponse(*job))
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+
+	"asset-diary/models"
+	"asset-diary/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StatementHandler struct {
+	service services.StatementServiceInterface
+}
+
+func NewStatementHandler(service services.StatementServiceInterface) *StatementHandler {
+	return &StatementHandler{service: service}
+}
+
+type StatementRequest struct {
+	Period string `form:"period" binding:"required"`
+	Format string `form:"format" binding:"omitempty,oneof=json html"`
+}
+
+// GetStatement handles GET /reports/statements?period=YYYY-MM|YYYY&format=json|html
+func (h *StatementHandler) GetStatement(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var req StatementRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	statement, err := h.service.GetStatement(userID.(string), req.Period)
+	if err != nil {
+		respondWithError(c, err, "Failed to generate statement")
+		return
+	}
+
+	if req.Format != "html" {
+		c.JSON(http.StatusOK, statement)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := services.WriteStatementHTML(&buf, statement); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to write statement"))
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"asset-diary/models"
+	"asset-diary/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SymbolHandler struct {
+	service services.SymbolServiceInterface
+}
+
+func NewSymbolHandler(service services.SymbolServiceInterface) *SymbolHandler {
+	return &SymbolHandler{service: service}
+}
+
+// SearchSymbols handles GET /symbols/search?q=...&asset_type=stock|crypto&limit=10
+// Matches codes and English or Chinese names by prefix, substring and small typos
+func (h *SymbolHandler) SearchSymbols(c *gin.Context) {
+	var req models.SymbolSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	symbols, err := h.service.SearchSymbols(req.Query, req.AssetType, req.Limit)
+	if err != nil {
+		respondWithError(c, err, "Failed to search symbols")
+		return
+	}
+
+	c.JSON(http.StatusOK, symbols)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+
+	"asset-diary/models"
+	"asset-diary/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	service services.TagServiceInterface
+}
+
+func NewTagHandler(tagService services.TagServiceInterface) *TagHandler {
+	return &TagHandler{
+		service: tagService,
+	}
+}
+
+// ListTags handles GET /tags
+func (h *TagHandler) ListTags(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	tags, err := h.service.ListTags(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to fetch tags"))
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// CreateTag handles POST /tags
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var req models.TagCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	tag, err := h.service.CreateTag(userID.(string), req)
+	if err != nil {
+		respondWithError(c, err, "Failed to create tag")
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+// UpdateTag handles PUT /tags/:id
+func (h *TagHandler) UpdateTag(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var req models.TagUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	tag, err := h.service.UpdateTag(userID.(string), c.Param("id"), req)
+	if err != nil {
+		respondWithError(c, err, "Failed to update tag")
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// DeleteTag handles DELETE /tags/:id, removing the tag from every trade it was attached to
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	id := c.Param("id")
+	deleted, err := h.service.DeleteTag(userID.(string), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to delete tag"))
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, models.NewAppError(models.ErrCodeNotFound, "Tag not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "deleted": true})
+}
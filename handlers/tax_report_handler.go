@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"asset-diary/models"
+	"asset-diary/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TaxReportHandler struct {
+	service services.TaxReportServiceInterface
+}
+
+func NewTaxReportHandler(service services.TaxReportServiceInterface) *TaxReportHandler {
+	return &TaxReportHandler{service: service}
+}
+
+type TaxReportRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=json csv html"`
+}
+
+// GetTaxReport handles GET /reports/tax/:year?format=json|csv|html
+// The CSV is sent as an attachment and the HTML is a standalone page meant to be printed
+func (h *TaxReportHandler) GetTaxReport(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewAppError(models.ErrCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, "Invalid year"))
+		return
+	}
+
+	var req TaxReportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	report, err := h.service.GetTaxReport(userID.(string), year)
+	if err != nil {
+		respondWithError(c, err, "Failed to generate tax report")
+		return
+	}
+
+	switch req.Format {
+	case "csv":
+		var buf bytes.Buffer
+		if err := services.WriteTaxReportCSV(&buf, report); err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to write tax report"))
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=tax-report-%d.csv", year))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+	case "html":
+		var buf bytes.Buffer
+		if err := services.WriteTaxReportHTML(&buf, report); err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to write tax report"))
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+	default:
+		c.JSON(http.StatusOK, report)
+	}
+}
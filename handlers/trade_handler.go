@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"net/http"
-	"time"
 
 	"asset-diary/models"
 	"asset-diary/services"
@@ -12,16 +11,18 @@ import (
 )
 
 type TradeHandler struct {
-	service services.TradeServiceInterface
+	service        services.TradeServiceInterface
+	profileService services.ProfileServiceInterface
 }
 
-func NewTradeHandler(tradeService services.TradeServiceInterface) *TradeHandler {
+func NewTradeHandler(tradeService services.TradeServiceInterface, profileService services.ProfileServiceInterface) *TradeHandler {
 	return &TradeHandler{
-		service: tradeService,
+		service:        tradeService,
+		profileService: profileService,
 	}
 }
 
-// List all trades for a given account or user
+// List all trades for a given account or user, optionally only those labelled with ?tag=<id or name>
 func (h *TradeHandler) ListTrades(c *gin.Context) {
 	userID, ok := c.Get("user_id")
 	if !ok {
@@ -29,7 +30,13 @@ func (h *TradeHandler) ListTrades(c *gin.Context) {
 		return
 	}
 
-	trades, err := h.service.ListTrades(userID.(string))
+	var trades []models.Trade
+	var err error
+	if tag := c.Query("tag"); tag != "" {
+		trades, err = h.service.ListTradesByTag(userID.(string), tag)
+	} else {
+		trades, err = h.service.ListTrades(userID.(string))
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to fetch trades"))
 		return
@@ -49,6 +56,7 @@ func (h *TradeHandler) ListTrades(c *gin.Context) {
 			Currency:   trade.Currency,
 			AccountID:  trade.AccountID,
 			Reason:     trade.Reason,
+			Tags:       trade.Tags,
 			CreatedAt:  trade.CreatedAt,
 		})
 	}
@@ -74,9 +82,20 @@ func (h *TradeHandler) CreateTrade(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, "Invalid or unauthorized account_id"))
 		return
 	}
-	tradeDate, err := time.Parse("2006-01-02", req.TradeDate)
+	// Verify tags belong to user
+	okTags, err := h.service.AreTagsOwnedByUser(req.TagIDs, userID.(string))
+	if err != nil || !okTags {
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, "Invalid or unauthorized tagIds"))
+		return
+	}
+	loc, err := h.profileService.GetLocation(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to get user timezone"))
+		return
+	}
+	tradeDate, err := parseLocalDate(req.TradeDate, loc)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, "Invalid tradeDate format, use YYYY-MM-DD"))
+		c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, "Invalid tradeDate format, use YYYY-MM-DD or RFC 3339"))
 		return
 	}
 	trade := models.Trade{
@@ -92,6 +111,9 @@ func (h *TradeHandler) CreateTrade(c *gin.Context) {
 		AccountID:  req.AccountID,
 		Reason:     req.Reason,
 	}
+	for _, tagID := range req.TagIDs {
+		trade.Tags = append(trade.Tags, models.Tag{ID: tagID})
+	}
 	createdTrade, err := h.service.CreateTrade(userID.(string), trade)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to create trade"))
@@ -109,6 +131,7 @@ func (h *TradeHandler) CreateTrade(c *gin.Context) {
 		Currency:   createdTrade.Currency,
 		AccountID:  createdTrade.AccountID,
 		Reason:     createdTrade.Reason,
+		Tags:       createdTrade.Tags,
 		CreatedAt:  createdTrade.CreatedAt,
 	}
 	c.JSON(http.StatusCreated, tradeResponse)
@@ -140,6 +163,26 @@ func (h *TradeHandler) UpdateTrade(c *gin.Context) {
 			return
 		}
 	}
+	if req.TradeDate != "" {
+		loc, err := h.profileService.GetLocation(userID.(string))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to get user timezone"))
+			return
+		}
+		tradeDate, err := parseLocalDate(req.TradeDate, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, "Invalid tradeDate format, use YYYY-MM-DD or RFC 3339"))
+			return
+		}
+		req.TradeDate = tradeDate.Format("2006-01-02")
+	}
+	if req.TagIDs != nil {
+		okTags, err := h.service.AreTagsOwnedByUser(*req.TagIDs, userID.(string))
+		if err != nil || !okTags {
+			c.JSON(http.StatusBadRequest, models.NewAppError(models.ErrCodeInvalidRequest, "Invalid or unauthorized tagIds"))
+			return
+		}
+	}
 	updatedTrade, err := h.service.UpdateTrade(userID.(string), id, req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.NewAppError(models.ErrCodeInternal, "Failed to update trade"))
@@ -161,6 +204,7 @@ func (h *TradeHandler) UpdateTrade(c *gin.Context) {
 		Currency:   updatedTrade.Currency,
 		AccountID:  updatedTrade.AccountID,
 		Reason:     updatedTrade.Reason,
+		Tags:       updatedTrade.Tags,
 		CreatedAt:  updatedTrade.CreatedAt,
 	}
 	c.JSON(http.StatusOK, tradeResponse)
@@ -6,7 +6,6 @@ import (
 	"net/http"
 	"os"
 	"strings"
-	"time"
 
 	"asset-diary/db"
 	"asset-diary/handlers"
@@ -23,13 +22,6 @@ import (
 )
 
 func main() {
-	// Set the timezone to Asia/Taipei (UTC+8)
-	loc, err := time.LoadLocation("Asia/Taipei")
-	if err != nil {
-		log.Fatalf("Error loading location: %v", err)
-	}
-	time.Local = loc // Set the default timezone for the application
-
 	env := os.Getenv("ENV")
 	if env == "" {
 		env = "development"
@@ -143,25 +135,50 @@ func main() {
 	profileRepo := repositories.NewProfileRepository(dbConn)
 	tradeRepo := repositories.NewTradeRepository(dbConn)
 	accountRepo := repositories.NewAccountRepository(dbConn)
+	accountTransactionRepo := repositories.NewAccountTransactionRepository(dbConn)
 	authRepo := repositories.NewAuthRepository(dbConn)
 	userRepo := repositories.NewUserRepository(dbConn)
 	priceCacheRepo := repositories.NewPriceCacheRepository(redisClient)
 	exchangeRateRepo := repositories.NewExchangeRateRepository(dbConn)
+	exchangeRateHistoryRepo := repositories.NewExchangeRateHistoryRepository(dbConn)
 	userDailyTotalAssetValueRepo := repositories.NewUserDailyTotalAssetValueRepository(dbConn)
 	waitingListRepo := repositories.NewWaitingListRepository(dbConn)
+	journalRepo := repositories.NewJournalRepository(dbConn)
+	tagRepo := repositories.NewTagRepository(dbConn)
+	benchmarkRepo := repositories.NewBenchmarkRepository(dbConn)
+	allocationTargetRepo := repositories.NewAllocationTargetRepository(dbConn)
+	goalRepo := repositories.NewGoalRepository(dbConn)
+	priceHistoryRepo := repositories.NewPriceHistoryRepository(dbConn)
+	snapshotJobRepo := repositories.NewSnapshotJobRepository(dbConn)
+	symbolRepo := repositories.NewSymbolRepository(dbConn)
 
 	// Initialize services
 	userService := services.NewUserService(userRepo)
 	authService := services.NewAuthService(authRepo, userService)
 	profileService := services.NewProfileService(profileRepo)
-	accountService := services.NewAccountService(accountRepo)
-	tradeService := services.NewTradeService(tradeRepo)
+	accountService := services.NewAccountService(accountRepo, accountTransactionRepo)
 	geminiChatService := services.NewGeminiChatService()
 	geminiAssetPriceService := services.NewGeminiAssetPriceService(geminiChatService)
-	assetPriceService := services.NewAssetPriceService()
-	// fallbackPriceService := services.NewFallbackPriceService(assetPriceService, geminiAssetPriceService)
-	assetPriceServiceCacheDecorator := services.NewPriceServiceCacheDecorator(assetPriceService, priceCacheRepo)
-	exchangeRateService := services.NewExchangeRateService(exchangeRateRepo, supportedCurrencies)
+	assetPriceService := services.NewAssetPriceService(symbolRepo)
+	priceProviderRegistry, err := services.NewPriceProviderRegistry(assetPriceService, geminiAssetPriceService)
+	if err != nil {
+		log.Fatalf("Failed to configure price providers: %v", err)
+	}
+	assetPriceServiceCacheDecorator := services.NewPriceServiceCacheDecorator(priceProviderRegistry, priceCacheRepo)
+	exchangeRateService := services.NewExchangeRateService(exchangeRateRepo, exchangeRateHistoryRepo, supportedCurrencies)
+	historicalPriceService := services.NewHistoricalPriceService(priceHistoryRepo, tradeRepo, assetPriceService)
+	symbolService := services.NewSymbolService(symbolRepo)
+	snapshotJobService := services.NewSnapshotJobService(
+		snapshotJobRepo,
+		userDailyTotalAssetValueRepo,
+		tradeRepo,
+		accountService,
+		historicalPriceService,
+		exchangeRateService,
+		profileService,
+		userService,
+	)
+	tradeService := services.NewTradeService(tradeRepo, snapshotJobService)
 	holdingService := services.NewHoldingService(
 		tradeService,
 		assetPriceServiceCacheDecorator,
@@ -175,15 +192,82 @@ func main() {
 		exchangeRateService,
 		profileService,
 		userService,
+		snapshotJobService,
 	)
 	waitingListService := services.NewWaitingListService(waitingListRepo)
+	journalService := services.NewJournalService(journalRepo, tradeService, dailyAssetService)
+	tagService := services.NewTagService(tagRepo)
+	performanceService := services.NewPerformanceService(
+		dailyAssetService,
+		accountService,
+		tradeService,
+		profileService,
+		exchangeRateService,
+	)
+	benchmarkService := services.NewBenchmarkService(
+		benchmarkRepo,
+		historicalPriceService,
+		dailyAssetService,
+		accountService,
+		tradeService,
+		profileService,
+		exchangeRateService,
+	)
+	allocationService := services.NewAllocationService(
+		holdingService,
+		accountService,
+		tradeService,
+		tagService,
+		profileService,
+		exchangeRateService,
+	)
+	rebalanceService := services.NewRebalanceService(
+		allocationTargetRepo,
+		holdingService,
+		accountService,
+		tradeService,
+		tagService,
+		profileService,
+		exchangeRateService,
+	)
+	riskService := services.NewRiskService(
+		dailyAssetService,
+		accountService,
+		tradeService,
+		profileService,
+		exchangeRateService,
+	)
+	incomeService := services.NewIncomeService(
+		accountService,
+		holdingService,
+		tradeService,
+		profileService,
+		exchangeRateService,
+	)
+	taxReportService := services.NewTaxReportService(tradeService, accountService, exchangeRateService)
+	statementService := services.NewStatementService(
+		dailyAssetService,
+		accountService,
+		tradeService,
+		profileService,
+		exchangeRateService,
+	)
+	goalService := services.NewGoalService(
+		goalRepo,
+		holdingService,
+		accountService,
+		tradeService,
+		dailyAssetService,
+		profileService,
+		exchangeRateService,
+	)
 
 	// Initialize handlers
-	cronHandler := handlers.NewCronHandler(exchangeRateService, dailyAssetService)
+	cronHandler := handlers.NewCronHandler(exchangeRateService, dailyAssetService, snapshotJobService, historicalPriceService, symbolService)
 	authHandler := handlers.NewAuthHandler(authService, userService)
 	profileHandler := handlers.NewProfileHandler(profileService, userService)
 	accountHandler := handlers.NewAccountHandler(accountService, exchangeRateService, profileService)
-	tradeHandler := handlers.NewTradeHandler(tradeService)
+	tradeHandler := handlers.NewTradeHandler(tradeService, profileService)
 	holdingHandler := handlers.NewHoldingHandler(holdingService)
 	assetPriceHandler := handlers.NewAssetPriceHandler(assetPriceServiceCacheDecorator)
 	geminiTestHandler := handlers.NewGeminiTestHandler(geminiChatService, geminiAssetPriceService)
@@ -191,6 +275,20 @@ func main() {
 	exchangeRateHandler := handlers.NewExchangeRateHandler(exchangeRateService)
 	dailyTotalAssetValueHandler := handlers.NewDailyTotalAssetValueHandler(dailyAssetService)
 	waitingListHandler := handlers.NewWaitingListHandler(waitingListService)
+	journalHandler := handlers.NewJournalHandler(journalService)
+	tagHandler := handlers.NewTagHandler(tagService)
+	performanceHandler := handlers.NewPerformanceHandler(performanceService)
+	benchmarkHandler := handlers.NewBenchmarkHandler(benchmarkService)
+	allocationHandler := handlers.NewAllocationHandler(allocationService)
+	rebalanceHandler := handlers.NewRebalanceHandler(rebalanceService)
+	riskHandler := handlers.NewRiskHandler(riskService)
+	goalHandler := handlers.NewGoalHandler(goalService)
+	snapshotJobHandler := handlers.NewSnapshotJobHandler(snapshotJobService)
+	incomeHandler := handlers.NewIncomeHandler(incomeService)
+	taxReportHandler := handlers.NewTaxReportHandler(taxReportService)
+	statementHandler := handlers.NewStatementHandler(statementService)
+	priceHistoryHandler := handlers.NewPriceHistoryHandler(historicalPriceService, profileService)
+	symbolHandler := handlers.NewSymbolHandler(symbolService)
 
 	// Initialize Redis handler
 	redisHandler := handlers.NewRedisHandler()
@@ -210,9 +308,24 @@ func main() {
 		cronHandler,
 		redisHandler,
 		waitingListHandler,
+		journalHandler,
+		tagHandler,
+		performanceHandler,
+		benchmarkHandler,
+		allocationHandler,
+		rebalanceHandler,
+		riskHandler,
+		goalHandler,
+		snapshotJobHandler,
+		incomeHandler,
+		taxReportHandler,
+		statementHandler,
+		priceHistoryHandler,
+		symbolHandler,
 	)
 
 	go exchangeRateService.FetchAndStoreRates()
+	snapshotJobService.Start()
 
 	app.GET("/kaithhealthcheck", healthCheckHandler.HealthCheck) // for leapcell
 	app.GET("/swagger/*any", ginSwaggerHandler())
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS journal_entry_trades;
+DROP TABLE IF EXISTS journal_entries;
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    entry_date DATE,
+    asset_type VARCHAR(20),
+    ticker VARCHAR(50),
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(body, '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce(ticker, '')), 'A')
+    ) STORED,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_journal_entries_user_id_entry_date ON journal_entries(user_id, entry_date);
+CREATE INDEX IF NOT EXISTS idx_journal_entries_user_id_ticker ON journal_entries(user_id, ticker);
+CREATE INDEX IF NOT EXISTS idx_journal_entries_tags ON journal_entries USING GIN(tags);
+CREATE INDEX IF NOT EXISTS idx_journal_entries_search_vector ON journal_entries USING GIN(search_vector);
+
+CREATE TABLE IF NOT EXISTS journal_entry_trades (
+    journal_entry_id UUID NOT NULL REFERENCES journal_entries(id) ON DELETE CASCADE,
+    trade_id UUID NOT NULL REFERENCES trades(id) ON DELETE CASCADE,
+    PRIMARY KEY (journal_entry_id, trade_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_journal_entry_trades_trade_id ON journal_entry_trades(trade_id);
+
+COMMENT ON TABLE journal_entries IS 'Investment journal entries written by users, optionally linked to a date, a ticker and trades';
+COMMENT ON COLUMN journal_entries.search_vector IS 'Full-text search document built from title, body and ticker';
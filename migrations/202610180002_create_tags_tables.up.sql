@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    color VARCHAR(20),
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_user_id_lower_name ON tags(user_id, LOWER(name));
+
+CREATE TABLE IF NOT EXISTS trade_tags (
+    trade_id UUID NOT NULL REFERENCES trades(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (trade_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_trade_tags_tag_id ON trade_tags(tag_id);
+
+COMMENT ON TABLE tags IS 'User-defined tags and strategy labels that can be attached to trades';
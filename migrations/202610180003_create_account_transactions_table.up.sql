@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS account_transactions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    amount DECIMAL(20, 8) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    transaction_date DATE NOT NULL,
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_transactions_user_id_date ON account_transactions(user_id, transaction_date);
+CREATE INDEX IF NOT EXISTS idx_account_transactions_account_id ON account_transactions(account_id);
+
+COMMENT ON TABLE account_transactions IS 'Cash movements into and out of accounts, used as external cash flows for performance calculations';
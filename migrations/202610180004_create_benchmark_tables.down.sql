@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_benchmarks;
+DROP TABLE IF EXISTS benchmark_prices;
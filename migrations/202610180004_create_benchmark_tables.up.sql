@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS benchmark_prices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    asset_type VARCHAR(20) NOT NULL,
+    symbol VARCHAR(50) NOT NULL,
+    date DATE NOT NULL,
+    close DECIMAL(24, 8) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(asset_type, symbol, date)
+);
+
+CREATE TABLE IF NOT EXISTS user_benchmarks (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code VARCHAR(20) NOT NULL,
+    PRIMARY KEY (user_id, code)
+);
+
+COMMENT ON TABLE benchmark_prices IS 'Daily closing prices of benchmark symbols used for portfolio comparison';
+COMMENT ON TABLE user_benchmarks IS 'Benchmarks each user compares their portfolio against';
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS allocation_targets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_type VARCHAR(20) NOT NULL CHECK (target_type IN ('asset_type', 'ticker', 'tag')),
+    target_key VARCHAR(100) NOT NULL,
+    weight DECIMAL(7, 4) NOT NULL CHECK (weight >= 0 AND weight <= 100),
+    tolerance DECIMAL(7, 4) NOT NULL CHECK (tolerance >= 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, target_type, target_key)
+);
+
+COMMENT ON TABLE allocation_targets IS 'Target portfolio weights per asset type, ticker or tag used for rebalancing';
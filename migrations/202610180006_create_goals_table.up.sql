@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS goals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    target_amount DECIMAL(24, 8) NOT NULL CHECK (target_amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    target_date DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_goals_user_id ON goals(user_id);
+
+COMMENT ON TABLE goals IS 'Financial goals with a target amount and date used for Monte Carlo projections';
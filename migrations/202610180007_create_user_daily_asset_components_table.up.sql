@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS user_daily_asset_components (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    component_type VARCHAR(10) NOT NULL CHECK (component_type IN ('holding', 'account')),
+    account_id UUID NOT NULL,
+    asset_type VARCHAR(20),
+    ticker VARCHAR(50),
+    name VARCHAR(255) NOT NULL,
+    quantity DECIMAL(24, 8) NOT NULL,
+    price DECIMAL(24, 8) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    value DECIMAL(24, 8) NOT NULL,
+    fx_rate DECIMAL(24, 10),
+    value_in_default_currency DECIMAL(24, 8)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_daily_asset_components_user_id_date ON user_daily_asset_components(user_id, date);
+CREATE INDEX IF NOT EXISTS idx_user_daily_asset_components_user_id_ticker ON user_daily_asset_components(user_id, ticker, date);
+CREATE INDEX IF NOT EXISTS idx_user_daily_asset_components_user_id_account_id ON user_daily_asset_components(user_id, account_id, date);
+
+COMMENT ON TABLE user_daily_asset_components IS 'Per-holding and per-account lines of the daily total asset value snapshots';
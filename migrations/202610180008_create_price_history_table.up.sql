@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS price_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    asset_type VARCHAR(20) NOT NULL,
+    symbol VARCHAR(50) NOT NULL,
+    date DATE NOT NULL,
+    open DECIMAL(24, 8),
+    high DECIMAL(24, 8),
+    low DECIMAL(24, 8),
+    close DECIMAL(24, 8) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(asset_type, symbol, date)
+);
+
+COMMENT ON TABLE price_history IS 'Daily prices of tickers used to value portfolios on past dates';
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS exchange_rate_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    base_currency VARCHAR(10) NOT NULL,
+    target_currency VARCHAR(10) NOT NULL,
+    date DATE NOT NULL,
+    rate DOUBLE PRECISION NOT NULL,
+    UNIQUE(base_currency, target_currency, date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_exchange_rate_history_base_currency_date ON exchange_rate_history(base_currency, date);
+
+COMMENT ON TABLE exchange_rate_history IS 'Daily exchange rates used to convert values on past dates';
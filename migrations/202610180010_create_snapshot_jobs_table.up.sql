@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS snapshot_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    overwrite BOOLEAN NOT NULL DEFAULT FALSE,
+    total_days INTEGER NOT NULL DEFAULT 0,
+    processed_days INTEGER NOT NULL DEFAULT 0,
+    written_days INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_snapshot_jobs_user_id ON snapshot_jobs(user_id);
+CREATE INDEX IF NOT EXISTS idx_snapshot_jobs_status ON snapshot_jobs(status);
+
+COMMENT ON TABLE snapshot_jobs IS 'Background jobs that backfill or recompute daily asset snapshots';
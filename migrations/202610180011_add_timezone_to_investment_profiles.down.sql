@@ -0,0 +1 @@
+ALTER TABLE investment_profiles DROP COLUMN IF EXISTS timezone;
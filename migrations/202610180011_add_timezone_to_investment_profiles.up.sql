@@ -0,0 +1 @@
+ALTER TABLE investment_profiles ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'Asia/Taipei';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_account_transactions_user_id_type;
+ALTER TABLE account_transactions DROP COLUMN IF EXISTS ticker;
+ALTER TABLE account_transactions DROP COLUMN IF EXISTS asset_type;
@@ -0,0 +1,6 @@
+ALTER TABLE account_transactions ADD COLUMN IF NOT EXISTS asset_type VARCHAR(20);
+ALTER TABLE account_transactions ADD COLUMN IF NOT EXISTS ticker VARCHAR(50);
+
+CREATE INDEX IF NOT EXISTS idx_account_transactions_user_id_type ON account_transactions(user_id, type);
+
+COMMENT ON COLUMN account_transactions.ticker IS 'Paying security of dividend transactions';
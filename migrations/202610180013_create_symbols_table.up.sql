@@ -0,0 +1,19 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE IF NOT EXISTS symbols (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    asset_type VARCHAR(20) NOT NULL,
+    symbol VARCHAR(50) NOT NULL,
+    name_en VARCHAR(255) NOT NULL DEFAULT '',
+    name_zh VARCHAR(255) NOT NULL DEFAULT '',
+    exchange VARCHAR(20) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(asset_type, symbol)
+);
+
+CREATE INDEX IF NOT EXISTS idx_symbols_symbol_trgm ON symbols USING gin (symbol gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_symbols_name_en_trgm ON symbols USING gin (name_en gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_symbols_name_zh_trgm ON symbols USING gin (name_zh gin_trgm_ops);
+
+COMMENT ON TABLE symbols IS 'Symbol master of tradable tickers used for search and autocomplete';
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS benchmark_prices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    asset_type VARCHAR(20) NOT NULL,
+    symbol VARCHAR(50) NOT NULL,
+    date DATE NOT NULL,
+    close DECIMAL(24, 8) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(asset_type, symbol, date)
+);
+
+COMMENT ON TABLE benchmark_prices IS 'Daily closing prices of benchmark symbols used for portfolio comparison';
@@ -0,0 +1,2 @@
+-- benchmark closes are read from price_history, which records the benchmarks with every held symbol
+DROP TABLE IF EXISTS benchmark_prices;
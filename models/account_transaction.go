@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+const (
+	AccountTransactionTypeDeposit    = "deposit"
+	AccountTransactionTypeWithdrawal = "withdrawal"
+	AccountTransactionTypeDividend   = "dividend"
+	AccountTransactionTypeInterest   = "interest"
+	AccountTransactionTypeFee        = "fee"
+)
+
+// AccountTransaction is a cash movement into or out of an account. Deposits and withdrawals are
+// external cash flows used to separate contributions from investment performance. Dividends and
+// interest are income earned by the portfolio; dividends name the paying security. Fees are
+// costs charged to the account, such as custody or platform fees.
+type AccountTransaction struct {
+	ID              string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	UserID          string    `gorm:"type:uuid;not null;index" json:"-"`
+	AccountID       string    `gorm:"type:uuid;not null;index" json:"accountId"`
+	Account         Account   `gorm:"foreignKey:AccountID;references:ID;onUpdate:CASCADE;onDelete:CASCADE" json:"-"`
+	Type            string    `gorm:"not null" json:"type"`
+	Amount          float64   `gorm:"type:decimal(20,8);not null" json:"amount"` // always positive, Type gives the direction
+	Currency        string    `gorm:"not null" json:"currency"`
+	TransactionDate time.Time `gorm:"type:date;not null" json:"transactionDate"`
+	AssetType       *string   `json:"assetType,omitempty"`
+	Ticker          *string   `json:"ticker,omitempty"`
+	Note            *string   `json:"note,omitempty"`
+	CreatedAt       time.Time `gorm:"not null;default:current_timestamp" json:"createdAt"`
+}
+
+func (AccountTransaction) TableName() string {
+	return "account_transactions"
+}
+
+// SignedAmount returns the amount as seen by the account balance: positive for money coming in
+func (t AccountTransaction) SignedAmount() float64 {
+	if t.Type == AccountTransactionTypeWithdrawal || t.Type == AccountTransactionTypeFee {
+		return -t.Amount
+	}
+	return t.Amount
+}
+
+// IsIncome reports whether the transaction is investment income rather than a contribution
+func (t AccountTransaction) IsIncome() bool {
+	return t.Type == AccountTransactionTypeDividend || t.Type == AccountTransactionTypeInterest
+}
+
+type AccountTransactionCreateRequest struct {
+	Type            string  `json:"type" binding:"required,oneof=deposit withdrawal dividend interest fee"`
+	Amount          float64 `json:"amount" binding:"required,gt=0"`
+	TransactionDate string  `json:"transactionDate" binding:"required,datetime=2006-01-02"`
+	AssetType       string  `json:"assetType" binding:"omitempty,oneof=stock crypto"` // dividends only, defaults to stock
+	Ticker          string  `json:"ticker"`                                           // required for dividends
+	Note            *string `json:"note"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// AllocationBucket is the share of the portfolio held in one group, valued in the default currency
+type AllocationBucket struct {
+	Key        string  `json:"key"`
+	Name       string  `json:"name"`
+	Value      float64 `json:"value"`
+	Percentage float64 `json:"percentage"`
+}
+
+// AllocationReport groups current holdings and account cash in several ways. Every grouping except
+// ByTag sums to TotalValue; a trade can carry several tags, so tag buckets may overlap.
+// Positions whose currency has no exchange rate are left out of the totals and listed in
+// UnconvertedCurrencies instead of being counted as zero.
+type AllocationReport struct {
+	Currency              string             `json:"currency"`
+	TotalValue            float64            `json:"totalValue"`
+	ByAssetType           []AllocationBucket `json:"byAssetType"`
+	ByCurrency            []AllocationBucket `json:"byCurrency"`
+	ByAccount             []AllocationBucket `json:"byAccount"`
+	ByMarket              []AllocationBucket `json:"byMarket"`
+	ByTag                 []AllocationBucket `json:"byTag"`
+	UnconvertedCurrencies []string           `json:"unconvertedCurrencies"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Benchmark is a market index that a portfolio can be compared against. Its daily closes are read
+// from the price history using AssetType and Symbol.
+type Benchmark struct {
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	AssetType string `json:"assetType"`
+	Symbol    string `json:"symbol"`
+	Selected  bool   `json:"selected"`
+}
+
+// UserBenchmark is a benchmark a user picked for comparison
+type UserBenchmark struct {
+	UserID string `gorm:"primaryKey;type:uuid"`
+	Code   string `gorm:"primaryKey"`
+}
+
+func (UserBenchmark) TableName() string {
+	return "user_benchmarks"
+}
+
+type UserBenchmarksUpdateRequest struct {
+	Codes []string `json:"codes" binding:"required"`
+}
+
+// SeriesPoint is one point of a normalized series, rebased to 100 at the first date
+type SeriesPoint struct {
+	Date  time.Time `json:"date"`
+	Value float64   `json:"value"`
+}
+
+type BenchmarkSeries struct {
+	Code             string        `json:"code"`
+	Name             string        `json:"name"`
+	ReturnPercentage *float64      `json:"returnPercentage"`
+	Points           []SeriesPoint `json:"points"`
+}
+
+// BenchmarkComparison puts the time-weighted portfolio index next to the selected benchmarks
+type BenchmarkComparison struct {
+	StartDate  time.Time         `json:"startDate"`
+	EndDate    time.Time         `json:"endDate"`
+	Currency   string            `json:"currency"`
+	Portfolio  BenchmarkSeries   `json:"portfolio"`
+	Benchmarks []BenchmarkSeries `json:"benchmarks"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// ExchangeRateHistory is the rate of a currency pair on a given day, in units of TargetCurrency
+// per unit of BaseCurrency like ExchangeRate
+type ExchangeRateHistory struct {
+	ID             string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"-"`
+	BaseCurrency   string    `gorm:"not null" json:"baseCurrency"`
+	TargetCurrency string    `gorm:"not null" json:"targetCurrency"`
+	Date           time.Time `gorm:"type:date;not null" json:"date"`
+	Rate           float64   `gorm:"type:float8;not null" json:"rate"`
+}
+
+func (ExchangeRateHistory) TableName() string {
+	return "exchange_rate_history"
+}
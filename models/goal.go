@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// Goal is a financial target the user wants to reach by a date, e.g. a house down payment
+type Goal struct {
+	ID           string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	UserID       string    `gorm:"type:uuid;not null;index" json:"-"`
+	User         User      `gorm:"foreignKey:UserID;references:ID;onUpdate:CASCADE;onDelete:CASCADE" json:"-"`
+	Name         string    `gorm:"not null" json:"name"`
+	TargetAmount float64   `gorm:"type:decimal(24,8);not null" json:"targetAmount"`
+	Currency     string    `gorm:"not null" json:"currency"`
+	TargetDate   time.Time `gorm:"type:date;not null" json:"targetDate"`
+	CreatedAt    time.Time `gorm:"not null;default:current_timestamp" json:"createdAt"`
+}
+
+func (Goal) TableName() string {
+	return "goals"
+}
+
+type GoalCreateRequest struct {
+	Name         string  `json:"name" binding:"required"`
+	TargetAmount float64 `json:"targetAmount" binding:"required,gt=0"`
+	Currency     string  `json:"currency" binding:"required"`
+	TargetDate   string  `json:"targetDate" binding:"required,datetime=2006-01-02"`
+}
+
+// GoalUpdateRequest only changes the fields that are present in the payload
+type GoalUpdateRequest struct {
+	Name         *string  `json:"name"`
+	TargetAmount *float64 `json:"targetAmount" binding:"omitempty,gt=0"`
+	Currency     *string  `json:"currency"`
+	TargetDate   *string  `json:"targetDate" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// GoalProbability is the share of simulated paths at or above the goal on its target date. Goals
+// dated after the simulated horizon are BeyondHorizon and have neither probability nor median.
+type GoalProbability struct {
+	Goal                          Goal     `json:"goal"`
+	TargetAmountInDefaultCurrency *float64 `json:"targetAmountInDefaultCurrency"` // nil when no exchange rate is available
+	ProbabilityPercentage         *float64 `json:"probabilityPercentage"`
+	MedianValueAtTargetDate       *float64 `json:"medianValueAtTargetDate"`
+	BeyondHorizon                 bool     `json:"beyondHorizon"`
+}
+
+// ProjectionPercentile is the given percentile of simulated portfolio values at each month end
+type ProjectionPercentile struct {
+	Percentile int           `json:"percentile"`
+	Points     []SeriesPoint `json:"points"`
+}
+
+// GoalProjection is the outcome of a Monte Carlo simulation of the portfolio value. Runs with the
+// same Seed and inputs return the same result.
+type GoalProjection struct {
+	Currency                 string                 `json:"currency"`
+	StartDate                time.Time              `json:"startDate"`
+	StartValue               float64                `json:"startValue"`
+	MonthlyContribution      float64                `json:"monthlyContribution"`
+	ExpectedReturnPercentage float64                `json:"expectedReturnPercentage"`
+	VolatilityPercentage     float64                `json:"volatilityPercentage"`
+	Months                   int                    `json:"months"`
+	Simulations              int                    `json:"simulations"`
+	Seed                     int64                  `json:"seed"`
+	Goals                    []GoalProbability      `json:"goals"`
+	Percentiles              []ProjectionPercentile `json:"percentiles"`
+}
@@ -1,6 +1,9 @@
 package models
 
+import "time"
+
 type Holding struct {
+	AccountID                   string  `json:"accountId,omitempty"` // only set when holdings are listed per account
 	Ticker                      string  `json:"ticker"`
 	TickerName                  string  `json:"tickerName"`
 	Quantity                    float64 `json:"quantity"`
@@ -13,4 +16,31 @@ type Holding struct {
 	TotalValueInDefaultCurrency float64 `json:"totalValueInDefaultCurrency"`
 	GainLoss                    float64 `json:"gainLoss"`
 	GainLossPercentage          float64 `json:"gainLossPercentage"`
+
+	// Cost and gain in the default currency. The cost converts every open lot at the rate of its
+	// trade date; the gain splits into the price effect, GainLoss converted at today's rate, and the
+	// currency effect, the revaluation of the cost basis since the trade dates.
+	TotalCostInDefaultCurrency      float64 `json:"totalCostInDefaultCurrency"`
+	GainLossInDefaultCurrency       float64 `json:"gainLossInDefaultCurrency"`
+	PriceEffectInDefaultCurrency    float64 `json:"priceEffectInDefaultCurrency"`
+	CurrencyEffectInDefaultCurrency float64 `json:"currencyEffectInDefaultCurrency"`
+
+	OpenLots     []HoldingLot  `json:"-"` // buys not yet consumed by FIFO sells
+	RealizedLots []RealizedLot `json:"-"` // FIFO matches of sells against earlier buys
+}
+
+// RealizedLot is the part of a sell matched against a single earlier buy
+type RealizedLot struct {
+	AcquisitionDate time.Time
+	DisposalDate    time.Time
+	Quantity        float64
+	CostPrice       float64
+	SalePrice       float64
+}
+
+// HoldingLot is the remaining quantity of a single buy
+type HoldingLot struct {
+	TradeDate time.Time
+	Quantity  float64
+	Price     float64
 }
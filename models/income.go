@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// IncomeBucket is the dividend and interest income of one group, in the default currency
+type IncomeBucket struct {
+	Key      string  `json:"key"`
+	Name     string  `json:"name"`
+	Amount   float64 `json:"amount"`
+	Payments int     `json:"payments"`
+}
+
+// HoldingIncomeProjection estimates the next year of dividends of a holding by repeating the
+// payments of the trailing year per share held at the time, scaled to the current quantity.
+// Yields are nil when the holding has no cost or value in the default currency.
+type HoldingIncomeProjection struct {
+	AssetType                              string   `json:"assetType"`
+	Ticker                                 string   `json:"ticker"`
+	TickerName                             string   `json:"tickerName"`
+	Quantity                               float64  `json:"quantity"`
+	PaymentsPerYear                        int      `json:"paymentsPerYear"`
+	ProjectedAnnualIncomeInDefaultCurrency float64  `json:"projectedAnnualIncomeInDefaultCurrency"`
+	YieldOnCostPercentage                  *float64 `json:"yieldOnCostPercentage"`
+	CurrentYieldPercentage                 *float64 `json:"currentYieldPercentage"`
+}
+
+type IncomeProjection struct {
+	Total    float64                   `json:"total"`
+	ByMonth  []IncomeBucket            `json:"byMonth"`
+	Holdings []HoldingIncomeProjection `json:"holdings"`
+}
+
+// IncomeReport aggregates the dividends and interest received within a date range, each converted
+// at the exchange rate of its payment date, and projects the coming twelve months of dividends
+type IncomeReport struct {
+	StartDate             time.Time        `json:"startDate"`
+	EndDate               time.Time        `json:"endDate"`
+	Currency              string           `json:"currency"`
+	TotalReceived         float64          `json:"totalReceived"`
+	ByMonth               []IncomeBucket   `json:"byMonth"`
+	ByTicker              []IncomeBucket   `json:"byTicker"`
+	ByAccount             []IncomeBucket   `json:"byAccount"`
+	Projection            IncomeProjection `json:"projection"`
+	UnconvertedCurrencies []string         `json:"unconvertedCurrencies"`
+}
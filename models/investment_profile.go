@@ -1,5 +1,8 @@
 package models
 
+// DefaultTimezone applies to users who have not chosen a timezone
+const DefaultTimezone = "Asia/Taipei"
+
 type InvestmentProfile struct {
 	UserID                               string  `gorm:"primaryKey;type:uuid;not null;unique;index" json:"user_id" db:"user_id"`
 	User                                 User    `gorm:"foreignKey:UserID;references:ID;onUpdate:CASCADE;onDelete:CASCADE" json:"user"`
@@ -10,6 +13,7 @@ type InvestmentProfile struct {
 	YearsInvesting                       int     `gorm:"nullable" json:"yearsInvesting" db:"years_investing"`
 	MonthlyCashFlow                      float64 `gorm:"nullable" json:"monthlyCashFlow" db:"monthly_cash_flow"`
 	DefaultCurrency                      string  `gorm:"nullable" json:"defaultCurrency" db:"default_currency"`
+	Timezone                             string  `gorm:"not null;default:Asia/Taipei" json:"timezone" db:"timezone"` // IANA name, e.g. America/New_York
 }
 
 func (InvestmentProfile) TableName() string {
@@ -24,4 +28,5 @@ type InvestmentProfileCreateRequest struct {
 	YearsInvesting                       int     `json:"yearsInvesting"`
 	MonthlyCashFlow                      float64 `json:"monthlyCashFlow"`
 	DefaultCurrency                      string  `json:"defaultCurrency"`
+	Timezone                             string  `json:"timezone"`
 }
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// JournalEntry is a free-form investment note optionally linked to a date, a ticker and trades
+type JournalEntry struct {
+	ID        string         `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	UserID    string         `gorm:"type:uuid;not null;index" json:"userId"`
+	User      User           `gorm:"foreignKey:UserID;references:ID;onUpdate:CASCADE;onDelete:CASCADE" json:"-"`
+	Title     string         `gorm:"not null" json:"title"`
+	Body      string         `gorm:"type:text;not null" json:"body"` // markdown
+	Tags      pq.StringArray `gorm:"type:text[];not null" json:"tags"`
+	EntryDate *time.Time     `gorm:"type:date" json:"entryDate,omitempty"`
+	AssetType *string        `json:"assetType,omitempty"`
+	Ticker    *string        `json:"ticker,omitempty"`
+	TradeIDs  []string       `gorm:"-" json:"tradeIds"`
+	CreatedAt time.Time      `gorm:"not null;default:current_timestamp" json:"createdAt"`
+	UpdatedAt time.Time      `gorm:"not null;default:current_timestamp" json:"updatedAt"`
+}
+
+func (JournalEntry) TableName() string {
+	return "journal_entries"
+}
+
+// JournalEntryTrade links a journal entry to one of the user's trades
+type JournalEntryTrade struct {
+	JournalEntryID string `gorm:"primaryKey;type:uuid"`
+	TradeID        string `gorm:"primaryKey;type:uuid"`
+}
+
+func (JournalEntryTrade) TableName() string {
+	return "journal_entry_trades"
+}
+
+type JournalEntryCreateRequest struct {
+	Title     string   `json:"title" binding:"required"`
+	Body      string   `json:"body"`
+	Tags      []string `json:"tags"`
+	EntryDate string   `json:"entryDate" binding:"omitempty,datetime=2006-01-02"`
+	AssetType string   `json:"assetType" binding:"omitempty,oneof=stock crypto"`
+	Ticker    string   `json:"ticker"`
+	TradeIDs  []string `json:"tradeIds"`
+}
+
+// JournalEntryUpdateRequest only changes the fields that are present in the payload
+type JournalEntryUpdateRequest struct {
+	Title     *string   `json:"title"`
+	Body      *string   `json:"body"`
+	Tags      *[]string `json:"tags"`
+	EntryDate *string   `json:"entryDate"`
+	AssetType *string   `json:"assetType" binding:"omitempty,oneof=stock crypto"`
+	Ticker    *string   `json:"ticker"`
+	TradeIDs  *[]string `json:"tradeIds"`
+}
+
+// JournalEntryFilter narrows down the entries returned by a list or search
+type JournalEntryFilter struct {
+	Query     string
+	Tag       string
+	Ticker    string
+	TradeID   string
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+// JournalPortfolioValue is the recorded total asset value on the entry date
+type JournalPortfolioValue struct {
+	Date       time.Time `json:"date"`
+	TotalValue float64   `json:"totalValue"`
+	Currency   string    `json:"currency"`
+}
+
+type JournalEntryResponse struct {
+	ID             string                 `json:"id"`
+	Title          string                 `json:"title"`
+	Body           string                 `json:"body"`
+	Tags           []string               `json:"tags"`
+	EntryDate      *time.Time             `json:"entryDate,omitempty"`
+	AssetType      *string                `json:"assetType,omitempty"`
+	Ticker         *string                `json:"ticker,omitempty"`
+	TradeIDs       []string               `json:"tradeIds"`
+	PortfolioValue *JournalPortfolioValue `json:"portfolioValue,omitempty"`
+	CreatedAt      time.Time              `json:"createdAt"`
+	UpdatedAt      time.Time              `json:"updatedAt"`
+}
@@ -0,0 +1,120 @@
+package models
+
+import "time"
+
+// PerformanceReport holds time-weighted and money-weighted returns. Percentages are nil when they
+// cannot be computed, e.g. when there are fewer than two snapshots or no sign change in cash flows.
+type PerformanceReport struct {
+	StartDate time.Time             `json:"startDate"`
+	EndDate   time.Time             `json:"endDate"`
+	Currency  string                `json:"currency"`
+	Portfolio PortfolioPerformance  `json:"portfolio"`
+	Accounts  []PositionPerformance `json:"accounts"`
+	Holdings  []PositionPerformance `json:"holdings"`
+}
+
+// PortfolioPerformance is computed from daily snapshots, account deposits/withdrawals and the
+// amounts traded, since trades do not move account balances
+type PortfolioPerformance struct {
+	StartDate                               time.Time `json:"startDate"` // first snapshot in the period
+	EndDate                                 time.Time `json:"endDate"`   // last snapshot in the period
+	StartValue                              float64   `json:"startValue"`
+	EndValue                                float64   `json:"endValue"`
+	NetContributions                        float64   `json:"netContributions"`
+	InvestmentGain                          float64   `json:"investmentGain"`
+	TimeWeightedReturnPercentage            *float64  `json:"timeWeightedReturnPercentage"`
+	AnnualizedTimeWeightedReturnPercentage  *float64  `json:"annualizedTimeWeightedReturnPercentage"`
+	MoneyWeightedReturnPercentage           *float64  `json:"moneyWeightedReturnPercentage"`
+	AnnualizedMoneyWeightedReturnPercentage *float64  `json:"annualizedMoneyWeightedReturnPercentage"`
+}
+
+// PositionPerformance is the money-weighted return of an account or a holding over the period,
+// built from its value in the first snapshot, its trades since as cash flows and its value in the
+// last snapshot as the terminal value
+type PositionPerformance struct {
+	AccountID                               string   `json:"accountId,omitempty"`
+	AccountName                             string   `json:"accountName,omitempty"`
+	Ticker                                  string   `json:"ticker,omitempty"`
+	TickerName                              string   `json:"tickerName,omitempty"`
+	AssetType                               string   `json:"assetType,omitempty"`
+	Currency                                string   `json:"currency"`
+	StartValue                              float64  `json:"startValue"`
+	Invested                                float64  `json:"invested"`
+	Proceeds                                float64  `json:"proceeds"`
+	EndValue                                float64  `json:"endValue"`
+	GainLoss                                float64  `json:"gainLoss"`
+	MoneyWeightedReturnPercentage           *float64 `json:"moneyWeightedReturnPercentage"`
+	AnnualizedMoneyWeightedReturnPercentage *float64 `json:"annualizedMoneyWeightedReturnPercentage"`
+}
+
+// NetWorthChange decomposes the change in net worth between two snapshots in the default
+// currency. Trades do not move account balances, so the net amount bought counts as money put
+// into the portfolio alongside deposits and withdrawals. Cash movements are converted at the rate
+// of their date; FXEffect is the revaluation of the opening positions and of those movements at
+// the closing rates. Other is what the ledger cannot explain, such as components recorded without
+// an exchange rate.
+type NetWorthChange struct {
+	StartDate             *time.Time `json:"startDate"` // first snapshot in the period
+	EndDate               *time.Time `json:"endDate"`   // last snapshot in the period
+	Currency              string     `json:"currency"`
+	StartValue            float64    `json:"startValue"`
+	EndValue              float64    `json:"endValue"`
+	Change                float64    `json:"change"`
+	NetContributions      float64    `json:"netContributions"`
+	Deposits              float64    `json:"deposits"`
+	Withdrawals           float64    `json:"withdrawals"`
+	NetPurchases          float64    `json:"netPurchases"`
+	InvestmentIncome      float64    `json:"investmentIncome"`
+	MarketGain            float64    `json:"marketGain"`
+	FXEffect              float64    `json:"fxEffect"`
+	Fees                  float64    `json:"fees"`
+	Other                 float64    `json:"other"`
+	UnconvertedCurrencies []string   `json:"unconvertedCurrencies"`
+}
+
+const (
+	ReturnStreakWinning = "winning"
+	ReturnStreakLosing  = "losing"
+)
+
+// DailyReturn is the flow-adjusted change since the previous snapshot. ReturnPercentage is nil for
+// the first snapshot and after a day without value.
+type DailyReturn struct {
+	Date             time.Time `json:"date"`
+	Value            float64   `json:"value"`
+	ReturnPercentage *float64  `json:"returnPercentage"`
+}
+
+// PeriodReturn compounds the daily returns of a month (YYYY-MM) or a year (YYYY)
+type PeriodReturn struct {
+	Period           string   `json:"period"`
+	Days             int      `json:"days"`
+	ReturnPercentage *float64 `json:"returnPercentage"`
+}
+
+// ReturnStreak is a run of consecutive daily returns of the same sign
+type ReturnStreak struct {
+	Type             string    `json:"type"` // winning or losing
+	StartDate        time.Time `json:"startDate"`
+	EndDate          time.Time `json:"endDate"`
+	Days             int       `json:"days"`
+	ReturnPercentage *float64  `json:"returnPercentage"`
+}
+
+// ReturnCalendar lays out the time-weighted returns of the daily snapshots, with deposits and
+// withdrawals removed, for calendar heatmaps and return tables
+type ReturnCalendar struct {
+	StartDate            time.Time      `json:"startDate"`
+	EndDate              time.Time      `json:"endDate"`
+	Currency             string         `json:"currency"`
+	Daily                []DailyReturn  `json:"daily"`
+	Monthly              []PeriodReturn `json:"monthly"`
+	Yearly               []PeriodReturn `json:"yearly"`
+	PositiveDays         int            `json:"positiveDays"`
+	NegativeDays         int            `json:"negativeDays"`
+	BestDay              *DailyReturn   `json:"bestDay"`
+	WorstDay             *DailyReturn   `json:"worstDay"`
+	LongestWinningStreak *ReturnStreak  `json:"longestWinningStreak"`
+	LongestLosingStreak  *ReturnStreak  `json:"longestLosingStreak"`
+	CurrentStreak        *ReturnStreak  `json:"currentStreak"`
+}
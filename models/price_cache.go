@@ -3,13 +3,23 @@ package models
 import "time"
 
 type PriceCache struct {
-	AssetType string    `json:"asset_type"`
-	Symbol    string    `json:"symbol"`
-	Name      string    `json:"name"`
-	Price     float64   `json:"price"`
-	Currency  string    `json:"currency"`
-	ExpiresAt time.Time `json:"expires_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	AssetType  string    `json:"asset_type"`
+	Symbol     string    `json:"symbol"`
+	Name       string    `json:"name"`
+	Price      float64   `json:"price"`
+	Currency   string    `json:"currency"`
+	Provider   string    `json:"provider"`
+	ExpiresAt  time.Time `json:"expires_at"`  // fresh until
+	StaleUntil time.Time `json:"stale_until"` // may still be served, while refreshing, until
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// KeepUntil is when the entry can no longer be served at all
+func (p *PriceCache) KeepUntil() time.Time {
+	if p.StaleUntil.After(p.ExpiresAt) {
+		return p.StaleUntil
+	}
+	return p.ExpiresAt
 }
 
 func (p *PriceCache) GetRedisKey() string {
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// PriceHistory is the daily OHLC of a symbol as reported by an upstream provider
+type PriceHistory struct {
+	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"-"`
+	AssetType string    `gorm:"not null" json:"assetType"`
+	Symbol    string    `gorm:"not null" json:"symbol"`
+	Date      time.Time `gorm:"type:date;not null" json:"date"`
+	Open      *float64  `gorm:"type:decimal(24,8)" json:"open"`
+	High      *float64  `gorm:"type:decimal(24,8)" json:"high"`
+	Low       *float64  `gorm:"type:decimal(24,8)" json:"low"`
+	Close     float64   `gorm:"type:decimal(24,8);not null" json:"close"`
+	Currency  string    `gorm:"not null" json:"currency"`
+	Source    string    `gorm:"not null" json:"source"`
+	CreatedAt time.Time `gorm:"not null;default:current_timestamp" json:"-"`
+}
+
+func (PriceHistory) TableName() string {
+	return "price_history"
+}
+
+// PriceSymbol identifies a symbol whose daily prices are recorded
+type PriceSymbol struct {
+	AssetType string `json:"assetType"`
+	Symbol    string `json:"symbol"`
+}
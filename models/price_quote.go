@@ -0,0 +1,18 @@
+package models
+
+type PriceQuoteRequest struct {
+	AssetType string `json:"assetType" binding:"required,oneof=stock crypto"`
+	Symbol    string `json:"symbol" binding:"required"`
+}
+
+type PriceBatchRequest struct {
+	Items []PriceQuoteRequest `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+// PriceQuoteResult is the quote of one requested symbol, or the error that prevented it
+type PriceQuoteResult struct {
+	AssetType string      `json:"assetType"`
+	Symbol    string      `json:"symbol"`
+	Quote     *TickerInfo `json:"quote,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
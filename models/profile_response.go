@@ -20,4 +20,5 @@ type InvestmentProfileResponse struct {
 	YearsInvesting                       int     `json:"yearsInvesting"`
 	MonthlyCashFlow                      float64 `json:"monthlyCashFlow"`
 	DefaultCurrency                      string  `json:"defaultCurrency"`
+	Timezone                             string  `json:"timezone"`
 }
@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+const (
+	AllocationTargetTypeAssetType = "asset_type"
+	AllocationTargetTypeTicker    = "ticker"
+	AllocationTargetTypeTag       = "tag"
+)
+
+// AllocationTarget is the weight, in percent of the whole portfolio, a user wants one asset class,
+// ticker or tag to have. All of a user's targets share the same TargetType.
+type AllocationTarget struct {
+	ID         string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	UserID     string    `gorm:"type:uuid;not null;index" json:"-"`
+	TargetType string    `gorm:"not null" json:"targetType"`
+	TargetKey  string    `gorm:"not null" json:"targetKey"`
+	Weight     float64   `gorm:"type:decimal(7,4);not null" json:"weight"`
+	Tolerance  float64   `gorm:"type:decimal(7,4);not null" json:"tolerance"` // allowed drift in percentage points
+	CreatedAt  time.Time `gorm:"not null;default:current_timestamp" json:"createdAt"`
+}
+
+func (AllocationTarget) TableName() string {
+	return "allocation_targets"
+}
+
+type AllocationTargetInput struct {
+	Key       string   `json:"key" binding:"required"`
+	Weight    float64  `json:"weight" binding:"gte=0,lte=100"`
+	Tolerance *float64 `json:"tolerance" binding:"omitempty,gte=0,lte=100"`
+}
+
+// AllocationTargetsUpdateRequest replaces all of the user's targets
+type AllocationTargetsUpdateRequest struct {
+	TargetType string                  `json:"targetType" binding:"required,oneof=asset_type ticker tag"`
+	Tolerance  *float64                `json:"tolerance" binding:"omitempty,gte=0,lte=100"` // default for targets without one
+	Targets    []AllocationTargetInput `json:"targets" binding:"dive"`
+}
+
+// RebalanceGroup compares the current weight of a target with its target weight
+type RebalanceGroup struct {
+	Key               string  `json:"key"`
+	Name              string  `json:"name"`
+	CurrentValue      float64 `json:"currentValue"`
+	CurrentPercentage float64 `json:"currentPercentage"`
+	TargetPercentage  float64 `json:"targetPercentage"`
+	Tolerance         float64 `json:"tolerance"`
+	Drift             float64 `json:"drift"` // current minus target, in percentage points
+	WithinTolerance   bool    `json:"withinTolerance"`
+	TargetValue       float64 `json:"targetValue"`
+	AdjustmentValue   float64 `json:"adjustmentValue"` // positive to buy, negative to sell; zero within tolerance
+}
+
+// RebalanceOrder is a suggested trade, rounded to the market's lot size
+type RebalanceOrder struct {
+	Ticker                  string  `json:"ticker"`
+	TickerName              string  `json:"tickerName"`
+	AssetType               string  `json:"assetType"`
+	Market                  string  `json:"market"`
+	Side                    string  `json:"side"` // buy or sell
+	Quantity                float64 `json:"quantity"`
+	LotSize                 float64 `json:"lotSize"`
+	Price                   float64 `json:"price"`
+	Currency                string  `json:"currency"`
+	Amount                  float64 `json:"amount"`
+	EstimatedFee            float64 `json:"estimatedFee"` // commission plus transaction tax, in Currency
+	AmountInDefaultCurrency float64 `json:"amountInDefaultCurrency"`
+}
+
+// RebalanceReport lists the orders that bring drifted targets back to their weights. Positions
+// not covered by any target are left untouched; cash funds the buys and receives the sells.
+type RebalanceReport struct {
+	Currency              string           `json:"currency"`
+	TargetType            string           `json:"targetType"`
+	TotalValue            float64          `json:"totalValue"` // including new cash
+	NewCash               float64          `json:"newCash"`
+	CashBefore            float64          `json:"cashBefore"`
+	CashAfter             float64          `json:"cashAfter"`
+	EstimatedFees         float64          `json:"estimatedFees"`
+	Groups                []RebalanceGroup `json:"groups"`
+	Orders                []RebalanceOrder `json:"orders"`
+	UnconvertedCurrencies []string         `json:"unconvertedCurrencies"`
+}
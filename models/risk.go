@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// RiskReport holds risk metrics computed from the daily snapshots with deposits and withdrawals
+// removed. Drawdowns are positive percentages of the peak value; metrics are nil when there are
+// too few snapshots to compute them.
+type RiskReport struct {
+	StartDate                       time.Time  `json:"startDate"`
+	EndDate                         time.Time  `json:"endDate"`
+	Currency                        string     `json:"currency"`
+	Observations                    int        `json:"observations"`
+	RiskFreeRatePercentage          float64    `json:"riskFreeRatePercentage"`
+	AnnualizedReturnPercentage      *float64   `json:"annualizedReturnPercentage"` // arithmetic mean of period returns, annualized
+	AnnualizedVolatilityPercentage  *float64   `json:"annualizedVolatilityPercentage"`
+	MaxDrawdownPercentage           *float64   `json:"maxDrawdownPercentage"`
+	MaxDrawdownPeakDate             *time.Time `json:"maxDrawdownPeakDate"`
+	MaxDrawdownTroughDate           *time.Time `json:"maxDrawdownTroughDate"`
+	CurrentDrawdownPercentage       *float64   `json:"currentDrawdownPercentage"`
+	SharpeRatio                     *float64   `json:"sharpeRatio"`
+	SortinoRatio                    *float64   `json:"sortinoRatio"`
+	MaxAcceptableLossPercentage     *float64   `json:"maxAcceptableLossPercentage"` // from the investment profile
+	MaxDrawdownExceedsTolerance     bool       `json:"maxDrawdownExceedsTolerance"`
+	CurrentDrawdownExceedsTolerance bool       `json:"currentDrawdownExceedsTolerance"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+const (
+	SnapshotJobStatusPending   = "pending"
+	SnapshotJobStatusRunning   = "running"
+	SnapshotJobStatusCompleted = "completed"
+	SnapshotJobStatusFailed    = "failed"
+)
+
+// SnapshotJob rebuilds a user's daily snapshots over a date range from the trade ledger and
+// historical prices. Without Overwrite only dates that have no snapshot yet are filled.
+type SnapshotJob struct {
+	ID            string     `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	UserID        string     `gorm:"type:uuid;not null;index" json:"-"`
+	Status        string     `gorm:"not null" json:"status"`
+	StartDate     time.Time  `gorm:"type:date;not null" json:"startDate"`
+	EndDate       time.Time  `gorm:"type:date;not null" json:"endDate"`
+	Overwrite     bool       `gorm:"not null" json:"overwrite"`
+	TotalDays     int        `gorm:"not null" json:"totalDays"`
+	ProcessedDays int        `gorm:"not null" json:"processedDays"`
+	WrittenDays   int        `gorm:"not null" json:"writtenDays"`
+	Error         *string    `json:"error,omitempty"`
+	CreatedAt     time.Time  `gorm:"not null;default:current_timestamp" json:"createdAt"`
+	StartedAt     *time.Time `json:"startedAt,omitempty"`
+	FinishedAt    *time.Time `json:"finishedAt,omitempty"`
+}
+
+func (SnapshotJob) TableName() string {
+	return "snapshot_jobs"
+}
+
+// ProgressPercentage is the share of days already processed
+func (j SnapshotJob) ProgressPercentage() float64 {
+	if j.TotalDays == 0 {
+		if j.Status == SnapshotJobStatusCompleted {
+			return 100
+		}
+		return 0
+	}
+	return float64(j.ProcessedDays) / float64(j.TotalDays) * 100
+}
+
+type SnapshotJobResponse struct {
+	SnapshotJob
+	ProgressPercentage float64 `json:"progressPercentage"`
+}
+
+type SnapshotBackfillRequest struct {
+	StartDate string `json:"startDate" binding:"omitempty,datetime=2006-01-02"` // defaults to the first trade date
+	EndDate   string `json:"endDate" binding:"omitempty,datetime=2006-01-02"`   // defaults to yesterday
+	Overwrite bool   `json:"overwrite"`
+}
+
+type CronSnapshotBackfillRequest struct {
+	UserID string `json:"userId"` // all users when empty
+	SnapshotBackfillRequest
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+const (
+	StatementPeriodMonth = "month"
+	StatementPeriodYear  = "year"
+)
+
+// StatementMover is the market gain of a security over the statement period: the change in its
+// value less the net amount bought, in the default currency
+type StatementMover struct {
+	AssetType        string   `json:"assetType"`
+	Ticker           string   `json:"ticker"`
+	Name             string   `json:"name"`
+	OpeningValue     float64  `json:"openingValue"`
+	ClosingValue     float64  `json:"closingValue"`
+	NetTrades        float64  `json:"netTrades"`
+	Change           float64  `json:"change"`
+	ChangePercentage *float64 `json:"changePercentage"`
+}
+
+// Statement summarizes a month or a year of the portfolio in the default currency. The opening
+// value is the last snapshot before the period, or its first snapshot when there is none, and the
+// closing value the last snapshot within it. Cash movements and realized gains are counted after
+// the opening snapshot and converted at the rate of their date; UnrealizedGain is the change in
+// the unrealized gain of the holdings between the two snapshots.
+type Statement struct {
+	Period                string           `json:"period"`
+	PeriodType            string           `json:"periodType"`
+	StartDate             time.Time        `json:"startDate"`
+	EndDate               time.Time        `json:"endDate"`
+	Currency              string           `json:"currency"`
+	OpeningDate           *time.Time       `json:"openingDate"`
+	ClosingDate           *time.Time       `json:"closingDate"`
+	OpeningValue          float64          `json:"openingValue"`
+	Contributions         float64          `json:"contributions"`
+	Withdrawals           float64          `json:"withdrawals"`
+	Income                float64          `json:"income"`
+	Fees                  float64          `json:"fees"`
+	RealizedGain          float64          `json:"realizedGain"`
+	UnrealizedGain        float64          `json:"unrealizedGain"`
+	ClosingValue          float64          `json:"closingValue"`
+	TopMovers             []StatementMover `json:"topMovers"`
+	UnconvertedCurrencies []string         `json:"unconvertedCurrencies"`
+	GeneratedAt           time.Time        `json:"generatedAt"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Symbol is a tradable ticker in the symbol master
+type Symbol struct {
+	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"-"`
+	AssetType string    `gorm:"not null" json:"assetType"`
+	Symbol    string    `gorm:"not null" json:"symbol"`
+	NameEn    string    `gorm:"not null;default:''" json:"nameEn"`
+	NameZh    string    `gorm:"not null;default:''" json:"nameZh"`
+	Exchange  string    `gorm:"not null" json:"exchange"`
+	Currency  string    `gorm:"not null" json:"currency"`
+	UpdatedAt time.Time `gorm:"not null;default:current_timestamp" json:"-"`
+}
+
+func (Symbol) TableName() string {
+	return "symbols"
+}
+
+// SymbolSearchRequest is the query of GET /symbols/search
+type SymbolSearchRequest struct {
+	Query     string `form:"q" binding:"required"`
+	AssetType string `form:"asset_type" binding:"omitempty,oneof=stock crypto"`
+	Limit     int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
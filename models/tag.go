@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Tag is a user-defined label (e.g. "long-term", "dividend") used to group trades into strategies
+type Tag struct {
+	ID          string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	UserID      string    `gorm:"type:uuid;not null;index" json:"-"`
+	User        User      `gorm:"foreignKey:UserID;references:ID;onUpdate:CASCADE;onDelete:CASCADE" json:"-"`
+	Name        string    `gorm:"not null" json:"name"`
+	Color       *string   `json:"color,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	CreatedAt   time.Time `gorm:"not null;default:current_timestamp" json:"createdAt"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// TradeTag links a trade to one of the user's tags
+type TradeTag struct {
+	TradeID string `gorm:"primaryKey;type:uuid"`
+	TagID   string `gorm:"primaryKey;type:uuid"`
+}
+
+func (TradeTag) TableName() string {
+	return "trade_tags"
+}
+
+type TagCreateRequest struct {
+	Name        string  `json:"name" binding:"required,max=50"`
+	Color       *string `json:"color"`
+	Description *string `json:"description"`
+}
+
+type TagUpdateRequest struct {
+	Name        string  `json:"name" binding:"omitempty,max=50"`
+	Color       *string `json:"color"`
+	Description *string `json:"description"`
+}
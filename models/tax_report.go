@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// TaxRealizedLot is one FIFO match of a sale in the report year. Amounts are in the trade currency
+// and in TWD, the cost at the rate of the acquisition date and the proceeds at the rate of the
+// disposal date. The TWD amounts are nil when a rate is missing.
+type TaxRealizedLot struct {
+	AccountID       string    `json:"accountId"`
+	AccountName     string    `json:"accountName"`
+	AssetType       string    `json:"assetType"`
+	Ticker          string    `json:"ticker"`
+	TickerName      string    `json:"tickerName"`
+	Market          string    `json:"market"`
+	ForeignSource   bool      `json:"foreignSource"`
+	AcquisitionDate time.Time `json:"acquisitionDate"`
+	DisposalDate    time.Time `json:"disposalDate"`
+	Quantity        float64   `json:"quantity"`
+	Currency        string    `json:"currency"`
+	Cost            float64   `json:"cost"`
+	Proceeds        float64   `json:"proceeds"`
+	Gain            float64   `json:"gain"`
+	CostTWD         *float64  `json:"costTwd"`
+	ProceedsTWD     *float64  `json:"proceedsTwd"`
+	GainTWD         *float64  `json:"gainTwd"`
+}
+
+// TaxCashItem is a dividend, interest payment or fee of the report year, converted to TWD at the
+// rate of its date
+type TaxCashItem struct {
+	Date          time.Time `json:"date"`
+	Type          string    `json:"type"`
+	AccountID     string    `json:"accountId"`
+	AccountName   string    `json:"accountName"`
+	AssetType     string    `json:"assetType,omitempty"`
+	Ticker        string    `json:"ticker,omitempty"`
+	ForeignSource bool      `json:"foreignSource"`
+	Currency      string    `json:"currency"`
+	Amount        float64   `json:"amount"`
+	AmountTWD     *float64  `json:"amountTwd"`
+	Note          *string   `json:"note,omitempty"`
+}
+
+// TaxReportSummary totals the report in TWD. ForeignIncome is the overseas income to declare:
+// foreign realized gains plus foreign dividends and interest.
+type TaxReportSummary struct {
+	RealizedGain         float64 `json:"realizedGain"`
+	DomesticRealizedGain float64 `json:"domesticRealizedGain"`
+	ForeignRealizedGain  float64 `json:"foreignRealizedGain"`
+	DividendIncome       float64 `json:"dividendIncome"`
+	InterestIncome       float64 `json:"interestIncome"`
+	Fees                 float64 `json:"fees"`
+	ForeignIncome        float64 `json:"foreignIncome"`
+}
+
+type TaxReport struct {
+	Year                  int              `json:"year"`
+	Currency              string           `json:"currency"`
+	RealizedLots          []TaxRealizedLot `json:"realizedLots"`
+	Income                []TaxCashItem    `json:"income"`
+	Fees                  []TaxCashItem    `json:"fees"`
+	Summary               TaxReportSummary `json:"summary"`
+	UnconvertedCurrencies []string         `json:"unconvertedCurrencies"`
+}
@@ -7,6 +7,9 @@ type TickerInfo struct {
 	Name        string  `json:"name"`
 	Currency    string  `json:"currency"`
 	LastUpdated string  `json:"lastUpdated"`
+	AgeSeconds  int64   `json:"ageSeconds"` // time since the price was fetched upstream
+	Stale       bool    `json:"stale"`      // served past the cache TTL while a refresh runs
+	Provider    string  `json:"provider"`   // upstream that served the price
 }
 
 func (t *TickerInfo) GetRedisKey() string {
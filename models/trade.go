@@ -18,6 +18,7 @@ type Trade struct {
 	AccountID string    `gorm:"type:uuid;not null;index" json:"accountId" db:"account_id"`
 	Account   Account   `gorm:"foreignKey:AccountID;references:ID;onUpdate:CASCADE" json:"account"`
 	Reason    *string   `gorm:"nullable" json:"reason,omitempty" db:"reason"`
+	Tags      []Tag     `gorm:"-" json:"tags"`
 	CreatedAt time.Time `gorm:"not null;default:current_timestamp" json:"createdAt" db:"created_at"`
 }
 
@@ -38,6 +39,7 @@ type TradeCreateRequest struct {
 	Currency  string  `json:"currency" binding:"required"`
 	AccountID string  `json:"accountId" binding:"required"`
 	Reason    *string `json:"reason"`
+	TagIDs    []string `json:"tagIds"`
 }
 
 type TradeUpdateRequest struct {
@@ -51,6 +53,7 @@ type TradeUpdateRequest struct {
 	Currency  string  `json:"currency" binding:"omitempty"`
 	AccountID string  `json:"accountId" binding:"omitempty"`
 	Reason    *string `json:"reason"`
+	TagIDs    *[]string `json:"tagIds"` // replaces all tags when present
 }
 
 type TradeResponse struct {
@@ -65,5 +68,6 @@ type TradeResponse struct {
 	Currency  string    `json:"currency" db:"currency"` // e.g., USD, TWD
 	AccountID string    `json:"accountId" db:"account_id"`
 	Reason    *string   `json:"reason,omitempty" db:"reason"`
+	Tags      []Tag     `json:"tags"`
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 }
@@ -18,3 +18,60 @@ type UserDailyTotalAssetValue struct {
 func (UserDailyTotalAssetValue) TableName() string {
 	return "user_daily_total_asset_values"
 }
+
+const (
+	SnapshotComponentHolding = "holding"
+	SnapshotComponentAccount = "account"
+)
+
+// UserDailyAssetComponent is one line of a daily snapshot: a holding in an account, or an account's
+// cash balance. FXRate is units of Currency per unit of the default currency; it and
+// ValueInDefaultCurrency are nil when no exchange rate was available, in which case the component
+// is not part of the snapshot total.
+type UserDailyAssetComponent struct {
+	ID                     string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"-"`
+	UserID                 string    `gorm:"type:uuid;not null;index" json:"-"`
+	Date                   time.Time `gorm:"type:date;not null" json:"date"`
+	ComponentType          string    `gorm:"not null" json:"componentType"` // holding or account
+	AccountID              string    `gorm:"type:uuid;not null" json:"accountId"`
+	AssetType              string    `json:"assetType,omitempty"`
+	Ticker                 string    `json:"ticker,omitempty"`
+	Name                   string    `json:"name"` // ticker name or account name at the time of the snapshot
+	Quantity               float64   `gorm:"type:decimal(24,8);not null" json:"quantity"`
+	Price                  float64   `gorm:"type:decimal(24,8);not null" json:"price"`
+	Currency               string    `gorm:"not null" json:"currency"`
+	Value                  float64   `gorm:"type:decimal(24,8);not null" json:"value"`
+	FXRate                 *float64  `gorm:"column:fx_rate;type:decimal(24,10)" json:"fxRate"`
+	ValueInDefaultCurrency *float64  `gorm:"type:decimal(24,8)" json:"valueInDefaultCurrency"`
+}
+
+func (UserDailyAssetComponent) TableName() string {
+	return "user_daily_asset_components"
+}
+
+// DailyAssetBreakdown is a daily snapshot with its itemized components
+type DailyAssetBreakdown struct {
+	Date       time.Time                 `json:"date"`
+	Currency   string                    `json:"currency"`
+	TotalValue float64                   `json:"totalValue"`
+	Holdings   []UserDailyAssetComponent `json:"holdings"`
+	Accounts   []UserDailyAssetComponent `json:"accounts"`
+}
+
+// TickerValuePoint is a ticker's position on one day, summed over all accounts
+type TickerValuePoint struct {
+	Date                   time.Time `json:"date"`
+	Quantity               float64   `json:"quantity"`
+	Price                  float64   `json:"price"`
+	Currency               string    `json:"currency"`
+	Value                  float64   `json:"value"`
+	ValueInDefaultCurrency float64   `json:"valueInDefaultCurrency"`
+}
+
+// AccountValuePoint is an account's cash and holdings on one day in the default currency
+type AccountValuePoint struct {
+	Date          time.Time `json:"date"`
+	CashValue     float64   `json:"cashValue"`
+	HoldingsValue float64   `json:"holdingsValue"`
+	TotalValue    float64   `json:"totalValue"`
+}
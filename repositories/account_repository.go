@@ -10,6 +10,7 @@ import (
 
 type AccountRepositoryInterface interface {
 	ListAccounts(userID string) ([]models.Account, error)
+	GetAccount(userID, accID string) (*models.Account, error)
 	CreateAccount(userID string, acc *models.Account) error
 	UpdateAccount(userID, accID string, req models.AccountUpdateRequest) (*models.Account, error)
 	DeleteAccount(userID, accID string) error
@@ -43,6 +44,21 @@ func (r *AccountRepository) ListAccounts(userID string) ([]models.Account, error
 	return accounts, nil
 }
 
+func (r *AccountRepository) GetAccount(userID, accID string) (*models.Account, error) {
+	var gormAccount models.Account
+	result := r.DB.Where(&models.Account{ID: accID, UserID: userID}).First(&gormAccount)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &models.Account{
+		ID:       gormAccount.ID,
+		Name:     gormAccount.Name,
+		Currency: gormAccount.Currency,
+		Balance:  gormAccount.Balance,
+	}, nil
+}
+
 func (r *AccountRepository) CreateAccount(userID string, acc *models.Account) error {
 	gormAcc := models.Account{
 		ID:       acc.ID,
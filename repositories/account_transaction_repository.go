@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"log"
+	"time"
+
+	"asset-diary/models"
+
+	"gorm.io/gorm"
+)
+
+type AccountTransactionRepositoryInterface interface {
+	ListAccountTransactions(userID, accountID string) ([]models.AccountTransaction, error)
+	ListUserTransactions(userID string, startDate, endDate time.Time) ([]models.AccountTransaction, error)
+	CreateTransaction(userID string, txn *models.AccountTransaction) error
+	DeleteTransaction(userID, accountID, txnID string) (bool, error)
+}
+
+type AccountTransactionRepository struct {
+	DB *gorm.DB
+}
+
+func NewAccountTransactionRepository(db *gorm.DB) *AccountTransactionRepository {
+	return &AccountTransactionRepository{DB: db}
+}
+
+func (r *AccountTransactionRepository) ListAccountTransactions(userID, accountID string) ([]models.AccountTransaction, error) {
+	txns := []models.AccountTransaction{}
+	result := r.DB.Where(&models.AccountTransaction{UserID: userID, AccountID: accountID}).
+		Order("transaction_date DESC").Order("created_at DESC").
+		Find(&txns)
+	if result.Error != nil {
+		log.Println("Failed to fetch account transactions:", result.Error)
+		return nil, result.Error
+	}
+	return txns, nil
+}
+
+// ListUserTransactions returns the transactions of all the user's accounts within a date range, oldest first
+func (r *AccountTransactionRepository) ListUserTransactions(userID string, startDate, endDate time.Time) ([]models.AccountTransaction, error) {
+	txns := []models.AccountTransaction{}
+	result := r.DB.Where("user_id = ? AND transaction_date BETWEEN ? AND ?",
+		userID,
+		startDate.Format("2006-01-02"),
+		endDate.Format("2006-01-02"),
+	).Order("transaction_date ASC").Find(&txns)
+	if result.Error != nil {
+		log.Println("Failed to fetch user account transactions:", result.Error)
+		return nil, result.Error
+	}
+	return txns, nil
+}
+
+// CreateTransaction stores the transaction and applies it to the account balance atomically
+func (r *AccountTransactionRepository) CreateTransaction(userID string, txn *models.AccountTransaction) error {
+	txn.UserID = userID
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(txn).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Account{}).
+			Where(&models.Account{ID: txn.AccountID, UserID: userID}).
+			Update("balance", gorm.Expr("balance + ?", txn.SignedAmount())).Error
+	})
+	if err != nil {
+		log.Println("Failed to create account transaction:", err)
+	}
+	return err
+}
+
+// DeleteTransaction removes the transaction and reverts its effect on the account balance
+func (r *AccountTransactionRepository) DeleteTransaction(userID, accountID, txnID string) (bool, error) {
+	deleted := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var txn models.AccountTransaction
+		result := tx.Where(&models.AccountTransaction{ID: txnID, UserID: userID, AccountID: accountID}).First(&txn)
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if err := tx.Delete(&txn).Error; err != nil {
+			return err
+		}
+		deleted = true
+		return tx.Model(&models.Account{}).
+			Where(&models.Account{ID: txn.AccountID, UserID: userID}).
+			Update("balance", gorm.Expr("balance - ?", txn.SignedAmount())).Error
+	})
+	if err != nil {
+		log.Println("Failed to delete account transaction:", err)
+		return false, err
+	}
+	return deleted, nil
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"log"
+
+	"asset-diary/models"
+
+	"gorm.io/gorm"
+)
+
+type AllocationTargetRepositoryInterface interface {
+	ListTargets(userID string) ([]models.AllocationTarget, error)
+	ReplaceTargets(userID string, targets []models.AllocationTarget) error
+}
+
+type AllocationTargetRepository struct {
+	db *gorm.DB
+}
+
+func NewAllocationTargetRepository(db *gorm.DB) *AllocationTargetRepository {
+	return &AllocationTargetRepository{db: db}
+}
+
+func (r *AllocationTargetRepository) ListTargets(userID string) ([]models.AllocationTarget, error) {
+	targets := []models.AllocationTarget{}
+	if err := r.db.Where(&models.AllocationTarget{UserID: userID}).Order("weight DESC").Order("target_key").Find(&targets).Error; err != nil {
+		log.Println("Failed to fetch allocation targets:", err)
+		return nil, err
+	}
+	return targets, nil
+}
+
+// ReplaceTargets deletes the user's targets and stores the given ones in a single transaction
+func (r *AllocationTargetRepository) ReplaceTargets(userID string, targets []models.AllocationTarget) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.AllocationTarget{}).Error; err != nil {
+			return err
+		}
+		if len(targets) == 0 {
+			return nil
+		}
+		for i := range targets {
+			targets[i].UserID = userID
+		}
+		return tx.Create(&targets).Error
+	})
+	if err != nil {
+		log.Println("Failed to replace allocation targets:", err)
+	}
+	return err
+}
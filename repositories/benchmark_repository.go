@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"asset-diary/models"
+
+	"gorm.io/gorm"
+)
+
+type BenchmarkRepositoryInterface interface {
+	ListUserBenchmarkCodes(userID string) ([]string, error)
+	SetUserBenchmarkCodes(userID string, codes []string) error
+}
+
+type BenchmarkRepository struct {
+	db *gorm.DB
+}
+
+func NewBenchmarkRepository(db *gorm.DB) *BenchmarkRepository {
+	return &BenchmarkRepository{db: db}
+}
+
+func (r *BenchmarkRepository) ListUserBenchmarkCodes(userID string) ([]string, error) {
+	var codes []string
+	result := r.db.Model(&models.UserBenchmark{}).Where("user_id = ?", userID).Order("code").Pluck("code", &codes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return codes, nil
+}
+
+// SetUserBenchmarkCodes replaces the user's benchmark selection
+func (r *BenchmarkRepository) SetUserBenchmarkCodes(userID string, codes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserBenchmark{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		selection := make([]models.UserBenchmark, len(codes))
+		for i, code := range codes {
+			selection[i] = models.UserBenchmark{UserID: userID, Code: code}
+		}
+		return tx.Create(&selection).Error
+	})
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"log"
+	"time"
+
+	"asset-diary/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExchangeRateHistoryRepositoryInterface interface {
+	UpsertRates(rates []models.ExchangeRateHistory) error
+	GetRatesOnOrBefore(baseCurrency string, date time.Time) ([]models.ExchangeRateHistory, error)
+	GetRatesBetween(baseCurrency string, startDate, endDate time.Time) ([]models.ExchangeRateHistory, error)
+}
+
+type ExchangeRateHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewExchangeRateHistoryRepository(db *gorm.DB) *ExchangeRateHistoryRepository {
+	return &ExchangeRateHistoryRepository{db: db}
+}
+
+func (r *ExchangeRateHistoryRepository) UpsertRates(rates []models.ExchangeRateHistory) error {
+	if len(rates) == 0 {
+		return nil
+	}
+
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "base_currency"}, {Name: "target_currency"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate"}),
+	}).CreateInBatches(&rates, 500)
+
+	if result.Error != nil {
+		log.Printf("Failed to upsert exchange rate history: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+// GetRatesOnOrBefore returns the rates of the latest recorded day that is not after date
+func (r *ExchangeRateHistoryRepository) GetRatesOnOrBefore(baseCurrency string, date time.Time) ([]models.ExchangeRateHistory, error) {
+	rates := []models.ExchangeRateHistory{}
+	result := r.db.Where(`base_currency = ? AND date = (
+			SELECT MAX(date) FROM exchange_rate_history WHERE base_currency = ? AND date <= ?
+		)`,
+		baseCurrency,
+		baseCurrency,
+		date.Format("2006-01-02"),
+	).Find(&rates)
+
+	if result.Error != nil {
+		log.Printf("Failed to get exchange rate history: %v", result.Error)
+		return nil, result.Error
+	}
+
+	return rates, nil
+}
+
+// GetRatesBetween returns every recorded rate of the days between the dates, oldest first
+func (r *ExchangeRateHistoryRepository) GetRatesBetween(baseCurrency string, startDate, endDate time.Time) ([]models.ExchangeRateHistory, error) {
+	rates := []models.ExchangeRateHistory{}
+	result := r.db.Where("base_currency = ? AND date BETWEEN ? AND ?",
+		baseCurrency,
+		startDate.Format("2006-01-02"),
+		endDate.Format("2006-01-02"),
+	).Order("date ASC").Find(&rates)
+
+	if result.Error != nil {
+		log.Printf("Failed to get exchange rate history: %v", result.Error)
+		return nil, result.Error
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"log"
+
+	"asset-diary/models"
+
+	"gorm.io/gorm"
+)
+
+type GoalRepositoryInterface interface {
+	ListGoals(userID string) ([]models.Goal, error)
+	GetGoal(userID, goalID string) (*models.Goal, error)
+	CreateGoal(userID string, goal models.Goal) (*models.Goal, error)
+	SaveGoal(goal *models.Goal) error
+	DeleteGoal(userID, goalID string) (bool, error)
+}
+
+type GoalRepository struct {
+	db *gorm.DB
+}
+
+func NewGoalRepository(db *gorm.DB) *GoalRepository {
+	return &GoalRepository{db: db}
+}
+
+func (r *GoalRepository) ListGoals(userID string) ([]models.Goal, error) {
+	goals := []models.Goal{}
+	if err := r.db.Where(&models.Goal{UserID: userID}).Order("target_date ASC").Find(&goals).Error; err != nil {
+		log.Println("Failed to fetch goals:", err)
+		return nil, err
+	}
+	return goals, nil
+}
+
+func (r *GoalRepository) GetGoal(userID, goalID string) (*models.Goal, error) {
+	var goal models.Goal
+	if err := r.db.Where(&models.Goal{ID: goalID, UserID: userID}).First(&goal).Error; err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+func (r *GoalRepository) CreateGoal(userID string, goal models.Goal) (*models.Goal, error) {
+	goal.UserID = userID
+	if err := r.db.Create(&goal).Error; err != nil {
+		log.Println("Failed to create goal:", err)
+		return nil, err
+	}
+	return &goal, nil
+}
+
+func (r *GoalRepository) SaveGoal(goal *models.Goal) error {
+	if err := r.db.Save(goal).Error; err != nil {
+		log.Println("Failed to update goal:", err)
+		return err
+	}
+	return nil
+}
+
+func (r *GoalRepository) DeleteGoal(userID, goalID string) (bool, error) {
+	result := r.db.Where("id = ? AND user_id = ?", goalID, userID).Delete(&models.Goal{})
+	if result.Error != nil {
+		log.Println("Failed to delete goal:", result.Error)
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,223 @@
+package repositories
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"asset-diary/models"
+
+	"gorm.io/gorm"
+)
+
+// JournalRepositoryInterface defines methods for journal entry database operations
+type JournalRepositoryInterface interface {
+	ListEntries(userID string, filter models.JournalEntryFilter) ([]models.JournalEntry, error)
+	GetEntry(userID, entryID string) (*models.JournalEntry, error)
+	CreateEntry(userID string, entry models.JournalEntry) (*models.JournalEntry, error)
+	UpdateEntry(userID, entryID string, req models.JournalEntryUpdateRequest) (*models.JournalEntry, error)
+	DeleteEntry(userID, entryID string) (bool, error)
+}
+
+// JournalRepository implements JournalRepositoryInterface
+type JournalRepository struct {
+	db *gorm.DB
+}
+
+// NewJournalRepository creates a new JournalRepository instance
+func NewJournalRepository(db *gorm.DB) *JournalRepository {
+	return &JournalRepository{db: db}
+}
+
+// ListEntries returns the user's entries matching the filter. When a full-text query is given the
+// results are ranked by relevance, otherwise they are ordered by entry date, newest first.
+func (r *JournalRepository) ListEntries(userID string, filter models.JournalEntryFilter) ([]models.JournalEntry, error) {
+	query := r.db.Model(&models.JournalEntry{}).Where("journal_entries.user_id = ?", userID)
+
+	if filter.Tag != "" {
+		query = query.Where("? = ANY(journal_entries.tags)", filter.Tag)
+	}
+	if filter.Ticker != "" {
+		query = query.Where("UPPER(journal_entries.ticker) = ?", strings.ToUpper(filter.Ticker))
+	}
+	if filter.TradeID != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM journal_entry_trades jet WHERE jet.journal_entry_id = journal_entries.id AND jet.trade_id = ?)", filter.TradeID)
+	}
+	if filter.StartDate != nil {
+		query = query.Where("journal_entries.entry_date >= ?", filter.StartDate.Format("2006-01-02"))
+	}
+	if filter.EndDate != nil {
+		query = query.Where("journal_entries.entry_date <= ?", filter.EndDate.Format("2006-01-02"))
+	}
+
+	if filter.Query != "" {
+		query = query.
+			Where("journal_entries.search_vector @@ websearch_to_tsquery('simple', ?)", filter.Query).
+			Order(gorm.Expr("ts_rank(journal_entries.search_vector, websearch_to_tsquery('simple', ?)) DESC", filter.Query))
+	}
+	query = query.Order("journal_entries.entry_date DESC NULLS LAST").Order("journal_entries.created_at DESC")
+
+	var entries []models.JournalEntry
+	if err := query.Find(&entries).Error; err != nil {
+		log.Println("JournalRepository: Failed to fetch journal entries:", err)
+		return nil, err
+	}
+
+	if err := r.loadTradeIDs(entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetEntry retrieves a single entry owned by the user
+func (r *JournalRepository) GetEntry(userID, entryID string) (*models.JournalEntry, error) {
+	var entry models.JournalEntry
+	if err := r.db.Where(&models.JournalEntry{ID: entryID, UserID: userID}).First(&entry).Error; err != nil {
+		return nil, err
+	}
+
+	entries := []models.JournalEntry{entry}
+	if err := r.loadTradeIDs(entries); err != nil {
+		return nil, err
+	}
+
+	return &entries[0], nil
+}
+
+func (r *JournalRepository) CreateEntry(userID string, entry models.JournalEntry) (*models.JournalEntry, error) {
+	entry.UserID = userID
+	if entry.Tags == nil {
+		entry.Tags = []string{}
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+		return replaceJournalEntryTrades(tx, entry.ID, entry.TradeIDs)
+	})
+	if err != nil {
+		log.Println("Failed to create journal entry:", err)
+		return nil, err
+	}
+
+	return r.GetEntry(userID, entry.ID)
+}
+
+func (r *JournalRepository) UpdateEntry(userID, entryID string, req models.JournalEntryUpdateRequest) (*models.JournalEntry, error) {
+	var entry models.JournalEntry
+	result := r.db.Where(&models.JournalEntry{ID: entryID, UserID: userID}).First(&entry)
+	if result.Error != nil {
+		log.Println("Failed to find journal entry:", result.Error)
+		return nil, result.Error
+	}
+
+	// Update fields if provided
+	if req.Title != nil {
+		entry.Title = *req.Title
+	}
+	if req.Body != nil {
+		entry.Body = *req.Body
+	}
+	if req.Tags != nil {
+		entry.Tags = *req.Tags
+	}
+	if req.EntryDate != nil {
+		if *req.EntryDate == "" {
+			entry.EntryDate = nil
+		} else {
+			entryDate, err := time.Parse("2006-01-02", *req.EntryDate)
+			if err != nil {
+				return nil, err
+			}
+			entry.EntryDate = &entryDate
+		}
+	}
+	if req.AssetType != nil {
+		entry.AssetType = nilIfEmpty(*req.AssetType)
+	}
+	if req.Ticker != nil {
+		entry.Ticker = nilIfEmpty(*req.Ticker)
+	}
+	entry.UpdatedAt = time.Now()
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&entry).Error; err != nil {
+			return err
+		}
+		if req.TradeIDs != nil {
+			return replaceJournalEntryTrades(tx, entry.ID, *req.TradeIDs)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Println("Failed to update journal entry:", err)
+		return nil, err
+	}
+
+	return r.GetEntry(userID, entryID)
+}
+
+func (r *JournalRepository) DeleteEntry(userID, entryID string) (bool, error) {
+	result := r.db.Where("id = ? AND user_id = ?", entryID, userID).Delete(&models.JournalEntry{})
+	if result.Error != nil {
+		log.Println("Failed to delete journal entry:", result.Error)
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// loadTradeIDs fills TradeIDs on every entry with a single query
+func (r *JournalRepository) loadTradeIDs(entries []models.JournalEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+
+	var links []models.JournalEntryTrade
+	if err := r.db.Where("journal_entry_id IN ?", ids).Find(&links).Error; err != nil {
+		log.Println("JournalRepository: Failed to fetch linked trades:", err)
+		return err
+	}
+
+	tradeIDs := make(map[string][]string)
+	for _, link := range links {
+		tradeIDs[link.JournalEntryID] = append(tradeIDs[link.JournalEntryID], link.TradeID)
+	}
+	for i := range entries {
+		entries[i].TradeIDs = tradeIDs[entries[i].ID]
+		if entries[i].TradeIDs == nil {
+			entries[i].TradeIDs = []string{}
+		}
+	}
+
+	return nil
+}
+
+func replaceJournalEntryTrades(tx *gorm.DB, entryID string, tradeIDs []string) error {
+	if err := tx.Where("journal_entry_id = ?", entryID).Delete(&models.JournalEntryTrade{}).Error; err != nil {
+		return err
+	}
+	if len(tradeIDs) == 0 {
+		return nil
+	}
+
+	links := make([]models.JournalEntryTrade, len(tradeIDs))
+	for i, tradeID := range tradeIDs {
+		links[i] = models.JournalEntryTrade{JournalEntryID: entryID, TradeID: tradeID}
+	}
+	return tx.Create(&links).Error
+}
+
+func nilIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -41,8 +41,8 @@ func (r *PriceCacheRepository) Get(assetType string, symbol string) (*models.Pri
 		return nil, err
 	}
 
-	// Check if the cache has expired
-	if time.Now().After(cache.ExpiresAt) {
+	// Check if the cache can no longer be served
+	if time.Now().After(cache.KeepUntil()) {
 		// Delete expired cache and return nil
 		r.redisClient.Del(ctx, cache.GetRedisKey())
 		return nil, nil
@@ -60,8 +60,8 @@ func (r *PriceCacheRepository) Set(cache *models.PriceCache) error {
 		return err
 	}
 
-	// Calculate TTL in seconds
-	ttl := time.Until(cache.ExpiresAt)
+	// Keep the entry while it can still be served stale
+	ttl := time.Until(cache.KeepUntil())
 	if ttl <= 0 {
 		// If already expired, don't set the cache
 		return nil
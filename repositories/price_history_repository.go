@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"log"
+	"time"
+
+	"asset-diary/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PriceHistoryRepositoryInterface interface {
+	UpsertPrices(prices []models.PriceHistory) error
+	GetPrices(assetType, symbol string, startDate, endDate time.Time) ([]models.PriceHistory, error)
+}
+
+type PriceHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceHistoryRepository(db *gorm.DB) *PriceHistoryRepository {
+	return &PriceHistoryRepository{db: db}
+}
+
+// UpsertPrices stores daily prices, replacing prices already recorded for the same symbol and day
+func (r *PriceHistoryRepository) UpsertPrices(prices []models.PriceHistory) error {
+	if len(prices) == 0 {
+		return nil
+	}
+
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "asset_type"}, {Name: "symbol"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"open", "high", "low", "close", "currency", "source"}),
+	}).CreateInBatches(&prices, 500)
+
+	if result.Error != nil {
+		log.Printf("Failed to upsert price history: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *PriceHistoryRepository) GetPrices(assetType, symbol string, startDate, endDate time.Time) ([]models.PriceHistory, error) {
+	prices := []models.PriceHistory{}
+	result := r.db.Where("asset_type = ? AND symbol = ? AND date BETWEEN ? AND ?",
+		assetType,
+		symbol,
+		startDate.Format("2006-01-02"),
+		endDate.Format("2006-01-02"),
+	).Order("date ASC").Find(&prices)
+
+	if result.Error != nil {
+		log.Printf("Failed to get price history: %v", result.Error)
+		return nil, result.Error
+	}
+
+	return prices, nil
+}
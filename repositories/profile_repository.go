@@ -63,6 +63,7 @@ func (r *ProfileRepository) GetProfile(userID string) (*models.Profile, error) {
 			YearsInvesting:                       int(investmentProfile.YearsInvesting),
 			MonthlyCashFlow:                      investmentProfile.MonthlyCashFlow,
 			DefaultCurrency:                      investmentProfile.DefaultCurrency,
+			Timezone:                             investmentProfile.Timezone,
 		},
 	}, nil
 }
@@ -140,6 +141,10 @@ func (r *ProfileRepository) UpdateProfile(userID string, req *models.UserUpdateR
 				YearsInvesting:                       int(req.InvestmentProfile.YearsInvesting),
 				MonthlyCashFlow:                      req.InvestmentProfile.MonthlyCashFlow,
 				DefaultCurrency:                      req.InvestmentProfile.DefaultCurrency,
+				Timezone:                             req.InvestmentProfile.Timezone,
+			}
+			if newProfile.Timezone == "" {
+				newProfile.Timezone = models.DefaultTimezone
 			}
 			existingProfile = newProfile
 			result = r.db.Create(&newProfile)
@@ -152,6 +157,9 @@ func (r *ProfileRepository) UpdateProfile(userID string, req *models.UserUpdateR
 			existingProfile.YearsInvesting = int(req.InvestmentProfile.YearsInvesting)
 			existingProfile.MonthlyCashFlow = req.InvestmentProfile.MonthlyCashFlow
 			existingProfile.DefaultCurrency = req.InvestmentProfile.DefaultCurrency
+			if req.InvestmentProfile.Timezone != "" {
+				existingProfile.Timezone = req.InvestmentProfile.Timezone
+			}
 			result = r.db.Save(&existingProfile)
 		} else {
 			log.Println("Failed to process investment profile:", result.Error)
@@ -174,6 +182,7 @@ func (r *ProfileRepository) UpdateProfile(userID string, req *models.UserUpdateR
 				YearsInvesting:                       int(existingProfile.YearsInvesting),
 				MonthlyCashFlow:                      existingProfile.MonthlyCashFlow,
 				DefaultCurrency:                      existingProfile.DefaultCurrency,
+				Timezone:                             existingProfile.Timezone,
 			},
 		}, nil
 	}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"log"
+
+	"asset-diary/models"
+
+	"gorm.io/gorm"
+)
+
+type SnapshotJobRepositoryInterface interface {
+	CreateJob(job *models.SnapshotJob) error
+	SaveJob(job *models.SnapshotJob) error
+	GetJob(userID, jobID string) (*models.SnapshotJob, error)
+	ListJobs(userID string, limit int) ([]models.SnapshotJob, error)
+	ListJobsByStatus(status string) ([]models.SnapshotJob, error)
+	FindPendingJob(userID string) (*models.SnapshotJob, error)
+}
+
+type SnapshotJobRepository struct {
+	db *gorm.DB
+}
+
+func NewSnapshotJobRepository(db *gorm.DB) *SnapshotJobRepository {
+	return &SnapshotJobRepository{db: db}
+}
+
+func (r *SnapshotJobRepository) CreateJob(job *models.SnapshotJob) error {
+	if err := r.db.Create(job).Error; err != nil {
+		log.Println("Failed to create snapshot job:", err)
+		return err
+	}
+	return nil
+}
+
+func (r *SnapshotJobRepository) SaveJob(job *models.SnapshotJob) error {
+	if err := r.db.Save(job).Error; err != nil {
+		log.Println("Failed to update snapshot job:", err)
+		return err
+	}
+	return nil
+}
+
+func (r *SnapshotJobRepository) GetJob(userID, jobID string) (*models.SnapshotJob, error) {
+	var job models.SnapshotJob
+	if err := r.db.Where(&models.SnapshotJob{ID: jobID, UserID: userID}).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *SnapshotJobRepository) ListJobs(userID string, limit int) ([]models.SnapshotJob, error) {
+	jobs := []models.SnapshotJob{}
+	if err := r.db.Where(&models.SnapshotJob{UserID: userID}).Order("created_at DESC").Limit(limit).Find(&jobs).Error; err != nil {
+		log.Println("Failed to fetch snapshot jobs:", err)
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// ListJobsByStatus returns jobs in the given status, oldest first
+func (r *SnapshotJobRepository) ListJobsByStatus(status string) ([]models.SnapshotJob, error) {
+	jobs := []models.SnapshotJob{}
+	if err := r.db.Where(&models.SnapshotJob{Status: status}).Order("created_at ASC").Find(&jobs).Error; err != nil {
+		log.Println("Failed to fetch snapshot jobs:", err)
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// FindPendingJob returns the user's job that has not started yet, or nil when there is none
+func (r *SnapshotJobRepository) FindPendingJob(userID string) (*models.SnapshotJob, error) {
+	var job models.SnapshotJob
+	result := r.db.Where(&models.SnapshotJob{UserID: userID, Status: models.SnapshotJobStatusPending}).
+		Order("created_at ASC").First(&job)
+	if result.Error == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &job, nil
+}
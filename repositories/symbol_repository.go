@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"asset-diary/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// symbolSimilarityThreshold is the lowest trigram similarity a fuzzy candidate may have
+const symbolSimilarityThreshold = 0.2
+
+type SymbolRepositoryInterface interface {
+	UpsertSymbols(symbols []models.Symbol) error
+	SearchSymbols(query, assetType string, limit int) ([]models.Symbol, error)
+	ListStocksByExchange(exchanges []string) ([]models.Symbol, error)
+}
+
+type SymbolRepository struct {
+	db *gorm.DB
+}
+
+func NewSymbolRepository(db *gorm.DB) *SymbolRepository {
+	return &SymbolRepository{db: db}
+}
+
+// UpsertSymbols stores symbols, updating the ones already known. An empty name keeps the stored
+// one, so sources that only carry English or Chinese names can be combined.
+func (r *SymbolRepository) UpsertSymbols(symbols []models.Symbol) error {
+	if len(symbols) == 0 {
+		return nil
+	}
+
+	result := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "asset_type"}, {Name: "symbol"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"name_en":    gorm.Expr("COALESCE(NULLIF(EXCLUDED.name_en, ''), symbols.name_en)"),
+			"name_zh":    gorm.Expr("COALESCE(NULLIF(EXCLUDED.name_zh, ''), symbols.name_zh)"),
+			"exchange":   gorm.Expr("EXCLUDED.exchange"),
+			"currency":   gorm.Expr("EXCLUDED.currency"),
+			"updated_at": gorm.Expr("CURRENT_TIMESTAMP"),
+		}),
+	}).CreateInBatches(&symbols, 500)
+
+	if result.Error != nil {
+		log.Printf("Failed to upsert symbols: %v", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+// SearchSymbols returns symbols whose code or names start with or contain query, or resemble it
+// by trigram similarity, prefix matches first. Every condition is one the trigram indexes serve:
+// the % operator is used rather than comparing similarity(), with its threshold set for the
+// transaction only.
+func (r *SymbolRepository) SearchSymbols(query, assetType string, limit int) ([]models.Symbol, error) {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(query)
+	prefix := escaped + "%"
+	contains := "%" + escaped + "%"
+
+	symbols := []models.Symbol{}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		threshold := strconv.FormatFloat(symbolSimilarityThreshold, 'f', -1, 64)
+		if err := tx.Exec("SELECT set_config('pg_trgm.similarity_threshold', ?, true)", threshold).Error; err != nil {
+			return err
+		}
+
+		db := tx.Where(
+			"symbol ILIKE ? OR name_en ILIKE ? OR name_zh ILIKE ? OR symbol % ? OR name_en % ? OR name_zh % ?",
+			prefix, contains, contains, query, query, query,
+		)
+		if assetType != "" {
+			db = db.Where("asset_type = ?", assetType)
+		}
+
+		return db.Order(clause.Expr{
+			SQL:  "CASE WHEN symbol ILIKE ? THEN 0 WHEN name_en ILIKE ? OR name_zh ILIKE ? THEN 1 ELSE 2 END, GREATEST(similarity(symbol, ?), similarity(name_en, ?), similarity(name_zh, ?)) DESC, symbol ASC",
+			Vars: []interface{}{prefix, prefix, prefix, query, query, query},
+		}).Limit(limit).Find(&symbols).Error
+	})
+
+	if err != nil {
+		log.Printf("Failed to search symbols: %v", err)
+		return nil, err
+	}
+
+	return symbols, nil
+}
+
+// ListStocksByExchange returns the code and exchange of every stock traded on the exchanges
+func (r *SymbolRepository) ListStocksByExchange(exchanges []string) ([]models.Symbol, error) {
+	symbols := []models.Symbol{}
+	result := r.db.Select("symbol", "exchange").
+		Where("asset_type = ? AND exchange IN ?", "stock", exchanges).
+		Find(&symbols)
+
+	if result.Error != nil {
+		log.Printf("Failed to list symbols by exchange: %v", result.Error)
+		return nil, result.Error
+	}
+
+	return symbols, nil
+}
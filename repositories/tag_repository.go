@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"log"
+
+	"asset-diary/models"
+
+	"gorm.io/gorm"
+)
+
+// TagRepositoryInterface defines methods for tag-related database operations
+type TagRepositoryInterface interface {
+	ListTags(userID string) ([]models.Tag, error)
+	CreateTag(userID string, tag models.Tag) (*models.Tag, error)
+	UpdateTag(userID, tagID string, req models.TagUpdateRequest) (*models.Tag, error)
+	DeleteTag(userID, tagID string) (bool, error)
+}
+
+// TagRepository implements TagRepositoryInterface
+type TagRepository struct {
+	db *gorm.DB
+}
+
+// NewTagRepository creates a new TagRepository instance
+func NewTagRepository(db *gorm.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// ListTags retrieves all tags for a given user ordered by name
+func (r *TagRepository) ListTags(userID string) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	if err := r.db.Where(&models.Tag{UserID: userID}).Order("LOWER(name)").Find(&tags).Error; err != nil {
+		log.Println("TagRepository: Failed to fetch tags:", err)
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *TagRepository) CreateTag(userID string, tag models.Tag) (*models.Tag, error) {
+	tag.UserID = userID
+	if err := r.db.Create(&tag).Error; err != nil {
+		log.Println("Failed to create tag:", err)
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *TagRepository) UpdateTag(userID, tagID string, req models.TagUpdateRequest) (*models.Tag, error) {
+	var tag models.Tag
+	result := r.db.Where(&models.Tag{ID: tagID, UserID: userID}).First(&tag)
+	if result.Error != nil {
+		log.Println("Failed to find tag:", result.Error)
+		return nil, result.Error
+	}
+
+	if req.Name != "" {
+		tag.Name = req.Name
+	}
+	if req.Color != nil {
+		tag.Color = nilIfEmpty(*req.Color)
+	}
+	if req.Description != nil {
+		tag.Description = nilIfEmpty(*req.Description)
+	}
+
+	if err := r.db.Save(&tag).Error; err != nil {
+		log.Println("Failed to update tag:", err)
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *TagRepository) DeleteTag(userID, tagID string) (bool, error) {
+	result := r.db.Where("id = ? AND user_id = ?", tagID, userID).Delete(&models.Tag{})
+	if result.Error != nil {
+		log.Println("Failed to delete tag:", result.Error)
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
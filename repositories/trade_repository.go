@@ -12,11 +12,15 @@ import (
 // TradeRepositoryInterface defines methods for trade-related database operations
 type TradeRepositoryInterface interface {
 	ListTrades(userID string) ([]models.Trade, error)
+	ListTradesByTag(userID, tag string) ([]models.Trade, error)
+	GetTrade(userID, tradeID string) (*models.Trade, error)
 	CreateTrade(userID string, trade models.Trade) (*models.Trade, error)
 	UpdateTrade(userID, tradeID string, req models.TradeUpdateRequest) (*models.Trade, error)
 	DeleteTrade(userID, tradeID string) (bool, error)
 	IsAccountOwnedByUser(accountID, userID string) (bool, error)
 	IsTradeOwnedByUser(tradeID, userID string) (bool, error)
+	AreTagsOwnedByUser(tagIDs []string, userID string) (bool, error)
+	ListHeldSymbols() ([]models.PriceSymbol, error)
 }
 
 // TradeRepository implements TradeRepositoryInterface
@@ -31,8 +35,22 @@ func NewTradeRepository(db *gorm.DB) *TradeRepository {
 
 // ListTrades retrieves all trades for a given user
 func (r *TradeRepository) ListTrades(userID string) ([]models.Trade, error) {
+	return r.listTrades(r.db.Where(&models.Trade{UserID: userID}))
+}
+
+// ListTradesByTag retrieves the user's trades labelled with the given tag, matched by id or name
+func (r *TradeRepository) ListTradesByTag(userID, tag string) ([]models.Trade, error) {
+	return r.listTrades(r.db.
+		Where(&models.Trade{UserID: userID}).
+		Where(`EXISTS (
+			SELECT 1 FROM trade_tags tt JOIN tags t ON t.id = tt.tag_id
+			WHERE tt.trade_id = trades.id AND t.user_id = trades.user_id AND (t.id::text = ? OR LOWER(t.name) = LOWER(?))
+		)`, tag, tag))
+}
+
+func (r *TradeRepository) listTrades(query *gorm.DB) ([]models.Trade, error) {
 	var gormTrades []models.Trade
-	result := r.db.Where(&models.Trade{UserID: userID}).Find(&gormTrades)
+	result := query.Find(&gormTrades)
 	if result.Error != nil {
 		log.Println("TradeRepository: Failed to fetch trades:", result.Error)
 		return nil, result.Error
@@ -57,6 +75,10 @@ func (r *TradeRepository) ListTrades(userID string) ([]models.Trade, error) {
 		trades = append(trades, trade)
 	}
 
+	if err := r.loadTags(trades); err != nil {
+		return nil, err
+	}
+
 	return trades, nil
 }
 
@@ -72,6 +94,25 @@ func (r *TradeRepository) IsTradeOwnedByUser(tradeID, userID string) (bool, erro
 	return count > 0, result.Error
 }
 
+// AreTagsOwnedByUser reports whether every given tag belongs to the user
+func (r *TradeRepository) AreTagsOwnedByUser(tagIDs []string, userID string) (bool, error) {
+	if len(tagIDs) == 0 {
+		return true, nil
+	}
+	var count int64
+	result := r.db.Model(&models.Tag{}).Where("id IN ? AND user_id = ?", tagIDs, userID).Count(&count)
+	return count == int64(len(tagIDs)), result.Error
+}
+
+// GetTrade returns a single trade without its tags
+func (r *TradeRepository) GetTrade(userID, tradeID string) (*models.Trade, error) {
+	var trade models.Trade
+	if err := r.db.Where(&models.Trade{ID: tradeID, UserID: userID}).First(&trade).Error; err != nil {
+		return nil, err
+	}
+	return &trade, nil
+}
+
 func (r *TradeRepository) CreateTrade(userID string, trade models.Trade) (*models.Trade, error) {
 	gormTrade := &models.Trade{
 		ID:         trade.ID,
@@ -89,9 +130,14 @@ func (r *TradeRepository) CreateTrade(userID string, trade models.Trade) (*model
 		CreatedAt:  time.Now(),
 	}
 
-	result := r.db.Create(gormTrade)
-	if result.Error != nil {
-		return nil, result.Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(gormTrade).Error; err != nil {
+			return err
+		}
+		return replaceTradeTags(tx, gormTrade.ID, tagIDsOf(trade.Tags))
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Fetch the created trade to get all fields populated by DB
@@ -100,7 +146,12 @@ func (r *TradeRepository) CreateTrade(userID string, trade models.Trade) (*model
 		return nil, err
 	}
 
-	return &createdTrade, nil
+	createdTrades := []models.Trade{createdTrade}
+	if err := r.loadTags(createdTrades); err != nil {
+		return nil, err
+	}
+
+	return &createdTrades[0], nil
 }
 
 func (r *TradeRepository) UpdateTrade(userID, tradeID string, req models.TradeUpdateRequest) (*models.Trade, error) {
@@ -147,13 +198,21 @@ func (r *TradeRepository) UpdateTrade(userID, tradeID string, req models.TradeUp
 		gormTrade.AccountID = req.AccountID
 	}
 
-	result = r.db.Save(&gormTrade)
-	if result.Error != nil {
-		log.Println("Failed to update trade:", result.Error)
-		return nil, result.Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&gormTrade).Error; err != nil {
+			return err
+		}
+		if req.TagIDs != nil {
+			return replaceTradeTags(tx, gormTrade.ID, *req.TagIDs)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Println("Failed to update trade:", err)
+		return nil, err
 	}
 
-	return &models.Trade{
+	updatedTrades := []models.Trade{{
 		ID:         gormTrade.ID,
 		Type:       gormTrade.Type,
 		AssetType:  gormTrade.AssetType,
@@ -166,7 +225,12 @@ func (r *TradeRepository) UpdateTrade(userID, tradeID string, req models.TradeUp
 		AccountID:  gormTrade.AccountID,
 		Reason:     gormTrade.Reason,
 		CreatedAt:  gormTrade.CreatedAt,
-	}, nil
+	}}
+	if err := r.loadTags(updatedTrades); err != nil {
+		return nil, err
+	}
+
+	return &updatedTrades[0], nil
 }
 
 func (r *TradeRepository) DeleteTrade(userID, tradeID string) (bool, error) {
@@ -178,3 +242,95 @@ func (r *TradeRepository) DeleteTrade(userID, tradeID string) (bool, error) {
 
 	return result.RowsAffected > 0, nil
 }
+
+// loadTags fills Tags on every trade with a single query
+func (r *TradeRepository) loadTags(trades []models.Trade) error {
+	if len(trades) == 0 {
+		return nil
+	}
+
+	tradeIDs := make([]string, len(trades))
+	for i, trade := range trades {
+		tradeIDs[i] = trade.ID
+	}
+
+	var rows []struct {
+		TradeID     string
+		ID          string
+		Name        string
+		Color       *string
+		Description *string
+		CreatedAt   time.Time
+	}
+	err := r.db.Table("trade_tags").
+		Select("trade_tags.trade_id, tags.id, tags.name, tags.color, tags.description, tags.created_at").
+		Joins("JOIN tags ON tags.id = trade_tags.tag_id").
+		Where("trade_tags.trade_id IN ?", tradeIDs).
+		Order("LOWER(tags.name)").
+		Scan(&rows).Error
+	if err != nil {
+		log.Println("TradeRepository: Failed to fetch trade tags:", err)
+		return err
+	}
+
+	tags := make(map[string][]models.Tag)
+	for _, row := range rows {
+		tags[row.TradeID] = append(tags[row.TradeID], models.Tag{
+			ID:          row.ID,
+			Name:        row.Name,
+			Color:       row.Color,
+			Description: row.Description,
+			CreatedAt:   row.CreatedAt,
+		})
+	}
+	for i := range trades {
+		trades[i].Tags = tags[trades[i].ID]
+		if trades[i].Tags == nil {
+			trades[i].Tags = []models.Tag{}
+		}
+	}
+
+	return nil
+}
+
+func replaceTradeTags(tx *gorm.DB, tradeID string, tagIDs []string) error {
+	if err := tx.Where("trade_id = ?", tradeID).Delete(&models.TradeTag{}).Error; err != nil {
+		return err
+	}
+	if len(tagIDs) == 0 {
+		return nil
+	}
+
+	links := make([]models.TradeTag, len(tagIDs))
+	for i, tagID := range tagIDs {
+		links[i] = models.TradeTag{TradeID: tradeID, TagID: tagID}
+	}
+	return tx.Create(&links).Error
+}
+
+func tagIDsOf(tags []models.Tag) []string {
+	ids := make([]string, len(tags))
+	for i, tag := range tags {
+		ids[i] = tag.ID
+	}
+	return ids
+}
+
+// ListHeldSymbols returns the stock and crypto symbols that any user currently holds
+func (r *TradeRepository) ListHeldSymbols() ([]models.PriceSymbol, error) {
+	symbols := []models.PriceSymbol{}
+	result := r.db.Model(&models.Trade{}).
+		Select("asset_type, UPPER(ticker) AS symbol").
+		Where("asset_type IN ?", []string{"stock", "crypto"}).
+		Group("asset_type, UPPER(ticker)").
+		Having("SUM(CASE WHEN type = 'buy' THEN quantity ELSE -quantity END) > 0").
+		Order("asset_type, symbol").
+		Scan(&symbols)
+
+	if result.Error != nil {
+		log.Println("TradeRepository: Failed to list held symbols:", result.Error)
+		return nil, result.Error
+	}
+
+	return symbols, nil
+}
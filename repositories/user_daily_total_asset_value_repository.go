@@ -14,6 +14,11 @@ type UserDailyTotalAssetValueRepositoryInterface interface {
 	CreateOrUpdate(record *models.UserDailyTotalAssetValue) error
 	GetUserDailyTotalAssetValues(userID string, startDate, endDate time.Time) ([]models.UserDailyTotalAssetValue, error)
 	GetLatestUserDailyTotalAssetValue(userID string) (*models.UserDailyTotalAssetValue, error)
+	SaveSnapshot(record *models.UserDailyTotalAssetValue, components []models.UserDailyAssetComponent) error
+	GetUserDailyTotalAssetValue(userID string, date time.Time) (*models.UserDailyTotalAssetValue, error)
+	GetComponents(userID string, date time.Time) ([]models.UserDailyAssetComponent, error)
+	GetTickerSeries(userID, assetType, ticker string, startDate, endDate time.Time) ([]models.TickerValuePoint, error)
+	GetAccountSeries(userID, accountID string, startDate, endDate time.Time) ([]models.AccountValuePoint, error)
 }
 
 // UserDailyTotalAssetValueRepository implements DailyAssetRepositoryInterface
@@ -28,8 +33,33 @@ func NewUserDailyTotalAssetValueRepository(db *gorm.DB) *UserDailyTotalAssetValu
 
 // CreateOrUpdate creates a new daily asset record or updates if it already exists for the user and date
 func (r *UserDailyTotalAssetValueRepository) CreateOrUpdate(record *models.UserDailyTotalAssetValue) error {
+	return upsertDailyTotalAssetValue(r.db, record)
+}
+
+// SaveSnapshot upserts the daily total and replaces its components in a single transaction
+func (r *UserDailyTotalAssetValueRepository) SaveSnapshot(record *models.UserDailyTotalAssetValue, components []models.UserDailyAssetComponent) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := upsertDailyTotalAssetValue(tx, record); err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? AND date = ?", record.UserID, record.Date.Format("2006-01-02")).
+			Delete(&models.UserDailyAssetComponent{}).Error; err != nil {
+			return err
+		}
+		if len(components) == 0 {
+			return nil
+		}
+		return tx.Create(&components).Error
+	})
+	if err != nil {
+		log.Printf("Failed to save daily asset snapshot: %v", err)
+	}
+	return err
+}
+
+func upsertDailyTotalAssetValue(db *gorm.DB, record *models.UserDailyTotalAssetValue) error {
 	// Use ON CONFLICT to update the total_value if the record already exists
-	result := r.db.Exec(`
+	result := db.Exec(`
 		INSERT INTO user_daily_total_asset_values (user_id, date, total_value, currency, updated_at)
 		VALUES (?, ?, ?, ?, NOW())
 		ON CONFLICT (user_id, date) 
@@ -83,3 +113,90 @@ func (r *UserDailyTotalAssetValueRepository) GetLatestUserDailyTotalAssetValue(u
 
 	return &asset, nil
 }
+
+// GetUserDailyTotalAssetValue returns the snapshot of a single date, or nil when none was recorded
+func (r *UserDailyTotalAssetValueRepository) GetUserDailyTotalAssetValue(userID string, date time.Time) (*models.UserDailyTotalAssetValue, error) {
+	var asset models.UserDailyTotalAssetValue
+	result := r.db.Where("user_id = ? AND date = ?", userID, date.Format("2006-01-02")).First(&asset)
+
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		log.Printf("Failed to get user daily asset: %v", result.Error)
+		return nil, result.Error
+	}
+
+	return &asset, nil
+}
+
+func (r *UserDailyTotalAssetValueRepository) GetComponents(userID string, date time.Time) ([]models.UserDailyAssetComponent, error) {
+	components := []models.UserDailyAssetComponent{}
+	result := r.db.Where("user_id = ? AND date = ?", userID, date.Format("2006-01-02")).
+		Order("value_in_default_currency DESC NULLS LAST").
+		Find(&components)
+
+	if result.Error != nil {
+		log.Printf("Failed to get daily asset components: %v", result.Error)
+		return nil, result.Error
+	}
+
+	return components, nil
+}
+
+// GetTickerSeries sums a ticker's holding components over all accounts per day. An empty assetType matches any.
+func (r *UserDailyTotalAssetValueRepository) GetTickerSeries(userID, assetType, ticker string, startDate, endDate time.Time) ([]models.TickerValuePoint, error) {
+	points := []models.TickerValuePoint{}
+	query := r.db.Model(&models.UserDailyAssetComponent{}).
+		Select(`date,
+			SUM(quantity) AS quantity,
+			MAX(price) AS price,
+			MAX(currency) AS currency,
+			SUM(value) AS value,
+			COALESCE(SUM(value_in_default_currency), 0) AS value_in_default_currency`).
+		Where("user_id = ? AND component_type = ? AND UPPER(ticker) = UPPER(?) AND date BETWEEN ? AND ?",
+			userID,
+			models.SnapshotComponentHolding,
+			ticker,
+			startDate.Format("2006-01-02"),
+			endDate.Format("2006-01-02"),
+		)
+	if assetType != "" {
+		query = query.Where("asset_type = ?", assetType)
+	}
+
+	if err := query.Group("date").Order("date ASC").Scan(&points).Error; err != nil {
+		log.Printf("Failed to get ticker series: %v", err)
+		return nil, err
+	}
+
+	return points, nil
+}
+
+// GetAccountSeries splits an account's daily value into cash and holdings in the default currency
+func (r *UserDailyTotalAssetValueRepository) GetAccountSeries(userID, accountID string, startDate, endDate time.Time) ([]models.AccountValuePoint, error) {
+	points := []models.AccountValuePoint{}
+	result := r.db.Model(&models.UserDailyAssetComponent{}).
+		Select(`date,
+			COALESCE(SUM(CASE WHEN component_type = ? THEN value_in_default_currency END), 0) AS cash_value,
+			COALESCE(SUM(CASE WHEN component_type = ? THEN value_in_default_currency END), 0) AS holdings_value,
+			COALESCE(SUM(value_in_default_currency), 0) AS total_value`,
+			models.SnapshotComponentAccount,
+			models.SnapshotComponentHolding,
+		).
+		Where("user_id = ? AND account_id = ? AND date BETWEEN ? AND ?",
+			userID,
+			accountID,
+			startDate.Format("2006-01-02"),
+			endDate.Format("2006-01-02"),
+		).
+		Group("date").Order("date ASC").
+		Scan(&points)
+
+	if result.Error != nil {
+		log.Printf("Failed to get account series: %v", result.Error)
+		return nil, result.Error
+	}
+
+	return points, nil
+}
@@ -22,6 +22,20 @@ func SetupRoutes(router *gin.RouterGroup,
 	cronHandler *handlers.CronHandler,
 	redisHandler *handlers.RedisHandler,
 	waitingListHandler *handlers.WaitingListHandler,
+	journalHandler *handlers.JournalHandler,
+	tagHandler *handlers.TagHandler,
+	performanceHandler *handlers.PerformanceHandler,
+	benchmarkHandler *handlers.BenchmarkHandler,
+	allocationHandler *handlers.AllocationHandler,
+	rebalanceHandler *handlers.RebalanceHandler,
+	riskHandler *handlers.RiskHandler,
+	goalHandler *handlers.GoalHandler,
+	snapshotJobHandler *handlers.SnapshotJobHandler,
+	incomeHandler *handlers.IncomeHandler,
+	taxReportHandler *handlers.TaxReportHandler,
+	statementHandler *handlers.StatementHandler,
+	priceHistoryHandler *handlers.PriceHistoryHandler,
+	symbolHandler *handlers.SymbolHandler,
 ) {
 	router.GET("/healthz", healthCheckHandler.HealthCheck)
 	router.POST("/waiting-list/join", middleware.RateLimit(5, time.Hour), waitingListHandler.Join)
@@ -56,6 +70,10 @@ func SetupRoutes(router *gin.RouterGroup,
 	{
 		cronGroup.POST("/update-exchange-rates", cronHandler.UpdateExchangeRates)
 		cronGroup.POST("/record-daily-assets-value", cronHandler.RecordDailyAssets)
+		cronGroup.POST("/record-closing-prices", cronHandler.RecordClosingPrices)
+		cronGroup.POST("/refresh-symbols", cronHandler.RefreshSymbols)
+		cronGroup.POST("/import-symbols", cronHandler.ImportSymbols)
+		cronGroup.POST("/backfill-daily-assets", cronHandler.BackfillDailyAssets)
 	}
 
 	protected := router.Group("/")
@@ -75,6 +93,9 @@ func SetupRoutes(router *gin.RouterGroup,
 			accounts.POST("", accountHandler.CreateAccount)
 			accounts.PUT("/:id", accountHandler.UpdateAccount)
 			accounts.DELETE("/:id", accountHandler.DeleteAccount)
+			accounts.GET("/:id/transactions", accountHandler.ListTransactions)
+			accounts.POST("/:id/transactions", accountHandler.CreateTransaction)
+			accounts.DELETE("/:id/transactions/:transactionId", accountHandler.DeleteTransaction)
 		}
 
 		trades := protected.Group("/trades")
@@ -85,6 +106,39 @@ func SetupRoutes(router *gin.RouterGroup,
 			trades.DELETE("/:id", tradeHandler.DeleteTrade)
 		}
 
+		tags := protected.Group("/tags")
+		{
+			tags.GET("", tagHandler.ListTags)
+			tags.POST("", tagHandler.CreateTag)
+			tags.PUT("/:id", tagHandler.UpdateTag)
+			tags.DELETE("/:id", tagHandler.DeleteTag)
+		}
+
+		journal := protected.Group("/journal-entries")
+		{
+			journal.GET("", journalHandler.ListEntries)
+			journal.POST("", journalHandler.CreateEntry)
+			journal.GET("/:id", journalHandler.GetEntry)
+			journal.PUT("/:id", journalHandler.UpdateEntry)
+			journal.DELETE("/:id", journalHandler.DeleteEntry)
+		}
+
+		goals := protected.Group("/goals")
+		{
+			goals.GET("", goalHandler.ListGoals)
+			goals.POST("", goalHandler.CreateGoal)
+			goals.GET("/projection", goalHandler.GetProjection)
+			goals.PUT("/:id", goalHandler.UpdateGoal)
+			goals.DELETE("/:id", goalHandler.DeleteGoal)
+		}
+
+		benchmarks := protected.Group("/benchmarks")
+		{
+			benchmarks.GET("", benchmarkHandler.ListBenchmarks)
+			benchmarks.PUT("", benchmarkHandler.UpdateBenchmarks)
+			benchmarks.GET("/comparison", benchmarkHandler.GetComparison)
+		}
+
 		googleAuth := protected.Group("/auth/google")
 		{
 			googleAuth.POST("/link", authHandler.LinkGoogleAccount)
@@ -95,6 +149,29 @@ func SetupRoutes(router *gin.RouterGroup,
 		protected.GET("/holdings", holdingHandler.ListHoldings)
 		protected.GET("/stock/price/:symbol", assetPriceHandler.GetStockPrice)
 		protected.GET("/crypto/price/:symbol", assetPriceHandler.GetCryptoPrice)
-		protected.GET("/daily-total-assets", dailyTotalAssetValueHandler.GetUserDailyTotalAssetValues)
+		protected.POST("/prices/batch", assetPriceHandler.GetPrices)
+		protected.GET("/prices/:asset_type/:symbol/history", priceHistoryHandler.GetPriceHistory)
+		protected.GET("/symbols/search", symbolHandler.SearchSymbols)
+		dailyTotalAssets := protected.Group("/daily-total-assets")
+		{
+			dailyTotalAssets.GET("", dailyTotalAssetValueHandler.GetUserDailyTotalAssetValues)
+			dailyTotalAssets.GET("/:date/breakdown", dailyTotalAssetValueHandler.GetBreakdown)
+			dailyTotalAssets.GET("/tickers/:ticker", dailyTotalAssetValueHandler.GetTickerSeries)
+			dailyTotalAssets.GET("/accounts/:id", dailyTotalAssetValueHandler.GetAccountSeries)
+			dailyTotalAssets.POST("/backfill", snapshotJobHandler.StartBackfill)
+			dailyTotalAssets.GET("/backfill", snapshotJobHandler.ListJobs)
+			dailyTotalAssets.GET("/backfill/:id", snapshotJobHandler.GetJob)
+		}
+		protected.GET("/performance", performanceHandler.GetPerformance)
+		protected.GET("/performance/change", performanceHandler.GetNetWorthChange)
+		protected.GET("/performance/calendar", performanceHandler.GetReturnCalendar)
+		protected.GET("/allocation", allocationHandler.GetAllocation)
+		protected.GET("/allocation/targets", rebalanceHandler.ListTargets)
+		protected.PUT("/allocation/targets", rebalanceHandler.UpdateTargets)
+		protected.GET("/rebalance", rebalanceHandler.GetRebalance)
+		protected.GET("/risk", riskHandler.GetRisk)
+		protected.GET("/income", incomeHandler.GetIncome)
+		protected.GET("/reports/tax/:year", taxReportHandler.GetTaxReport)
+		protected.GET("/reports/statements", statementHandler.GetStatement)
 	}
 }
@@ -1,10 +1,15 @@
 package services
 
 import (
+	"errors"
+	"strings"
+	"time"
+
 	"asset-diary/models"
 	"asset-diary/repositories"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type AccountServiceInterface interface {
@@ -12,14 +17,19 @@ type AccountServiceInterface interface {
 	CreateAccount(userID string, req models.AccountCreateRequest) (*models.Account, error)
 	UpdateAccount(userID, accID string, req models.AccountUpdateRequest) (*models.Account, error)
 	DeleteAccount(userID, accID string) error
+	ListTransactions(userID, accID string) ([]models.AccountTransaction, error)
+	ListUserTransactions(userID string, startDate, endDate time.Time) ([]models.AccountTransaction, error)
+	CreateTransaction(userID, accID string, req models.AccountTransactionCreateRequest) (*models.AccountTransaction, error)
+	DeleteTransaction(userID, accID, txnID string) (bool, error)
 }
 
 type AccountService struct {
-	repo repositories.AccountRepositoryInterface
+	repo    repositories.AccountRepositoryInterface
+	txnRepo repositories.AccountTransactionRepositoryInterface
 }
 
-func NewAccountService(repo repositories.AccountRepositoryInterface) *AccountService {
-	return &AccountService{repo: repo}
+func NewAccountService(repo repositories.AccountRepositoryInterface, txnRepo repositories.AccountTransactionRepositoryInterface) *AccountService {
+	return &AccountService{repo: repo, txnRepo: txnRepo}
 }
 
 func (s *AccountService) ListAccounts(userID string) ([]models.Account, error) {
@@ -50,3 +60,65 @@ func (s *AccountService) UpdateAccount(userID, accID string, req models.AccountU
 func (s *AccountService) DeleteAccount(userID, accID string) error {
 	return s.repo.DeleteAccount(userID, accID)
 }
+
+func (s *AccountService) ListTransactions(userID, accID string) ([]models.AccountTransaction, error) {
+	if _, err := s.getAccount(userID, accID); err != nil {
+		return nil, err
+	}
+	return s.txnRepo.ListAccountTransactions(userID, accID)
+}
+
+// ListUserTransactions returns the cash movements of all the user's accounts within a date range
+func (s *AccountService) ListUserTransactions(userID string, startDate, endDate time.Time) ([]models.AccountTransaction, error) {
+	return s.txnRepo.ListUserTransactions(userID, startDate, endDate)
+}
+
+// CreateTransaction records a cash movement in the account's currency and updates its balance
+func (s *AccountService) CreateTransaction(userID, accID string, req models.AccountTransactionCreateRequest) (*models.AccountTransaction, error) {
+	acc, err := s.getAccount(userID, accID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactionDate, err := time.Parse("2006-01-02", req.TransactionDate)
+	if err != nil {
+		return nil, models.NewAppError(models.ErrCodeInvalidRequest, "Invalid transactionDate format, use YYYY-MM-DD")
+	}
+
+	txn := &models.AccountTransaction{
+		AccountID:       acc.ID,
+		Type:            req.Type,
+		Amount:          req.Amount,
+		Currency:        acc.Currency,
+		TransactionDate: transactionDate,
+		Note:            req.Note,
+	}
+	if req.Type == models.AccountTransactionTypeDividend {
+		ticker := strings.ToUpper(strings.TrimSpace(req.Ticker))
+		if ticker == "" {
+			return nil, models.NewAppError(models.ErrCodeInvalidRequest, "ticker is required for dividends")
+		}
+		assetType := req.AssetType
+		if assetType == "" {
+			assetType = "stock"
+		}
+		txn.Ticker = &ticker
+		txn.AssetType = &assetType
+	}
+	if err := s.txnRepo.CreateTransaction(userID, txn); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+func (s *AccountService) DeleteTransaction(userID, accID, txnID string) (bool, error) {
+	return s.txnRepo.DeleteTransaction(userID, accID, txnID)
+}
+
+func (s *AccountService) getAccount(userID, accID string) (*models.Account, error) {
+	acc, err := s.repo.GetAccount(userID, accID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.NewAppError(models.ErrCodeNotFound, "Account not found")
+	}
+	return acc, err
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"asset-diary/models"
+)
+
+const (
+	AssetTypeCash = "cash"
+
+	MarketTW     = "TW"
+	MarketUS     = "US"
+	MarketCrypto = "CRYPTO"
+	MarketCash   = "CASH"
+)
+
+// marketOf classifies a position by the market it trades on, following the same rule the price
+// service uses to route stock symbols
+func marketOf(assetType, ticker string) string {
+	switch assetType {
+	case "crypto":
+		return MarketCrypto
+	case AssetTypeCash:
+		return MarketCash
+	}
+	return ParseStockSymbol(ticker).Market
+}
+
+type AllocationServiceInterface interface {
+	GetAllocation(userID string) (*models.AllocationReport, error)
+}
+
+type AllocationService struct {
+	holdingSvc  HoldingServiceInterface
+	accountSvc  AccountServiceInterface
+	tradeSvc    TradeServiceInterface
+	tagSvc      TagServiceInterface
+	profileSvc  ProfileServiceInterface
+	exchangeSvc ExchangeRateServiceInterface
+}
+
+func NewAllocationService(
+	holdingSvc HoldingServiceInterface,
+	accountSvc AccountServiceInterface,
+	tradeSvc TradeServiceInterface,
+	tagSvc TagServiceInterface,
+	profileSvc ProfileServiceInterface,
+	exchangeSvc ExchangeRateServiceInterface,
+) *AllocationService {
+	return &AllocationService{
+		holdingSvc:  holdingSvc,
+		accountSvc:  accountSvc,
+		tradeSvc:    tradeSvc,
+		tagSvc:      tagSvc,
+		profileSvc:  profileSvc,
+		exchangeSvc: exchangeSvc,
+	}
+}
+
+func (s *AllocationService) GetAllocation(userID string) (*models.AllocationReport, error) {
+	defaultCurrency, err := s.profileSvc.GetDefaultCurrency(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user profile: %w", err)
+	}
+
+	rates, err := s.exchangeSvc.GetRatesByBaseCurrency(defaultCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rates: %w", err)
+	}
+
+	holdings, err := s.holdingSvc.ListAccountHoldings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.accountSvc.ListAccounts(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.tagSvc.ListTags(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	trades, err := s.tradeSvc.ListTrades(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildAllocation(defaultCurrency, rates, holdings, accounts, tags, holdingsByTag(holdings, trades)), nil
+}
+
+// holdingsByTag splits already built holdings among the tags of their trades, instead of building
+// the holdings of every tag again. A tag holds the net quantity of its trades in each position at
+// the holding's price, which is what the holdings of the tagged trades alone would be worth.
+// Holdings listed per account are matched by account, the others across accounts.
+func holdingsByTag(holdings []models.Holding, trades []models.Trade) map[string][]models.Holding {
+	type position struct {
+		accountID, assetType, ticker, currency string
+	}
+
+	quantities := make(map[string]map[position]float64)
+	for _, trade := range trades {
+		quantity := trade.Quantity
+		if trade.Type == "sell" {
+			quantity = -quantity
+		}
+		for _, tag := range trade.Tags {
+			if quantities[tag.ID] == nil {
+				quantities[tag.ID] = make(map[position]float64)
+			}
+			quantities[tag.ID][position{trade.AccountID, trade.AssetType, trade.Ticker, trade.Currency}] += quantity
+			quantities[tag.ID][position{"", trade.AssetType, trade.Ticker, trade.Currency}] += quantity
+		}
+	}
+
+	tagHoldings := make(map[string][]models.Holding, len(quantities))
+	for tagID, tagQuantities := range quantities {
+		for _, holding := range holdings {
+			quantity := tagQuantities[position{holding.AccountID, holding.AssetType, holding.Ticker, holding.Currency}]
+			if quantity <= 0 {
+				continue
+			}
+			tagged := holding
+			tagged.Quantity = quantity
+			tagged.TotalValue = quantity * holding.Price
+			tagHoldings[tagID] = append(tagHoldings[tagID], tagged)
+		}
+	}
+	return tagHoldings
+}
+
+// allocationGroup accumulates values per key, remembering display names and first-seen order
+type allocationGroup struct {
+	names  map[string]string
+	values map[string]float64
+}
+
+func newAllocationGroup() *allocationGroup {
+	return &allocationGroup{names: map[string]string{}, values: map[string]float64{}}
+}
+
+func (g *allocationGroup) add(key, name string, value float64) {
+	if _, ok := g.names[key]; !ok {
+		g.names[key] = name
+	}
+	g.values[key] += value
+}
+
+// buckets returns the groups sorted by value, largest first, with percentages of total
+func (g *allocationGroup) buckets(total float64) []models.AllocationBucket {
+	buckets := make([]models.AllocationBucket, 0, len(g.values))
+	for key, value := range g.values {
+		bucket := models.AllocationBucket{Key: key, Name: g.names[key], Value: value}
+		if total > 0 {
+			bucket.Percentage = value / total * 100
+		}
+		buckets = append(buckets, bucket)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Value != buckets[j].Value {
+			return buckets[i].Value > buckets[j].Value
+		}
+		return buckets[i].Key < buckets[j].Key
+	})
+	return buckets
+}
+
+func buildAllocation(
+	defaultCurrency string,
+	rates map[string]float64,
+	holdings []models.Holding,
+	accounts []models.Account,
+	tags []models.Tag,
+	tagHoldings map[string][]models.Holding,
+) *models.AllocationReport {
+	byAssetType := newAllocationGroup()
+	byCurrency := newAllocationGroup()
+	byAccount := newAllocationGroup()
+	byMarket := newAllocationGroup()
+	byTag := newAllocationGroup()
+	unconverted := map[string]bool{}
+	total := 0.0
+
+	convert := func(amount float64, currency string) (float64, bool) {
+		value, ok := convertWithRates(amount, currency, defaultCurrency, rates)
+		if !ok {
+			log.Printf("No exchange rate found for %s to %s", currency, defaultCurrency)
+			unconverted[currency] = true
+		}
+		return value, ok
+	}
+
+	accountNames := make(map[string]string, len(accounts))
+	for _, account := range accounts {
+		accountNames[account.ID] = account.Name
+	}
+
+	for _, holding := range holdings {
+		value, ok := convert(holding.TotalValue, holding.Currency)
+		if !ok {
+			continue
+		}
+		total += value
+		byAssetType.add(holding.AssetType, holding.AssetType, value)
+		byCurrency.add(holding.Currency, holding.Currency, value)
+		byAccount.add(holding.AccountID, accountNames[holding.AccountID], value)
+		market := marketOf(holding.AssetType, holding.Ticker)
+		byMarket.add(market, market, value)
+	}
+
+	for _, account := range accounts {
+		value, ok := convert(account.Balance, account.Currency)
+		if !ok {
+			continue
+		}
+		total += value
+		byAssetType.add(AssetTypeCash, AssetTypeCash, value)
+		byCurrency.add(account.Currency, account.Currency, value)
+		byAccount.add(account.ID, account.Name, value)
+		byMarket.add(MarketCash, MarketCash, value)
+	}
+
+	for _, tag := range tags {
+		tagValue := 0.0
+		for _, holding := range tagHoldings[tag.ID] {
+			if value, ok := convert(holding.TotalValue, holding.Currency); ok {
+				tagValue += value
+			}
+		}
+		byTag.add(tag.ID, tag.Name, tagValue)
+	}
+
+	report := &models.AllocationReport{
+		Currency:              defaultCurrency,
+		TotalValue:            total,
+		ByAssetType:           byAssetType.buckets(total),
+		ByCurrency:            byCurrency.buckets(total),
+		ByAccount:             byAccount.buckets(total),
+		ByMarket:              byMarket.buckets(total),
+		ByTag:                 byTag.buckets(total),
+		UnconvertedCurrencies: make([]string, 0, len(unconverted)),
+	}
+	for currency := range unconverted {
+		report.UnconvertedCurrencies = append(report.UnconvertedCurrencies, currency)
+	}
+	sort.Strings(report.UnconvertedCurrencies)
+
+	return report
+}
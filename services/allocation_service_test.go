@@ -0,0 +1,105 @@
+package services
+
+import (
+	"testing"
+
+	"asset-diary/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildAllocation(t *testing.T) {
+	rates := map[string]float64{"USD": 0.03125}
+	holdings := []models.Holding{
+		{AccountID: "acc-1", Ticker: "2330", AssetType: "stock", Currency: "TWD", TotalValue: 40000},
+		{AccountID: "acc-2", Ticker: "AAPL", AssetType: "stock", Currency: "USD", TotalValue: 1000},
+		{AccountID: "acc-2", Ticker: "BTC", AssetType: "crypto", Currency: "USD", TotalValue: 500},
+		{AccountID: "acc-2", Ticker: "7203.T", AssetType: "stock", Currency: "JPY", TotalValue: 100000},
+	}
+	accounts := []models.Account{
+		{ID: "acc-1", Name: "Broker TW", Currency: "TWD", Balance: 12000},
+		{ID: "acc-2", Name: "Broker US", Currency: "USD", Balance: 0},
+	}
+	tags := []models.Tag{{ID: "tag-1", Name: "Core"}}
+	tagHoldings := map[string][]models.Holding{
+		"tag-1": {{Ticker: "2330", AssetType: "stock", Currency: "TWD", TotalValue: 20000}},
+	}
+
+	report := buildAllocation("TWD", rates, holdings, accounts, tags, tagHoldings)
+
+	// 40000 + 32000 + 16000 + 12000 cash; the JPY holding has no rate
+	assert.InDelta(t, 100000, report.TotalValue, 1e-6)
+	assert.Equal(t, []string{"JPY"}, report.UnconvertedCurrencies)
+
+	assert.Equal(t, []models.AllocationBucket{
+		{Key: "stock", Name: "stock", Value: 72000, Percentage: 72},
+		{Key: "crypto", Name: "crypto", Value: 16000, Percentage: 16},
+		{Key: "cash", Name: "cash", Value: 12000, Percentage: 12},
+	}, report.ByAssetType)
+
+	assert.Equal(t, []models.AllocationBucket{
+		{Key: "TW", Name: "TW", Value: 40000, Percentage: 40},
+		{Key: "US", Name: "US", Value: 32000, Percentage: 32},
+		{Key: "CRYPTO", Name: "CRYPTO", Value: 16000, Percentage: 16},
+		{Key: "CASH", Name: "CASH", Value: 12000, Percentage: 12},
+	}, report.ByMarket)
+
+	assert.Equal(t, []models.AllocationBucket{
+		{Key: "acc-1", Name: "Broker TW", Value: 52000, Percentage: 52},
+		{Key: "acc-2", Name: "Broker US", Value: 48000, Percentage: 48},
+	}, report.ByAccount)
+
+	assert.Equal(t, []models.AllocationBucket{
+		{Key: "TWD", Name: "TWD", Value: 52000, Percentage: 52},
+		{Key: "USD", Name: "USD", Value: 48000, Percentage: 48},
+	}, report.ByCurrency)
+
+	assert.Equal(t, []models.AllocationBucket{
+		{Key: "tag-1", Name: "Core", Value: 20000, Percentage: 20},
+	}, report.ByTag)
+}
+
+func TestBuildAllocationEmpty(t *testing.T) {
+	report := buildAllocation("TWD", map[string]float64{}, nil, nil, nil, nil)
+
+	assert.Equal(t, 0.0, report.TotalValue)
+	assert.Empty(t, report.ByAssetType)
+	assert.NotNil(t, report.UnconvertedCurrencies)
+}
+
+func TestHoldingsByTag(t *testing.T) {
+	holdings := []models.Holding{
+		{AccountID: "acc-1", AssetType: "stock", Ticker: "2330", Currency: "TWD", Quantity: 1500, Price: 600, TotalValue: 900000},
+		{AccountID: "acc-2", AssetType: "stock", Ticker: "2330", Currency: "TWD", Quantity: 200, Price: 600, TotalValue: 120000},
+		{AccountID: "acc-2", AssetType: "stock", Ticker: "AAPL", Currency: "USD", Quantity: 10, Price: 200, TotalValue: 2000},
+	}
+	growth := []models.Tag{{ID: "growth"}}
+	trades := []models.Trade{
+		{AccountID: "acc-1", Type: "buy", AssetType: "stock", Ticker: "2330", Currency: "TWD", Quantity: 1000, Tags: growth},
+		{AccountID: "acc-1", Type: "buy", AssetType: "stock", Ticker: "2330", Currency: "TWD", Quantity: 1000},
+		{AccountID: "acc-1", Type: "sell", AssetType: "stock", Ticker: "2330", Currency: "TWD", Quantity: 500, Tags: growth},
+		{AccountID: "acc-2", Type: "buy", AssetType: "stock", Ticker: "2330", Currency: "TWD", Quantity: 200, Tags: growth},
+		// sold out, so the tag holds nothing of it
+		{AccountID: "acc-2", Type: "buy", AssetType: "stock", Ticker: "AAPL", Currency: "USD", Quantity: 5, Tags: []models.Tag{{ID: "us"}}},
+		{AccountID: "acc-2", Type: "sell", AssetType: "stock", Ticker: "AAPL", Currency: "USD", Quantity: 5, Tags: []models.Tag{{ID: "us"}}},
+	}
+
+	byTag := holdingsByTag(holdings, trades)
+
+	require.Len(t, byTag["growth"], 2)
+	assert.InDelta(t, 500, byTag["growth"][0].Quantity, 1e-9)
+	assert.InDelta(t, 300000, byTag["growth"][0].TotalValue, 1e-9)
+	assert.InDelta(t, 200, byTag["growth"][1].Quantity, 1e-9)
+	assert.Empty(t, byTag["us"])
+
+	t.Run("holdings across accounts", func(t *testing.T) {
+		merged := []models.Holding{{AssetType: "stock", Ticker: "2330", Currency: "TWD", Quantity: 1700, Price: 600, TotalValue: 1020000}}
+
+		byTag := holdingsByTag(merged, trades)
+
+		require.Len(t, byTag["growth"], 1)
+		assert.InDelta(t, 700, byTag["growth"][0].Quantity, 1e-9)
+		assert.InDelta(t, 420000, byTag["growth"][0].TotalValue, 1e-9)
+	})
+}
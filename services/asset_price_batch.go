@@ -0,0 +1,273 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"asset-diary/models"
+)
+
+// GetPrices quotes the Taiwan stocks with one TWSE request and the US and international stocks
+// with one FMP request, while crypto symbols, which Binance cannot batch without failing them all on one
+// unknown symbol, are quoted concurrently one at a time
+func (s *AssetPriceService) GetPrices(requests []models.PriceQuoteRequest) []models.PriceQuoteResult {
+	results := newPriceQuoteResults(requests)
+
+	var taiwan, fmp, crypto []int
+	for i, result := range results {
+		switch {
+		case result.AssetType == "crypto":
+			crypto = append(crypto, i)
+		case marketOf(result.AssetType, result.Symbol) == MarketTW:
+			taiwan = append(taiwan, i)
+		default:
+			fmp = append(fmp, i)
+		}
+	}
+
+	var wg sync.WaitGroup
+	if len(taiwan) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fillPriceQuotes(results, taiwan, s.getTaiwanStockPrices)
+		}()
+	}
+	if len(fmp) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fillPriceQuotes(results, fmp, s.getFMPStockPrices)
+		}()
+	}
+	for _, i := range crypto {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			info, err := s.getCryptoPrice(results[i].Symbol)
+			setPriceQuote(&results[i], info, err)
+		}(i)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// getTaiwanStockPrices requests several listed and OTC stocks at once through the ex_ch parameter,
+// on the exchange the symbol master knows or on both for unknown codes. Emerging stocks, and
+// unknown codes neither exchange quotes, are looked up on the TPEx emerging board.
+func (s *AssetPriceService) getTaiwanStockPrices(symbols []string) (map[string]*models.TickerInfo, error) {
+	channels, emerging, unknown := taiwanStockChannels(symbols, s.taiwanSymbols)
+
+	quotes := make(map[string]*models.TickerInfo, len(symbols))
+	if len(channels) > 0 {
+		if err := s.getTaiwanMISPrices(channels, quotes); err != nil {
+			return nil, err
+		}
+	}
+
+	var unquoted []string
+	for _, symbol := range unknown {
+		if _, ok := quotes[symbol]; !ok {
+			unquoted = append(unquoted, symbol)
+		}
+	}
+	if len(emerging) == 0 && len(unquoted) == 0 {
+		return quotes, nil
+	}
+
+	if err := s.getTaiwanEmergingStockPrices(append(emerging, unquoted...), quotes); err != nil {
+		if len(emerging) > 0 {
+			return nil, err
+		}
+		// the unknown codes are most likely invalid rather than emerging
+		log.Printf("Failed to fetch Taiwan emerging stock data: %v", err)
+	}
+	return quotes, nil
+}
+
+// getTaiwanMISPrices adds the quotes of the TWSE MIS channels to quotes and teaches the symbol
+// master the exchange each code was found on
+func (s *AssetPriceService) getTaiwanMISPrices(channels []string, quotes map[string]*models.TickerInfo) error {
+	targetUrl := fmt.Sprintf("https://mis.twse.com.tw/stock/api/getStockInfo.jsp?ex_ch=%s", strings.Join(channels, "|"))
+	proxyUrl := fmt.Sprintf("https://api.allorigins.win/raw?url=%s", url.QueryEscape(targetUrl))
+
+	req, err := http.NewRequest("GET", proxyUrl, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch Taiwan stock data: %s", resp.Status)
+	}
+
+	var data TaiwanStockResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return err
+	}
+
+	for _, stockInfo := range data.MsgArray {
+		if stockInfo.Ex != "" {
+			s.taiwanSymbols.Remember(stockInfo.C, stockInfo.Ex)
+		}
+		price, err := strconv.ParseFloat(stockInfo.Z, 64)
+		if err != nil || price <= 0 {
+			continue
+		}
+		quotes[stockInfo.C] = &models.TickerInfo{
+			AssetType:   "stock",
+			Price:       price,
+			Symbol:      stockInfo.C,
+			Name:        stockInfo.N,
+			Currency:    "TWD",
+			LastUpdated: time.Now().Format(time.RFC3339),
+		}
+	}
+	return nil
+}
+
+// getTaiwanEmergingStockPrices adds the latest emerging board trades of symbols to quotes. The
+// board is only published as a whole, so one request serves every symbol.
+func (s *AssetPriceService) getTaiwanEmergingStockPrices(symbols []string, quotes map[string]*models.TickerInfo) error {
+	wanted := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		wanted[symbol] = true
+	}
+
+	rows, err := fetchTaiwanOpenData(s.httpClient, "https://www.tpex.org.tw/openapi/v1/tpex_esb_latest_statistics")
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		code := strings.ToUpper(openDataField(row, "SecuritiesCompanyCode"))
+		if !wanted[code] {
+			continue
+		}
+		s.taiwanSymbols.Remember(code, TaiwanExchangeEmerging)
+
+		// fall back to the previous day's average when nothing traded yet today
+		price, err := strconv.ParseFloat(openDataField(row, "LatestPrice"), 64)
+		if err != nil || price <= 0 {
+			price, err = strconv.ParseFloat(openDataField(row, "PreviousAveragePrice"), 64)
+			if err != nil || price <= 0 {
+				continue
+			}
+		}
+		quotes[code] = &models.TickerInfo{
+			AssetType:   "stock",
+			Price:       price,
+			Symbol:      code,
+			Name:        openDataField(row, "CompanyName"),
+			Currency:    "TWD",
+			LastUpdated: time.Now().Format(time.RFC3339),
+		}
+	}
+	return nil
+}
+
+// getFMPStockPrices requests several US and international stocks with the FMP batch quote endpoint
+func (s *AssetPriceService) getFMPStockPrices(symbols []string) (map[string]*models.TickerInfo, error) {
+	apiKey := os.Getenv("FMP_API_KEY")
+	quoteUrl := fmt.Sprintf("https://financialmodelingprep.com/stable/batch-quote?symbols=%s&apikey=%s", strings.Join(symbols, ","), apiKey)
+
+	req, err := http.NewRequest("GET", quoteUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch US stock data: %s", resp.Status)
+	}
+
+	var quotes []struct {
+		Symbol string  `json:"symbol"`
+		Name   string  `json:"name"`
+		Price  float64 `json:"price"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&quotes); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*models.TickerInfo, len(quotes))
+	for _, quote := range quotes {
+		parsed := ParseStockSymbol(quote.Symbol)
+		result[parsed.Symbol] = &models.TickerInfo{
+			AssetType:   "stock",
+			Price:       parsed.price(quote.Price),
+			Symbol:      quote.Symbol,
+			Name:        quote.Name,
+			Currency:    parsed.Currency,
+			LastUpdated: time.Now().Format(time.RFC3339),
+		}
+	}
+	return result, nil
+}
+
+// newPriceQuoteResults prepares one result per request with its symbol normalized
+func newPriceQuoteResults(requests []models.PriceQuoteRequest) []models.PriceQuoteResult {
+	results := make([]models.PriceQuoteResult, len(requests))
+	for i, request := range requests {
+		results[i] = models.PriceQuoteResult{
+			AssetType: request.AssetType,
+			Symbol:    strings.ToUpper(strings.TrimSpace(request.Symbol)),
+		}
+	}
+	return results
+}
+
+// fillPriceQuotes fetches the distinct symbols of the indexed results in one call. Symbols missing
+// from the response are reported as invalid.
+func fillPriceQuotes(results []models.PriceQuoteResult, indices []int, fetch func(symbols []string) (map[string]*models.TickerInfo, error)) {
+	seen := make(map[string]bool, len(indices))
+	symbols := make([]string, 0, len(indices))
+	for _, i := range indices {
+		if !seen[results[i].Symbol] {
+			seen[results[i].Symbol] = true
+			symbols = append(symbols, results[i].Symbol)
+		}
+	}
+
+	quotes, err := fetch(symbols)
+	for _, i := range indices {
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		if quote, ok := quotes[results[i].Symbol]; ok {
+			results[i].Quote = quote
+		} else {
+			results[i].Error = InvalidSymbolError
+		}
+	}
+}
+
+func setPriceQuote(result *models.PriceQuoteResult, info *models.TickerInfo, err error) {
+	if err != nil {
+		result.Error = err.Error()
+		return
+	}
+	result.Quote = info
+}
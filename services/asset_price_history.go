@@ -0,0 +1,408 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"asset-diary/models"
+)
+
+// twseRequestInterval keeps monthly history requests under the TWSE and TPEx rate limits
+const twseRequestInterval = 2 * time.Second
+
+const binanceKlineLimit = 1000
+
+func (s *AssetPriceService) GetStockPriceHistory(symbol string, startDate, endDate time.Time) ([]models.PriceHistory, error) {
+	parsed := ParseStockSymbol(symbol)
+
+	if parsed.Market == MarketTW {
+		return s.getTaiwanStockPriceHistory(parsed.Symbol, startDate, endDate)
+	}
+	return s.getFMPStockPriceHistory(parsed, startDate, endDate)
+}
+
+func (s *AssetPriceService) GetCryptoPriceHistory(symbol string, startDate, endDate time.Time) ([]models.PriceHistory, error) {
+	if symbol == "" {
+		return nil, fmt.Errorf("symbol is required")
+	}
+
+	return s.getCryptoPriceHistory(strings.ToUpper(symbol), startDate, endDate)
+}
+
+type TaiwanStockDayResponse struct {
+	Stat string     `json:"stat"`
+	Data [][]string `json:"data"` // date (ROC calendar), volume, turnover, open, high, low, close, change, transactions
+}
+
+// TPExTradingStockResponse is the TPEx daily trading report of a stock, whose rows have the
+// STOCK_DAY layout
+type TPExTradingStockResponse struct {
+	Stat   string `json:"stat"`
+	Tables []struct {
+		Data [][]string `json:"data"`
+	} `json:"tables"`
+}
+
+// getTaiwanStockPriceHistory reads the daily history from the exchange the symbol master knows.
+// Codes not in the master are tried on TWSE, then on TPEx and the emerging board, until one of
+// them has trades in the range.
+func (s *AssetPriceService) getTaiwanStockPriceHistory(symbol string, startDate, endDate time.Time) ([]models.PriceHistory, error) {
+	exchange, known := s.taiwanSymbols.Exchange(symbol)
+	if known {
+		return s.getTaiwanExchangePriceHistory(exchange, symbol, startDate, endDate)
+	}
+
+	var prices []models.PriceHistory
+	var err error
+	for _, exchange := range []string{TaiwanExchangeTWSE, TaiwanExchangeTPEx, TaiwanExchangeEmerging} {
+		prices, err = s.getTaiwanExchangePriceHistory(exchange, symbol, startDate, endDate)
+		if err == nil && len(prices) > 0 {
+			s.taiwanSymbols.Remember(symbol, exchange)
+			return prices, nil
+		}
+	}
+	return prices, err
+}
+
+func (s *AssetPriceService) getTaiwanExchangePriceHistory(exchange, symbol string, startDate, endDate time.Time) ([]models.PriceHistory, error) {
+	switch exchange {
+	case TaiwanExchangeTPEx:
+		return s.getTaiwanMonthlyPriceHistory(symbol, startDate, endDate, "tpex", s.fetchTPExStockMonth)
+	case TaiwanExchangeEmerging:
+		return s.getTaiwanEmergingStockPriceHistory(symbol, startDate, endDate)
+	}
+	return s.getTaiwanMonthlyPriceHistory(symbol, startDate, endDate, "twse", s.fetchTWSEStockMonth)
+}
+
+// getTaiwanMonthlyPriceHistory requests a monthly daily trading report one month at a time
+func (s *AssetPriceService) getTaiwanMonthlyPriceHistory(
+	symbol string,
+	startDate, endDate time.Time,
+	source string,
+	fetchMonth func(symbol string, month time.Time) ([][]string, error),
+) ([]models.PriceHistory, error) {
+	var prices []models.PriceHistory
+
+	month := time.Date(startDate.Year(), startDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for first := true; !month.After(endDate); first = false {
+		if !first {
+			time.Sleep(twseRequestInterval)
+		}
+
+		rows, err := fetchMonth(symbol, month)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			price, ok := parseTaiwanStockDayRow(row)
+			if !ok || price.Date.Before(startDate) || price.Date.After(endDate) {
+				continue
+			}
+			price.Symbol = symbol
+			price.Source = source
+			prices = append(prices, price)
+		}
+
+		month = month.AddDate(0, 1, 0)
+	}
+
+	return prices, nil
+}
+
+// fetchTWSEStockMonth reads the TWSE daily trading report of a listed stock for a month
+func (s *AssetPriceService) fetchTWSEStockMonth(symbol string, month time.Time) ([][]string, error) {
+	url := fmt.Sprintf("https://www.twse.com.tw/rwd/zh/afterTrading/STOCK_DAY?date=%s&stockNo=%s&response=json", month.Format("20060102"), symbol)
+
+	var data TaiwanStockDayResponse
+	if err := s.getTaiwanJSON(url, &data); err != nil {
+		return nil, err
+	}
+	return data.Data, nil
+}
+
+// fetchTPExStockMonth reads the TPEx daily trading report of an OTC stock for a month
+func (s *AssetPriceService) fetchTPExStockMonth(symbol string, month time.Time) ([][]string, error) {
+	url := fmt.Sprintf("https://www.tpex.org.tw/www/zh-tw/afterTrading/tradingStock?code=%s&date=%s&response=json", symbol, month.Format("2006/01/02"))
+
+	var data TPExTradingStockResponse
+	if err := s.getTaiwanJSON(url, &data); err != nil {
+		return nil, err
+	}
+	var rows [][]string
+	for _, table := range data.Tables {
+		rows = append(rows, table.Data...)
+	}
+	return rows, nil
+}
+
+func (s *AssetPriceService) getTaiwanJSON(url string, target any) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch Taiwan stock history: %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+// getTaiwanEmergingStockPriceHistory returns the latest emerging board session when it falls in
+// the range. TPEx only publishes the board as its latest statistics, so the history of emerging
+// stocks is built up by recording their closing prices daily and older ranges stay empty.
+func (s *AssetPriceService) getTaiwanEmergingStockPriceHistory(symbol string, startDate, endDate time.Time) ([]models.PriceHistory, error) {
+	rows, err := fetchTaiwanOpenData(s.httpClient, "https://www.tpex.org.tw/openapi/v1/tpex_esb_latest_statistics")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		if strings.ToUpper(openDataField(row, "SecuritiesCompanyCode")) != symbol {
+			continue
+		}
+		price, ok := parseTaiwanEmergingRow(row)
+		if !ok || price.Date.Before(startDate) || price.Date.After(endDate) {
+			return nil, nil
+		}
+		price.Symbol = symbol
+		return []models.PriceHistory{price}, nil
+	}
+	return nil, nil
+}
+
+// parseTaiwanEmergingRow reads the session of an emerging board statistics row. Stocks that did
+// not trade in the session are skipped.
+func parseTaiwanEmergingRow(row map[string]any) (models.PriceHistory, bool) {
+	date, ok := parseROCDate(openDataField(row, "Date"))
+	if !ok {
+		return models.PriceHistory{}, false
+	}
+
+	closePrice, err := strconv.ParseFloat(strings.ReplaceAll(openDataField(row, "LatestPrice"), ",", ""), 64)
+	if err != nil || closePrice <= 0 {
+		return models.PriceHistory{}, false
+	}
+
+	return models.PriceHistory{
+		AssetType: "stock",
+		Date:      date,
+		Close:     closePrice,
+		Currency:  "TWD",
+		Source:    "tpex",
+	}, true
+}
+
+// parseROCDate reads a Republic of China calendar date written as 113/10/18 or 1131018
+func parseROCDate(value string) (time.Time, bool) {
+	var parts []string
+	if strings.Contains(value, "/") {
+		parts = strings.Split(value, "/")
+	} else if len(value) == 7 {
+		parts = []string{value[:3], value[3:5], value[5:]}
+	}
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	rocYear, errYear := strconv.Atoi(strings.TrimSpace(parts[0]))
+	monthNum, errMonth := strconv.Atoi(strings.TrimSpace(parts[1]))
+	day, errDay := strconv.Atoi(strings.TrimSpace(parts[2]))
+	if errYear != nil || errMonth != nil || errDay != nil {
+		return time.Time{}, false
+	}
+	return time.Date(rocYear+1911, time.Month(monthNum), day, 0, 0, 0, 0, time.UTC), true
+}
+
+// parseTaiwanStockDayRow reads a STOCK_DAY or TPEx trading report row; days without trades have "--" prices and are skipped
+func parseTaiwanStockDayRow(row []string) (models.PriceHistory, bool) {
+	if len(row) < 7 {
+		return models.PriceHistory{}, false
+	}
+
+	date, ok := parseROCDate(strings.TrimSpace(row[0]))
+	if !ok {
+		return models.PriceHistory{}, false
+	}
+
+	parse := func(value string) (float64, bool) {
+		number, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(value), ",", ""), 64)
+		return number, err == nil && number > 0
+	}
+	closePrice, ok := parse(row[6])
+	if !ok {
+		return models.PriceHistory{}, false
+	}
+
+	price := models.PriceHistory{
+		AssetType: "stock",
+		Date:      date,
+		Close:     closePrice,
+		Currency:  "TWD",
+	}
+	if open, ok := parse(row[3]); ok {
+		price.Open = &open
+	}
+	if high, ok := parse(row[4]); ok {
+		price.High = &high
+	}
+	if low, ok := parse(row[5]); ok {
+		price.Low = &low
+	}
+	return price, true
+}
+
+// getFMPStockPriceHistory requests the daily bars of a US or international stock, in the
+// currency of its market
+func (s *AssetPriceService) getFMPStockPriceHistory(symbol StockSymbol, startDate, endDate time.Time) ([]models.PriceHistory, error) {
+	apiKey := os.Getenv("FMP_API_KEY")
+	url := fmt.Sprintf("https://financialmodelingprep.com/stable/historical-price-eod/full?symbol=%s&from=%s&to=%s&apikey=%s",
+		symbol.Symbol, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"), apiKey)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch stock history: %s", resp.Status)
+	}
+
+	var bars []struct {
+		Date  string  `json:"date"`
+		Open  float64 `json:"open"`
+		High  float64 `json:"high"`
+		Low   float64 `json:"low"`
+		Close float64 `json:"close"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&bars); err != nil {
+		return nil, err
+	}
+
+	prices := make([]models.PriceHistory, 0, len(bars))
+	for _, bar := range bars {
+		date, err := time.Parse("2006-01-02", bar.Date)
+		if err != nil || bar.Close <= 0 {
+			continue
+		}
+		open, high, low := symbol.price(bar.Open), symbol.price(bar.High), symbol.price(bar.Low)
+		prices = append(prices, models.PriceHistory{
+			AssetType: "stock",
+			Symbol:    symbol.Symbol,
+			Date:      date,
+			Open:      &open,
+			High:      &high,
+			Low:       &low,
+			Close:     symbol.price(bar.Close),
+			Currency:  symbol.Currency,
+			Source:    "fmp",
+		})
+	}
+
+	return prices, nil
+}
+
+// getCryptoPriceHistory pages through Binance daily klines against USDT
+func (s *AssetPriceService) getCryptoPriceHistory(symbol string, startDate, endDate time.Time) ([]models.PriceHistory, error) {
+	var prices []models.PriceHistory
+
+	from := startDate
+	for !from.After(endDate) {
+		url := fmt.Sprintf("https://data-api.binance.vision/api/v3/klines?symbol=%sUSDT&interval=1d&startTime=%d&endTime=%d&limit=%d",
+			symbol, from.UnixMilli(), endDate.Add(24*time.Hour-time.Millisecond).UnixMilli(), binanceKlineLimit)
+
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := s.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch crypto history: %v", err)
+		}
+
+		var klines [][]json.RawMessage
+		err = json.NewDecoder(resp.Body).Decode(&klines)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			if resp.StatusCode == http.StatusBadRequest {
+				return nil, errors.New(InvalidSymbolError)
+			}
+			return nil, fmt.Errorf("failed to fetch crypto history: %s", resp.Status)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode crypto history response: %v", err)
+		}
+
+		for _, kline := range klines {
+			price, ok := parseBinanceKline(kline)
+			if !ok {
+				continue
+			}
+			price.Symbol = symbol
+			prices = append(prices, price)
+		}
+
+		if len(klines) < binanceKlineLimit {
+			break
+		}
+		from = prices[len(prices)-1].Date.AddDate(0, 0, 1)
+	}
+
+	return prices, nil
+}
+
+// parseBinanceKline reads [openTime, open, high, low, close, ...] with prices as strings
+func parseBinanceKline(kline []json.RawMessage) (models.PriceHistory, bool) {
+	if len(kline) < 5 {
+		return models.PriceHistory{}, false
+	}
+
+	var openTime int64
+	if err := json.Unmarshal(kline[0], &openTime); err != nil {
+		return models.PriceHistory{}, false
+	}
+
+	values := make([]float64, 4)
+	for i := range values {
+		var text string
+		if err := json.Unmarshal(kline[i+1], &text); err != nil {
+			return models.PriceHistory{}, false
+		}
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return models.PriceHistory{}, false
+		}
+		values[i] = value
+	}
+
+	return models.PriceHistory{
+		AssetType: "crypto",
+		Date:      time.UnixMilli(openTime).UTC().Truncate(24 * time.Hour),
+		Open:      &values[0],
+		High:      &values[1],
+		Low:       &values[2],
+		Close:     values[3],
+		Currency:  "USDT",
+		Source:    "binance",
+	}, true
+}
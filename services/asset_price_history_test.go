@@ -0,0 +1,44 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseROCDate(t *testing.T) {
+	day, ok := parseROCDate("115/10/16")
+	assert.True(t, ok)
+	assert.Equal(t, date(2026, 10, 16), day)
+
+	day, ok = parseROCDate("1151016")
+	assert.True(t, ok)
+	assert.Equal(t, date(2026, 10, 16), day)
+
+	_, ok = parseROCDate("2026-10-16")
+	assert.False(t, ok)
+}
+
+func TestParseTaiwanStockDayRow(t *testing.T) {
+	price, ok := parseTaiwanStockDayRow([]string{"115/10/16", "1,234", "56,789", "45.10", "45.50", "44.90", "1,045.30", "0.20", "567"})
+	require.True(t, ok)
+	assert.Equal(t, date(2026, 10, 16), price.Date)
+	assert.InDelta(t, 1045.3, price.Close, 1e-9)
+	assert.InDelta(t, 45.1, *price.Open, 1e-9)
+
+	// days without trades have no prices
+	_, ok = parseTaiwanStockDayRow([]string{"115/10/17", "0", "0", "--", "--", "--", "--", "X0.00", "0"})
+	assert.False(t, ok)
+}
+
+func TestParseTaiwanEmergingRow(t *testing.T) {
+	price, ok := parseTaiwanEmergingRow(map[string]any{"Date": "1151016", "SecuritiesCompanyCode": "7795", "LatestPrice": "88.5"})
+	require.True(t, ok)
+	assert.Equal(t, date(2026, 10, 16), price.Date)
+	assert.InDelta(t, 88.5, price.Close, 1e-9)
+	assert.Equal(t, "tpex", price.Source)
+
+	_, ok = parseTaiwanEmergingRow(map[string]any{"Date": "1151016", "LatestPrice": ""})
+	assert.False(t, ok)
+}
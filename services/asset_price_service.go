@@ -7,39 +7,41 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"regexp"
 	"strconv"
-	"strings"
 	"time"
 
 	"asset-diary/models"
+	"asset-diary/repositories"
 )
 
 type AssetPriceService struct {
-	httpClient *http.Client
+	httpClient    *http.Client
+	taiwanSymbols *TaiwanSymbolMaster
 }
 
-func NewAssetPriceService() *AssetPriceService {
+func NewAssetPriceService(symbolRepo repositories.SymbolRepositoryInterface) *AssetPriceService {
 	return &AssetPriceService{
-		httpClient: &http.Client{},
+		httpClient:    &http.Client{},
+		taiwanSymbols: NewTaiwanSymbolMaster(symbolRepo),
 	}
 }
 
 type TaiwanStockResponse struct {
 	MsgArray []struct {
-		C string `json:"c"` // stock code
-		N string `json:"n"` // stock name
-		Z string `json:"z"` // latest price
+		C  string `json:"c"`  // stock code
+		N  string `json:"n"`  // stock name
+		Z  string `json:"z"`  // latest price
+		Ex string `json:"ex"` // exchange, tse or otc
 	} `json:"msgArray"`
 }
 
 func (s *AssetPriceService) GetStockPrice(symbol string) (*models.TickerInfo, error) {
-	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	parsed := ParseStockSymbol(symbol)
 
-	if matched, _ := regexp.MatchString(`^\d`, symbol); matched {
-		return s.getTaiwanStockPrice(symbol)
+	if parsed.Market == MarketTW {
+		return s.getTaiwanStockPrice(parsed.Symbol)
 	}
-	return s.getUSStockPrice(symbol)
+	return s.getFMPStockPrice(parsed.Symbol)
 }
 
 func (s *AssetPriceService) GetCryptoPrice(symbol string) (*models.TickerInfo, error) {
@@ -51,49 +53,16 @@ func (s *AssetPriceService) GetCryptoPrice(symbol string) (*models.TickerInfo, e
 }
 
 func (s *AssetPriceService) getTaiwanStockPrice(symbol string) (*models.TickerInfo, error) {
-	targetUrl := fmt.Sprintf("https://mis.twse.com.tw/stock/api/getStockInfo.jsp?ex_ch=tse_%s.tw&_=CURRENT_TIME", symbol)
-	proxyUrl := fmt.Sprintf("https://api.allorigins.win/raw?url=%s", targetUrl)
-
-	req, err := http.NewRequest("GET", proxyUrl, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
-
-	resp, err := s.httpClient.Do(req)
+	quotes, err := s.getTaiwanStockPrices([]string{symbol})
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch Taiwan stock data: %s", resp.Status)
-	}
-
-	var data TaiwanStockResponse
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return nil, err
-	}
-
-	if len(data.MsgArray) == 0 {
-		return nil, errors.New(InvalidSymbolError)
-	}
 
-	stockInfo := data.MsgArray[0]
-	price, err := strconv.ParseFloat(stockInfo.Z, 64)
-	if err != nil || price <= 0 {
+	quote, ok := quotes[symbol]
+	if !ok {
 		return nil, errors.New(InvalidSymbolError)
 	}
-
-	return &models.TickerInfo{
-		AssetType:   "stock",
-		Price:       price,
-		Symbol:      symbol,
-		Name:        stockInfo.N,
-		Currency:    "TWD",
-		LastUpdated: time.Now().Format(time.RFC3339),
-	}, nil
+	return quote, nil
 }
 
 func (s *AssetPriceService) getCryptoPrice(symbol string) (*models.TickerInfo, error) {
@@ -141,7 +110,8 @@ func (s *AssetPriceService) getCryptoPrice(symbol string) (*models.TickerInfo, e
 	}, nil
 }
 
-func (s *AssetPriceService) getUSStockPrice(symbol string) (*models.TickerInfo, error) {
+// getFMPStockPrice quotes a US or exchange-suffixed international stock
+func (s *AssetPriceService) getFMPStockPrice(symbol string) (*models.TickerInfo, error) {
 	apiKey := os.Getenv("FMP_API_KEY")
 	url := fmt.Sprintf("https://financialmodelingprep.com/stable/quote?symbol=%s&apikey=%s", symbol, apiKey)
 
@@ -180,12 +150,13 @@ func (s *AssetPriceService) getUSStockPrice(symbol string) (*models.TickerInfo,
 	}
 
 	quote := quotes[0]
+	parsed := ParseStockSymbol(quote.Symbol)
 	return &models.TickerInfo{
 		AssetType:   "stock",
-		Price:       quote.Price,
+		Price:       parsed.price(quote.Price),
 		Symbol:      quote.Symbol,
 		Name:        quote.Name,
-		Currency:    "USD",
+		Currency:    parsed.Currency,
 		LastUpdated: time.Now().Format(time.RFC3339),
 	}, nil
 }
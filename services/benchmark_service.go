@@ -0,0 +1,221 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"asset-diary/models"
+	"asset-diary/repositories"
+)
+
+// benchmarkCatalog lists the benchmarks users can compare against. Indices that the price
+// services cannot quote directly are tracked through an ETF following them.
+var benchmarkCatalog = []models.Benchmark{
+	{Code: "TAIEX", Name: "TAIEX (0050)", AssetType: "stock", Symbol: "0050"},
+	{Code: "SP500", Name: "S&P 500 (SPY)", AssetType: "stock", Symbol: "SPY"},
+	{Code: "BTC", Name: "Bitcoin", AssetType: "crypto", Symbol: "BTC"},
+}
+
+func findBenchmark(code string) (models.Benchmark, bool) {
+	for _, benchmark := range benchmarkCatalog {
+		if benchmark.Code == strings.ToUpper(code) {
+			return benchmark, true
+		}
+	}
+	return models.Benchmark{}, false
+}
+
+type BenchmarkServiceInterface interface {
+	ListBenchmarks(userID string) ([]models.Benchmark, error)
+	SetUserBenchmarks(userID string, codes []string) ([]models.Benchmark, error)
+	GetComparison(userID string, codes []string, startDate, endDate time.Time) (*models.BenchmarkComparison, error)
+}
+
+type BenchmarkService struct {
+	repo               repositories.BenchmarkRepositoryInterface
+	historicalPriceSvc HistoricalPriceServiceInterface
+	dailyAssetSvc      DailyTotalAssetValueServiceInterface
+	accountSvc         AccountServiceInterface
+	tradeSvc           TradeServiceInterface
+	profileSvc         ProfileServiceInterface
+	exchangeSvc        ExchangeRateServiceInterface
+}
+
+func NewBenchmarkService(
+	repo repositories.BenchmarkRepositoryInterface,
+	historicalPriceSvc HistoricalPriceServiceInterface,
+	dailyAssetSvc DailyTotalAssetValueServiceInterface,
+	accountSvc AccountServiceInterface,
+	tradeSvc TradeServiceInterface,
+	profileSvc ProfileServiceInterface,
+	exchangeSvc ExchangeRateServiceInterface,
+) *BenchmarkService {
+	return &BenchmarkService{
+		repo:               repo,
+		historicalPriceSvc: historicalPriceSvc,
+		dailyAssetSvc:      dailyAssetSvc,
+		accountSvc:         accountSvc,
+		tradeSvc:           tradeSvc,
+		profileSvc:         profileSvc,
+		exchangeSvc:        exchangeSvc,
+	}
+}
+
+// ListBenchmarks returns the benchmark catalog, flagging the ones the user selected
+func (s *BenchmarkService) ListBenchmarks(userID string) ([]models.Benchmark, error) {
+	codes, err := s.repo.ListUserBenchmarkCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		selected[code] = true
+	}
+
+	benchmarks := make([]models.Benchmark, len(benchmarkCatalog))
+	for i, benchmark := range benchmarkCatalog {
+		benchmark.Selected = selected[benchmark.Code]
+		benchmarks[i] = benchmark
+	}
+	return benchmarks, nil
+}
+
+func (s *BenchmarkService) SetUserBenchmarks(userID string, codes []string) ([]models.Benchmark, error) {
+	normalized, err := normalizeBenchmarkCodes(codes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetUserBenchmarkCodes(userID, normalized); err != nil {
+		return nil, err
+	}
+	return s.ListBenchmarks(userID)
+}
+
+// GetComparison returns the time-weighted portfolio index and the benchmark closes over the period,
+// all rebased to 100 at their first point. Without explicit codes the user's selection is used,
+// and the whole catalog when the user has not selected any. Benchmark closes are read from the
+// price history, which is backfilled when it does not cover the period.
+func (s *BenchmarkService) GetComparison(userID string, codes []string, startDate, endDate time.Time) (*models.BenchmarkComparison, error) {
+	codes, err := normalizeBenchmarkCodes(codes)
+	if err != nil {
+		return nil, err
+	}
+	if len(codes) == 0 {
+		if codes, err = s.repo.ListUserBenchmarkCodes(userID); err != nil {
+			return nil, err
+		}
+	}
+	if len(codes) == 0 {
+		for _, benchmark := range benchmarkCatalog {
+			codes = append(codes, benchmark.Code)
+		}
+	}
+
+	defaultCurrency, err := s.profileSvc.GetDefaultCurrency(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user profile: %w", err)
+	}
+
+	snapshots, err := s.dailyAssetSvc.GetUserDailyTotalAssetValues(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	flows, err := loadPortfolioCashFlows(s.accountSvc, s.tradeSvc, s.exchangeSvc, userID, defaultCurrency, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &models.BenchmarkComparison{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Currency:  defaultCurrency,
+		Portfolio: portfolioSeries(snapshots, flows),
+	}
+
+	comparison.Benchmarks = make([]models.BenchmarkSeries, 0, len(codes))
+	for _, code := range codes {
+		benchmark, _ := findBenchmark(code)
+		prices, err := s.historicalPriceSvc.GetPriceHistory(benchmark.AssetType, benchmark.Symbol, startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+
+		closes := make([]cashFlow, len(prices))
+		for i, price := range prices {
+			closes[i] = cashFlow{Date: price.Date, Amount: price.Close}
+		}
+		series := normalizedSeries(closes)
+		series.Code = benchmark.Code
+		series.Name = benchmark.Name
+		comparison.Benchmarks = append(comparison.Benchmarks, series)
+	}
+
+	return comparison, nil
+}
+
+// normalizeBenchmarkCodes upper-cases and de-duplicates codes, rejecting codes outside the catalog
+func normalizeBenchmarkCodes(codes []string) ([]string, error) {
+	seen := make(map[string]bool, len(codes))
+	normalized := make([]string, 0, len(codes))
+	for _, code := range codes {
+		benchmark, ok := findBenchmark(strings.TrimSpace(code))
+		if !ok {
+			return nil, models.NewAppError(models.ErrCodeInvalidRequest, fmt.Sprintf("Unknown benchmark: %s", code))
+		}
+		if seen[benchmark.Code] {
+			continue
+		}
+		seen[benchmark.Code] = true
+		normalized = append(normalized, benchmark.Code)
+	}
+	return normalized, nil
+}
+
+// portfolioSeries turns the daily snapshots into a time-weighted index so deposits, withdrawals
+// and trades do not show up as gains or losses
+func portfolioSeries(snapshots []models.UserDailyTotalAssetValue, flows []cashFlow) models.BenchmarkSeries {
+	series := models.BenchmarkSeries{Code: "PORTFOLIO", Name: "Portfolio", Points: []models.SeriesPoint{}}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Date.Before(snapshots[j].Date)
+	})
+	values := make([]cashFlow, len(snapshots))
+	for i, snapshot := range snapshots {
+		values[i] = cashFlow{Date: snapshot.Date, Amount: snapshot.TotalValue}
+	}
+
+	index, linked := timeWeightedIndex(values, flows)
+	for i, growth := range index {
+		series.Points = append(series.Points, models.SeriesPoint{Date: values[i].Date, Value: growth * 100})
+	}
+	if linked {
+		series.ReturnPercentage = percentage(index[len(index)-1] - 1)
+	}
+	return series
+}
+
+// normalizedSeries rebases prices to 100 at the first positive price
+func normalizedSeries(prices []cashFlow) models.BenchmarkSeries {
+	series := models.BenchmarkSeries{Points: []models.SeriesPoint{}}
+
+	base := 0.0
+	for _, price := range prices {
+		if base == 0 {
+			if price.Amount <= 0 {
+				continue
+			}
+			base = price.Amount
+		}
+		series.Points = append(series.Points, models.SeriesPoint{Date: price.Date, Value: price.Amount / base * 100})
+	}
+
+	if len(series.Points) > 1 {
+		series.ReturnPercentage = percentage(series.Points[len(series.Points)-1].Value/100 - 1)
+	}
+	return series
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"asset-diary/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizedSeries(t *testing.T) {
+	series := normalizedSeries([]cashFlow{
+		{Date: date(2026, 1, 1), Amount: 0},
+		{Date: date(2026, 1, 2), Amount: 50},
+		{Date: date(2026, 1, 3), Amount: 55},
+		{Date: date(2026, 1, 4), Amount: 45},
+	})
+
+	assert.Len(t, series.Points, 3)
+	assert.Equal(t, date(2026, 1, 2), series.Points[0].Date)
+	assert.InDelta(t, 100, series.Points[0].Value, 1e-9)
+	assert.InDelta(t, 110, series.Points[1].Value, 1e-9)
+	assert.InDelta(t, 90, series.Points[2].Value, 1e-9)
+	assert.InDelta(t, -10, *series.ReturnPercentage, 1e-9)
+}
+
+func TestPortfolioSeriesExcludesDeposits(t *testing.T) {
+	snapshots := []models.UserDailyTotalAssetValue{
+		{Date: date(2026, 1, 3), TotalValue: 2200},
+		{Date: date(2026, 1, 1), TotalValue: 1000},
+		{Date: date(2026, 1, 2), TotalValue: 2100},
+	}
+	deposits := []cashFlow{{Date: date(2026, 1, 2), Amount: 1000}}
+
+	series := portfolioSeries(snapshots, deposits)
+
+	assert.Len(t, series.Points, 3)
+	assert.InDelta(t, 100, series.Points[0].Value, 1e-9)
+	assert.InDelta(t, 110, series.Points[1].Value, 1e-9)
+	assert.InDelta(t, 110*2200.0/2100.0, series.Points[2].Value, 1e-9)
+}
+
+func TestPortfolioSeriesExcludesTrades(t *testing.T) {
+	snapshots := []models.UserDailyTotalAssetValue{
+		{Date: date(2026, 1, 1), TotalValue: 1000},
+		{Date: date(2026, 1, 2), TotalValue: 1550},
+		{Date: date(2026, 1, 3), TotalValue: 1050},
+	}
+	// buying 500 of a stock and selling it again the next day leaves the cash untouched
+	trades := []models.Trade{
+		{Type: "buy", Quantity: 10, Price: 50, Currency: "TWD", TradeDate: date(2026, 1, 2)},
+		{Type: "sell", Quantity: 10, Price: 50, Currency: "TWD", TradeDate: date(2026, 1, 3)},
+	}
+	ratesOn := func(time.Time) map[string]float64 { return map[string]float64{} }
+
+	series := portfolioSeries(snapshots, portfolioCashFlows(nil, trades, "TWD", ratesOn))
+
+	assert.Len(t, series.Points, 3)
+	assert.InDelta(t, 105, series.Points[1].Value, 1e-9)
+	assert.InDelta(t, 105, series.Points[2].Value, 1e-9)
+	assert.InDelta(t, 5, *series.ReturnPercentage, 1e-9)
+}
+
+func TestNormalizeBenchmarkCodes(t *testing.T) {
+	codes, err := normalizeBenchmarkCodes([]string{"taiex", " BTC", "TAIEX"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"TAIEX", "BTC"}, codes)
+
+	_, err = normalizeBenchmarkCodes([]string{"NIKKEI"})
+	assert.Error(t, err)
+}
@@ -2,15 +2,25 @@ package services
 
 import (
 	"log"
+	"sync"
 	"time"
 
 	"asset-diary/models"
 	"asset-diary/repositories"
 )
 
+const (
+	// snapshotLocalHour is the local hour from which a user's day is recorded
+	snapshotLocalHour        = 23
+	defaultSnapshotRangeDays = 30
+)
+
 type DailyTotalAssetValueServiceInterface interface {
 	RecordDailyTotalAssetValue() error
 	GetUserDailyTotalAssetValues(userID string, startDate, endDate time.Time) ([]models.UserDailyTotalAssetValue, error)
+	GetBreakdown(userID string, date time.Time) (*models.DailyAssetBreakdown, error)
+	GetTickerSeries(userID, assetType, ticker string, startDate, endDate time.Time) ([]models.TickerValuePoint, error)
+	GetAccountSeries(userID, accountID string, startDate, endDate time.Time) ([]models.AccountValuePoint, error)
 }
 
 type DailyTotalAssetValueService struct {
@@ -20,6 +30,11 @@ type DailyTotalAssetValueService struct {
 	exchangeSvc    ExchangeRateServiceInterface
 	profileSvc     ProfileServiceInterface
 	userSvc        UserServiceInterface
+	snapshotJobSvc SnapshotJobServiceInterface
+
+	// caughtUp holds the local date each user's missed days were last checked on
+	caughtUpMu sync.Mutex
+	caughtUp   map[string]time.Time
 }
 
 func NewDailyTotalAssetValueService(
@@ -29,6 +44,7 @@ func NewDailyTotalAssetValueService(
 	exchangeSvc ExchangeRateServiceInterface,
 	profileSvc ProfileServiceInterface,
 	userSvc UserServiceInterface,
+	snapshotJobSvc SnapshotJobServiceInterface,
 ) *DailyTotalAssetValueService {
 	return &DailyTotalAssetValueService{
 		dailyAssetRepo: dailyAssetRepo,
@@ -37,19 +53,37 @@ func NewDailyTotalAssetValueService(
 		exchangeSvc:    exchangeSvc,
 		profileSvc:     profileSvc,
 		userSvc:        userSvc,
+		snapshotJobSvc: snapshotJobSvc,
+		caughtUp:       make(map[string]time.Time),
 	}
 }
 
+// RecordDailyTotalAssetValue records today's snapshot for every user whose local day is ending.
+// It is meant to be triggered hourly so that each timezone is recorded at its own end of day;
+// running it again within the hour overwrites the same date. Days missed since a user's latest
+// snapshot, e.g. while the trigger ran less often, are queued for a backfill from historical prices.
 func (s *DailyTotalAssetValueService) RecordDailyTotalAssetValue() error {
 	userIDs, err := s.userSvc.GetAllUserIDs()
 	if err != nil {
 		return err
 	}
 
-	today := time.Now().UTC()
+	now := time.Now()
 
 	for _, userID := range userIDs {
-		err := s.recordUserDailyTotalAssetValues(userID, today)
+		loc, err := s.profileSvc.GetLocation(userID)
+		if err != nil {
+			log.Printf("Failed to get timezone for user %s: %v", userID, err)
+			continue
+		}
+		if err := s.catchUpMissedDays(userID, LocalDate(now, loc)); err != nil {
+			log.Printf("Failed to queue missed daily assets for user %s: %v", userID, err)
+		}
+		if !isEndOfLocalDay(now, loc) {
+			continue
+		}
+
+		err = s.recordUserDailyTotalAssetValues(userID, LocalDate(now, loc))
 		if err != nil {
 			log.Printf("Failed to record daily assets for user %s: %v", userID, err)
 		}
@@ -58,92 +92,181 @@ func (s *DailyTotalAssetValueService) RecordDailyTotalAssetValue() error {
 	return nil
 }
 
+// GetUserDailyTotalAssetValues returns the snapshots between the dates. A zero endDate means the
+// user's local today and a zero startDate the defaultSnapshotRangeDays before endDate.
 func (s *DailyTotalAssetValueService) GetUserDailyTotalAssetValues(userID string, startDate, endDate time.Time) ([]models.UserDailyTotalAssetValue, error) {
+	if endDate.IsZero() {
+		loc, err := s.profileSvc.GetLocation(userID)
+		if err != nil {
+			return nil, err
+		}
+		endDate = LocalDate(time.Now(), loc)
+	}
+	if startDate.IsZero() {
+		startDate = endDate.AddDate(0, 0, -defaultSnapshotRangeDays)
+	}
 	return s.dailyAssetRepo.GetUserDailyTotalAssetValues(userID, startDate, endDate)
 }
 
+// catchUpMissedDays queues a backfill of the days between the user's latest snapshot and
+// yesterday. Users without any snapshot are left to an explicit backfill. It checks each user once
+// per local day, so the hourly trigger does not query or queue again until the next day.
+func (s *DailyTotalAssetValueService) catchUpMissedDays(userID string, today time.Time) error {
+	s.caughtUpMu.Lock()
+	checked, ok := s.caughtUp[userID]
+	s.caughtUpMu.Unlock()
+	if ok && checked.Equal(today) {
+		return nil
+	}
+
+	latest, err := s.dailyAssetRepo.GetLatestUserDailyTotalAssetValue(userID)
+	if err != nil {
+		return err
+	}
+
+	yesterday := today.AddDate(0, 0, -1)
+	if latest != nil && latest.Date.Before(yesterday) {
+		_, err = s.snapshotJobSvc.StartBackfill(userID, models.SnapshotBackfillRequest{
+			StartDate: latest.Date.AddDate(0, 0, 1).Format("2006-01-02"),
+			EndDate:   yesterday.Format("2006-01-02"),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	s.caughtUpMu.Lock()
+	s.caughtUp[userID] = today
+	s.caughtUpMu.Unlock()
+	return nil
+}
+
+// isEndOfLocalDay reports whether now falls in the last hour of the day in loc
+func isEndOfLocalDay(now time.Time, loc *time.Location) bool {
+	return now.In(loc).Hour() >= snapshotLocalHour
+}
+
 func (s *DailyTotalAssetValueService) recordUserDailyTotalAssetValues(userID string, date time.Time) error {
 	defaultCurrency, err := s.profileSvc.GetDefaultCurrency(userID)
 	if err != nil {
 		return err
 	}
 
-	holdings, err := s.holdingSvc.ListHoldings(userID)
+	rates, err := s.exchangeSvc.GetRatesByBaseCurrency(defaultCurrency)
 	if err != nil {
 		return err
 	}
-	holdingItems := make([]struct {
-		Amount   float64
-		Currency string
-	}, len(holdings))
-	for i, h := range holdings {
-		holdingItems[i] = struct {
-			Amount   float64
-			Currency string
-		}{
-			Amount:   h.Quantity * h.Price,
-			Currency: h.Currency,
-		}
+
+	holdings, err := s.holdingSvc.ListAccountHoldings(userID)
+	if err != nil {
+		return err
 	}
 
 	accounts, err := s.accountSvc.ListAccounts(userID)
 	if err != nil {
 		return err
 	}
-	accountItems := make([]struct {
-		Amount   float64
-		Currency string
-	}, len(accounts))
-	for i, a := range accounts {
-		accountItems[i] = struct {
-			Amount   float64
-			Currency string
-		}{
-			Amount:   a.Balance,
-			Currency: a.Currency,
-		}
+
+	record, components := buildSnapshot(userID, date, defaultCurrency, rates, holdings, accounts)
+	return s.dailyAssetRepo.SaveSnapshot(record, components)
+}
+
+// GetBreakdown returns the snapshot of a date with its holding and account components
+func (s *DailyTotalAssetValueService) GetBreakdown(userID string, date time.Time) (*models.DailyAssetBreakdown, error) {
+	record, err := s.dailyAssetRepo.GetUserDailyTotalAssetValue(userID, date)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, models.NewAppError(models.ErrCodeNotFound, "No snapshot recorded on this date")
 	}
 
-	allItems := append(holdingItems, accountItems...)
-	totalValue, err := s.calculateTotalValue(allItems, defaultCurrency)
+	components, err := s.dailyAssetRepo.GetComponents(userID, date)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	record := &models.UserDailyTotalAssetValue{
-		UserID:     userID,
-		Date:       date,
-		TotalValue: totalValue,
-		Currency:   defaultCurrency,
+	breakdown := &models.DailyAssetBreakdown{
+		Date:       record.Date,
+		Currency:   record.Currency,
+		TotalValue: record.TotalValue,
+		Holdings:   []models.UserDailyAssetComponent{},
+		Accounts:   []models.UserDailyAssetComponent{},
+	}
+	for _, component := range components {
+		if component.ComponentType == models.SnapshotComponentAccount {
+			breakdown.Accounts = append(breakdown.Accounts, component)
+		} else {
+			breakdown.Holdings = append(breakdown.Holdings, component)
+		}
 	}
+	return breakdown, nil
+}
 
-	return s.dailyAssetRepo.CreateOrUpdate(record)
+func (s *DailyTotalAssetValueService) GetTickerSeries(userID, assetType, ticker string, startDate, endDate time.Time) ([]models.TickerValuePoint, error) {
+	return s.dailyAssetRepo.GetTickerSeries(userID, assetType, ticker, startDate, endDate)
 }
 
-func (s *DailyTotalAssetValueService) calculateTotalValue(items []struct {
-	Amount   float64
-	Currency string
-}, targetCurrency string) (float64, error) {
-	totalValue := 0.0
+func (s *DailyTotalAssetValueService) GetAccountSeries(userID, accountID string, startDate, endDate time.Time) ([]models.AccountValuePoint, error) {
+	return s.dailyAssetRepo.GetAccountSeries(userID, accountID, startDate, endDate)
+}
 
-	rates, err := s.exchangeSvc.GetRatesByBaseCurrency(targetCurrency)
-	if err != nil {
-		return 0, err
+// buildSnapshot itemizes per-account holdings and account balances and sums the components that
+// could be converted into the default currency into the snapshot total
+func buildSnapshot(
+	userID string,
+	date time.Time,
+	defaultCurrency string,
+	rates map[string]float64,
+	holdings []models.Holding,
+	accounts []models.Account,
+) (*models.UserDailyTotalAssetValue, []models.UserDailyAssetComponent) {
+	record := &models.UserDailyTotalAssetValue{
+		UserID:   userID,
+		Date:     date,
+		Currency: defaultCurrency,
 	}
+	components := make([]models.UserDailyAssetComponent, 0, len(holdings)+len(accounts))
 
-	for _, item := range items {
-		if item.Currency == targetCurrency {
-			totalValue += item.Amount
-			continue
+	addComponent := func(component models.UserDailyAssetComponent) {
+		component.UserID = userID
+		component.Date = date
+		if unit, ok := convertWithRates(1, component.Currency, defaultCurrency, rates); ok {
+			fxRate := 1 / unit
+			value := component.Value * unit
+			component.FXRate = &fxRate
+			component.ValueInDefaultCurrency = &value
+			record.TotalValue += value
+		} else {
+			log.Printf("No exchange rate found for %s to %s", component.Currency, defaultCurrency)
 		}
+		components = append(components, component)
+	}
 
-		rate, ok := rates[item.Currency]
-		if !ok {
-			log.Printf("No exchange rate found for %s to %s", item.Currency, targetCurrency)
-			continue
-		}
-		totalValue += item.Amount / rate
+	for _, h := range holdings {
+		addComponent(models.UserDailyAssetComponent{
+			ComponentType: models.SnapshotComponentHolding,
+			AccountID:     h.AccountID,
+			AssetType:     h.AssetType,
+			Ticker:        h.Ticker,
+			Name:          h.TickerName,
+			Quantity:      h.Quantity,
+			Price:         h.Price,
+			Currency:      h.Currency,
+			Value:         h.Quantity * h.Price,
+		})
+	}
+	for _, a := range accounts {
+		addComponent(models.UserDailyAssetComponent{
+			ComponentType: models.SnapshotComponentAccount,
+			AccountID:     a.ID,
+			Name:          a.Name,
+			Quantity:      a.Balance,
+			Price:         1,
+			Currency:      a.Currency,
+			Value:         a.Balance,
+		})
 	}
 
-	return totalValue, nil
+	return record, components
 }
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"asset-diary/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUserDailyTotalAssetValueRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserDailyTotalAssetValueRepository) CreateOrUpdate(record *models.UserDailyTotalAssetValue) error {
+	panic("not implemented")
+}
+
+func (m *MockUserDailyTotalAssetValueRepository) GetUserDailyTotalAssetValues(userID string, startDate, endDate time.Time) ([]models.UserDailyTotalAssetValue, error) {
+	panic("not implemented")
+}
+
+func (m *MockUserDailyTotalAssetValueRepository) GetLatestUserDailyTotalAssetValue(userID string) (*models.UserDailyTotalAssetValue, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserDailyTotalAssetValue), args.Error(1)
+}
+
+func (m *MockUserDailyTotalAssetValueRepository) SaveSnapshot(record *models.UserDailyTotalAssetValue, components []models.UserDailyAssetComponent) error {
+	panic("not implemented")
+}
+
+func (m *MockUserDailyTotalAssetValueRepository) GetUserDailyTotalAssetValue(userID string, date time.Time) (*models.UserDailyTotalAssetValue, error) {
+	panic("not implemented")
+}
+
+func (m *MockUserDailyTotalAssetValueRepository) GetComponents(userID string, date time.Time) ([]models.UserDailyAssetComponent, error) {
+	panic("not implemented")
+}
+
+func (m *MockUserDailyTotalAssetValueRepository) GetTickerSeries(userID, assetType, ticker string, startDate, endDate time.Time) ([]models.TickerValuePoint, error) {
+	panic("not implemented")
+}
+
+func (m *MockUserDailyTotalAssetValueRepository) GetAccountSeries(userID, accountID string, startDate, endDate time.Time) ([]models.AccountValuePoint, error) {
+	panic("not implemented")
+}
+
+func TestBuildSnapshot(t *testing.T) {
+	rates := map[string]float64{"USD": 0.03125}
+	holdings := []models.Holding{
+		{AccountID: "acc-1", Ticker: "AAPL", TickerName: "Apple", AssetType: "stock", Currency: "USD", Quantity: 10, Price: 200},
+		{AccountID: "acc-2", Ticker: "7203.T", AssetType: "stock", Currency: "JPY", Quantity: 100, Price: 3000},
+	}
+	accounts := []models.Account{{ID: "acc-1", Name: "Broker", Currency: "TWD", Balance: 5000}}
+
+	record, components := buildSnapshot("user1", date(2026, 10, 1), "TWD", rates, holdings, accounts)
+
+	assert.Equal(t, "TWD", record.Currency)
+	assert.InDelta(t, 64000+5000, record.TotalValue, 1e-6)
+	assert.Len(t, components, 3)
+
+	apple := components[0]
+	assert.Equal(t, models.SnapshotComponentHolding, apple.ComponentType)
+	assert.Equal(t, "acc-1", apple.AccountID)
+	assert.Equal(t, "Apple", apple.Name)
+	assert.Equal(t, date(2026, 10, 1), apple.Date)
+	assert.InDelta(t, 2000, apple.Value, 1e-9)
+	assert.InDelta(t, 0.03125, *apple.FXRate, 1e-12)
+	assert.InDelta(t, 64000, *apple.ValueInDefaultCurrency, 1e-6)
+
+	assert.Nil(t, components[1].FXRate)
+	assert.Nil(t, components[1].ValueInDefaultCurrency)
+
+	cash := components[2]
+	assert.Equal(t, models.SnapshotComponentAccount, cash.ComponentType)
+	assert.Equal(t, "Broker", cash.Name)
+	assert.InDelta(t, 1, *cash.FXRate, 1e-12)
+	assert.InDelta(t, 5000, *cash.ValueInDefaultCurrency, 1e-9)
+}
+
+func TestLocalDate(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	assert.NoError(t, err)
+
+	// 02:30 UTC is still the previous evening in New York but already morning in Taipei
+	instant := time.Date(2026, 3, 10, 2, 30, 0, 0, time.UTC)
+	assert.Equal(t, date(2026, 3, 9), LocalDate(instant, newYork))
+	assert.Equal(t, date(2026, 3, 10), LocalDate(instant, taipei))
+}
+
+func TestIsEndOfLocalDay(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	assert.NoError(t, err)
+
+	// 15:30 UTC is 23:30 in Taipei and 11:30 in New York
+	instant := time.Date(2026, 3, 10, 15, 30, 0, 0, time.UTC)
+	assert.True(t, isEndOfLocalDay(instant, taipei))
+	assert.False(t, isEndOfLocalDay(instant, newYork))
+}
+
+func TestCatchUpMissedDays(t *testing.T) {
+	repo := new(MockUserDailyTotalAssetValueRepository)
+	jobs := new(MockSnapshotJobService)
+	service := NewDailyTotalAssetValueService(repo, nil, nil, nil, nil, nil, jobs)
+	today := date(2026, 10, 18)
+
+	t.Run("days missed since the latest snapshot are backfilled", func(t *testing.T) {
+		repo.On("GetLatestUserDailyTotalAssetValue", "user1").Return(&models.UserDailyTotalAssetValue{Date: date(2026, 10, 14)}, nil).Once()
+		jobs.On("StartBackfill", "user1", models.SnapshotBackfillRequest{StartDate: "2026-10-15", EndDate: "2026-10-17"}).
+			Return(&models.SnapshotJob{}, nil).Once()
+
+		assert.NoError(t, service.catchUpMissedDays("user1", today))
+	})
+
+	t.Run("later runs on the same local day do not check again", func(t *testing.T) {
+		assert.NoError(t, service.catchUpMissedDays("user1", today))
+		repo.AssertNumberOfCalls(t, "GetLatestUserDailyTotalAssetValue", 1)
+	})
+
+	t.Run("the next local day checks again", func(t *testing.T) {
+		repo.On("GetLatestUserDailyTotalAssetValue", "user1").Return(&models.UserDailyTotalAssetValue{Date: date(2026, 10, 18)}, nil).Once()
+
+		assert.NoError(t, service.catchUpMissedDays("user1", today.AddDate(0, 0, 1)))
+	})
+
+	t.Run("nothing is queued when yesterday was recorded", func(t *testing.T) {
+		repo.On("GetLatestUserDailyTotalAssetValue", "user2").Return(&models.UserDailyTotalAssetValue{Date: date(2026, 10, 17)}, nil).Once()
+
+		assert.NoError(t, service.catchUpMissedDays("user2", today))
+	})
+
+	t.Run("users without snapshots are left alone", func(t *testing.T) {
+		repo.On("GetLatestUserDailyTotalAssetValue", "user3").Return(nil, nil).Once()
+
+		assert.NoError(t, service.catchUpMissedDays("user3", today))
+	})
+
+	repo.AssertExpectations(t)
+	jobs.AssertExpectations(t)
+	jobs.AssertNumberOfCalls(t, "StartBackfill", 1)
+}
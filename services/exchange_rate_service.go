@@ -7,23 +7,41 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 )
 
 type ExchangeRateServiceInterface interface {
 	FetchAndStoreRates() error
 	GetRatesByBaseCurrency(baseCurrency string) (map[string]float64, error)
+	GetHistoricalRates(baseCurrency string, date time.Time) (map[string]float64, error)
+	GetRecordedRates(baseCurrency string, date time.Time) (map[string]float64, error)
+	GetRangeRates(baseCurrency string, startDate, endDate time.Time) (map[string]map[string]float64, error)
+	GetStoredRates(baseCurrency string, dates []time.Time) (map[string]map[string]float64, error)
 }
 
+// historicalRateMaxAge is how far back a stored rate may be used for a day without its own rates,
+// which covers weekends and holidays
+const historicalRateMaxAge = 7 * 24 * time.Hour
+
 type ExchangeRateService struct {
 	repo                repositories.ExchangeRateRepositoryInterface
+	historyRepo         repositories.ExchangeRateHistoryRepositoryInterface
 	supportedCurrencies []string
+	httpClient          *http.Client
 }
 
-func NewExchangeRateService(repo repositories.ExchangeRateRepositoryInterface, supportedCurrencies []string) *ExchangeRateService {
+func NewExchangeRateService(
+	repo repositories.ExchangeRateRepositoryInterface,
+	historyRepo repositories.ExchangeRateHistoryRepositoryInterface,
+	supportedCurrencies []string,
+) *ExchangeRateService {
 	return &ExchangeRateService{
 		repo:                repo,
+		historyRepo:         historyRepo,
 		supportedCurrencies: supportedCurrencies,
+		httpClient:          &http.Client{Timeout: 30 * time.Second},
 	}
 }
 
@@ -86,6 +104,7 @@ func (s *ExchangeRateService) FetchAndStoreRates() error {
 
 		lastUpdated := time.Unix(apiResponse.LastUpdated, 0)
 		successCount := 0
+		var history []models.ExchangeRateHistory
 
 		// TODO: remove this after USDT is added to the API
 		// add USDT to api Response same rate with USD but base on base currency
@@ -116,9 +135,19 @@ func (s *ExchangeRateService) FetchAndStoreRates() error {
 				continue
 			}
 
+			history = append(history, models.ExchangeRateHistory{
+				BaseCurrency:   baseCurrency,
+				TargetCurrency: currency,
+				Date:           lastUpdated.UTC(),
+				Rate:           rate,
+			})
 			successCount++
 		}
 
+		if err := s.historyRepo.UpsertRates(history); err != nil {
+			log.Printf("Failed to record exchange rate history for %s: %v", baseCurrency, err)
+		}
+
 		totalSuccessCount += successCount
 	}
 
@@ -133,3 +162,213 @@ func (s *ExchangeRateService) FetchAndStoreRates() error {
 
 	return nil
 }
+
+// GetHistoricalRates returns the rates of baseCurrency on date, using the current rates when
+// GetRecordedRates has none for the day.
+func (s *ExchangeRateService) GetHistoricalRates(baseCurrency string, date time.Time) (map[string]float64, error) {
+	rates, err := s.GetRecordedRates(baseCurrency, date)
+	if err != nil {
+		log.Printf("No historical exchange rates for %s on %s, using current rates: %v", baseCurrency, date.Format("2006-01-02"), err)
+		return s.GetRatesByBaseCurrency(baseCurrency)
+	}
+	return rates, nil
+}
+
+// GetRecordedRates returns the rates of baseCurrency on date. Rates missing from the history are
+// fetched from the currency-api archive and stored; it fails when neither has rates for the day.
+func (s *ExchangeRateService) GetRecordedRates(baseCurrency string, date time.Time) (map[string]float64, error) {
+	stored, err := s.historyRepo.GetRatesOnOrBefore(baseCurrency, date)
+	if err != nil {
+		return nil, err
+	}
+	if len(stored) > 0 && date.Sub(stored[0].Date) <= historicalRateMaxAge {
+		result := make(map[string]float64, len(stored))
+		for _, rate := range stored {
+			result[rate.TargetCurrency] = rate.Rate
+		}
+		return result, nil
+	}
+
+	history, err := s.fetchHistoricalRates(baseCurrency, date)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("no exchange rates for %s on %s", baseCurrency, date.Format("2006-01-02"))
+	}
+
+	if err := s.historyRepo.UpsertRates(history); err != nil {
+		log.Printf("Failed to record exchange rate history for %s: %v", baseCurrency, err)
+	}
+
+	result := make(map[string]float64, len(history))
+	for _, rate := range history {
+		result[rate.TargetCurrency] = rate.Rate
+	}
+	return result, nil
+}
+
+// GetRangeRates returns the rates of baseCurrency for each day of the range, keyed by
+// 2006-01-02. The stored history of the range is read at once and every day it lacks is fetched
+// from the currency-api archive once and stored. A day the archive has no rates for either uses
+// the latest stored day within historicalRateMaxAge, and is left out when there is none.
+func (s *ExchangeRateService) GetRangeRates(baseCurrency string, startDate, endDate time.Time) (map[string]map[string]float64, error) {
+	history, err := s.historyRepo.GetRatesBetween(baseCurrency, startDate.Add(-historicalRateMaxAge), endDate)
+	if err != nil {
+		return nil, err
+	}
+	recorded := newRecordedRates(history)
+
+	result := make(map[string]map[string]float64)
+	for day := startDate; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		if rates, ok := recorded.byDay[key]; ok {
+			result[key] = rates
+			continue
+		}
+
+		fetched, err := s.fetchHistoricalRates(baseCurrency, day)
+		if err == nil && len(fetched) > 0 {
+			if err := s.historyRepo.UpsertRates(fetched); err != nil {
+				log.Printf("Failed to record exchange rate history for %s: %v", baseCurrency, err)
+			}
+			recorded.add(fetched)
+		} else {
+			log.Printf("No historical exchange rates for %s on %s: %v", baseCurrency, key, err)
+		}
+
+		if rates, ok := recorded.on(day); ok {
+			result[key] = rates
+		}
+	}
+	return result, nil
+}
+
+// GetStoredRates returns the stored rates of baseCurrency on each of dates, keyed by 2006-01-02,
+// with a single query and without fetching anything. Dates without stored rates within
+// historicalRateMaxAge are left out.
+func (s *ExchangeRateService) GetStoredRates(baseCurrency string, dates []time.Time) (map[string]map[string]float64, error) {
+	result := make(map[string]map[string]float64)
+	if len(dates) == 0 {
+		return result, nil
+	}
+
+	startDate, endDate := dates[0], dates[0]
+	for _, date := range dates {
+		if date.Before(startDate) {
+			startDate = date
+		}
+		if date.After(endDate) {
+			endDate = date
+		}
+	}
+
+	history, err := s.historyRepo.GetRatesBetween(baseCurrency, startDate.Add(-historicalRateMaxAge), endDate)
+	if err != nil {
+		return nil, err
+	}
+	recorded := newRecordedRates(history)
+
+	for _, date := range dates {
+		if rates, ok := recorded.on(date); ok {
+			result[date.Format("2006-01-02")] = rates
+		}
+	}
+	return result, nil
+}
+
+// recordedRates indexes recorded rates by day
+type recordedRates struct {
+	days  []time.Time // sorted
+	byDay map[string]map[string]float64
+}
+
+func newRecordedRates(history []models.ExchangeRateHistory) *recordedRates {
+	recorded := &recordedRates{byDay: make(map[string]map[string]float64)}
+	recorded.add(history)
+	return recorded
+}
+
+func (r *recordedRates) add(history []models.ExchangeRateHistory) {
+	for _, rate := range history {
+		key := rate.Date.Format("2006-01-02")
+		if _, ok := r.byDay[key]; !ok {
+			r.byDay[key] = make(map[string]float64)
+			i := sort.Search(len(r.days), func(i int) bool { return !r.days[i].Before(rate.Date) })
+			r.days = append(r.days, time.Time{})
+			copy(r.days[i+1:], r.days[i:])
+			r.days[i] = rate.Date
+		}
+		r.byDay[key][rate.TargetCurrency] = rate.Rate
+	}
+}
+
+// on returns the rates of the latest recorded day not after date and within historicalRateMaxAge
+func (r *recordedRates) on(date time.Time) (map[string]float64, bool) {
+	i := sort.Search(len(r.days), func(i int) bool { return r.days[i].After(date) })
+	if i == 0 || date.Sub(r.days[i-1]) > historicalRateMaxAge {
+		return nil, false
+	}
+	return r.byDay[r.days[i-1].Format("2006-01-02")], true
+}
+
+// fetchHistoricalRates reads the daily archive of https://github.com/fawazahmed0/exchange-api
+func (s *ExchangeRateService) fetchHistoricalRates(baseCurrency string, date time.Time) ([]models.ExchangeRateHistory, error) {
+	base := strings.ToLower(baseCurrency)
+	url := fmt.Sprintf("https://cdn.jsdelivr.net/npm/@fawazahmed0/currency-api@%s/v1/currencies/%s.json", date.Format("2006-01-02"), base)
+
+	resp, err := s.httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch historical exchange rates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code for historical exchange rates: %d", resp.StatusCode)
+	}
+
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode historical exchange rates: %w", err)
+	}
+
+	var rates map[string]float64
+	if err := json.Unmarshal(body[base], &rates); err != nil {
+		return nil, fmt.Errorf("failed to decode historical exchange rates: %w", err)
+	}
+
+	history := make([]models.ExchangeRateHistory, 0, len(s.supportedCurrencies))
+	for _, currency := range s.supportedCurrencies {
+		rate, ok := rates[strings.ToLower(currency)]
+		if !ok && currency == "USDT" {
+			// same fallback as FetchAndStoreRates
+			rate, ok = rates["usd"]
+		}
+		if currency == baseCurrency {
+			rate, ok = 1, true
+		}
+		if !ok || rate <= 0 {
+			continue
+		}
+		history = append(history, models.ExchangeRateHistory{
+			BaseCurrency:   baseCurrency,
+			TargetCurrency: currency,
+			Date:           date,
+			Rate:           rate,
+		})
+	}
+	return history, nil
+}
+
+// convertWithRates converts an amount into the base currency of rates, which maps each target
+// currency to how many units of it one unit of the base currency buys
+func convertWithRates(amount float64, currency, baseCurrency string, rates map[string]float64) (float64, bool) {
+	if currency == baseCurrency {
+		return amount, true
+	}
+	rate, ok := rates[currency]
+	if !ok || rate <= 0 {
+		return 0, false
+	}
+	return amount / rate, true
+}
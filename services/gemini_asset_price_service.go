@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"regexp"
 	"strings"
 	"time"
 
@@ -23,25 +22,11 @@ func NewGeminiAssetPriceService(geminiService *GeminiChatService) *GeminiAssetPr
 	}
 }
 
-// marketCurrencies maps market codes to their expected currency codes
-var marketCurrencies = map[string]string{
-	"US": "USD",
-	"TW": "TWD",
-}
-
 // GetStockPrice gets the price of a stock using Gemini
 func (s *GeminiAssetPriceService) GetStockPrice(symbol string) (*models.TickerInfo, error) {
-	market := "US"
-	isTaiwanStock, _ := regexp.MatchString(`^\d`, symbol)
-	if isTaiwanStock {
-		market = "TW"
-	}
-	expectedCurrency, ok := marketCurrencies[market]
-	if !ok {
-		expectedCurrency = "USD" // default to USD for unknown markets
-	}
+	parsed := ParseStockSymbol(symbol)
 
-	prompt := fmt.Sprintf(`today is %s, I will provide a stock symbol (e.g., "AAPL", "BTC", "Tesla") and a market identifier (e.g., TW for Taiwan, US for United States). Please return the real-time price data in the following JSON format, using the provided symbol in the symbol field (or standardizing it, e.g., Tesla to TSLA).  Return ONLY the specified JSON and NO additional text or explanations:
+	prompt := fmt.Sprintf(`today is %s, I will provide a stock symbol (e.g., "AAPL", "BTC", "Tesla") and a market identifier (e.g., TW for Taiwan, US for United States, HK for Hong Kong, JP for Japan, UK for the United Kingdom). Please return the real-time price data in the following JSON format, using the provided symbol in the symbol field (or standardizing it, e.g., Tesla to TSLA).  Return ONLY the specified JSON and NO additional text or explanations:
 
 {
   "price": <current price, number format, rounded to two decimal places>,
@@ -62,7 +47,7 @@ Requirements:
 6. Ensure the JSON is valid, with no extra spaces or newlines.
 
 Please generate the JSON response based on the input stock code and market appended below:
-Market: %s, Input code: %s`, time.Now().Format(time.RFC3339), market, strings.ToUpper(symbol))
+Market: %s, Input code: %s`, time.Now().Format(time.RFC3339), parsed.Market, parsed.Symbol)
 
 	response, err := s.geminiService.GenerateContentWithJSON(prompt)
 	if err != nil {
@@ -74,7 +59,7 @@ Market: %s, Input code: %s`, time.Now().Format(time.RFC3339), market, strings.To
 		panic(err)
 	}
 
-	if tickerInfo.Currency != expectedCurrency {
+	if tickerInfo.Currency != parsed.Currency {
 		return nil, errors.New(InvalidSymbolError)
 	}
 
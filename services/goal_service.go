@@ -0,0 +1,400 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"asset-diary/models"
+	"asset-diary/repositories"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultProjectionSimulations = 1000
+	maxProjectionSimulations     = 10000
+	defaultProjectionYears       = 10
+	maxProjectionYears           = 60
+	defaultProjectionVolatility  = 15.0
+)
+
+var projectionPercentiles = []int{10, 25, 50, 75, 90}
+
+var leadingNumberPattern = regexp.MustCompile(`^\s*(\d+)`)
+
+// ProjectionOptions tunes a Monte Carlo projection; zero values fall back to defaults
+type ProjectionOptions struct {
+	Simulations          int
+	Seed                 *int64
+	VolatilityPercentage *float64
+	Years                int
+}
+
+type GoalServiceInterface interface {
+	ListGoals(userID string) ([]models.Goal, error)
+	CreateGoal(userID string, req models.GoalCreateRequest) (*models.Goal, error)
+	UpdateGoal(userID, goalID string, req models.GoalUpdateRequest) (*models.Goal, error)
+	DeleteGoal(userID, goalID string) (bool, error)
+	GetProjection(userID string, options ProjectionOptions) (*models.GoalProjection, error)
+}
+
+type GoalService struct {
+	repo          repositories.GoalRepositoryInterface
+	holdingSvc    HoldingServiceInterface
+	accountSvc    AccountServiceInterface
+	tradeSvc      TradeServiceInterface
+	dailyAssetSvc DailyTotalAssetValueServiceInterface
+	profileSvc    ProfileServiceInterface
+	exchangeSvc   ExchangeRateServiceInterface
+}
+
+func NewGoalService(
+	repo repositories.GoalRepositoryInterface,
+	holdingSvc HoldingServiceInterface,
+	accountSvc AccountServiceInterface,
+	tradeSvc TradeServiceInterface,
+	dailyAssetSvc DailyTotalAssetValueServiceInterface,
+	profileSvc ProfileServiceInterface,
+	exchangeSvc ExchangeRateServiceInterface,
+) *GoalService {
+	return &GoalService{
+		repo:          repo,
+		holdingSvc:    holdingSvc,
+		accountSvc:    accountSvc,
+		tradeSvc:      tradeSvc,
+		dailyAssetSvc: dailyAssetSvc,
+		profileSvc:    profileSvc,
+		exchangeSvc:   exchangeSvc,
+	}
+}
+
+func (s *GoalService) ListGoals(userID string) ([]models.Goal, error) {
+	return s.repo.ListGoals(userID)
+}
+
+func (s *GoalService) CreateGoal(userID string, req models.GoalCreateRequest) (*models.Goal, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, models.NewAppError(models.ErrCodeInvalidRequest, "Goal name is required")
+	}
+	targetDate, _ := time.Parse("2006-01-02", req.TargetDate)
+
+	return s.repo.CreateGoal(userID, models.Goal{
+		Name:         name,
+		TargetAmount: req.TargetAmount,
+		Currency:     strings.ToUpper(req.Currency),
+		TargetDate:   targetDate,
+	})
+}
+
+func (s *GoalService) UpdateGoal(userID, goalID string, req models.GoalUpdateRequest) (*models.Goal, error) {
+	goal, err := s.repo.GetGoal(userID, goalID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.NewAppError(models.ErrCodeNotFound, "Goal not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, models.NewAppError(models.ErrCodeInvalidRequest, "Goal name is required")
+		}
+		goal.Name = name
+	}
+	if req.TargetAmount != nil {
+		goal.TargetAmount = *req.TargetAmount
+	}
+	if req.Currency != nil && *req.Currency != "" {
+		goal.Currency = strings.ToUpper(*req.Currency)
+	}
+	if req.TargetDate != nil {
+		goal.TargetDate, _ = time.Parse("2006-01-02", *req.TargetDate)
+	}
+
+	if err := s.repo.SaveGoal(goal); err != nil {
+		return nil, err
+	}
+	return goal, nil
+}
+
+func (s *GoalService) DeleteGoal(userID, goalID string) (bool, error) {
+	return s.repo.DeleteGoal(userID, goalID)
+}
+
+// GetProjection simulates monthly portfolio values from the current net worth, adding the profile's
+// monthly cash flow each month and drawing returns around the profile's expected annual return.
+// Volatility defaults to the realized volatility of the last year of snapshots.
+func (s *GoalService) GetProjection(userID string, options ProjectionOptions) (*models.GoalProjection, error) {
+	profile, err := s.profileSvc.GetProfile(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user profile: %w", err)
+	}
+
+	defaultCurrency, err := s.profileSvc.GetDefaultCurrency(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user profile: %w", err)
+	}
+
+	rates, err := s.exchangeSvc.GetRatesByBaseCurrency(defaultCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rates: %w", err)
+	}
+
+	goals, err := s.repo.ListGoals(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	startValue, err := s.currentNetWorth(userID, defaultCurrency, rates)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := s.profileSvc.GetLocation(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := LocalDate(time.Now(), loc)
+	params := simulationParams{
+		StartDate:   today,
+		StartValue:  startValue,
+		Simulations: options.Simulations,
+		Months:      options.Years * 12,
+	}
+	if params.Simulations <= 0 {
+		params.Simulations = defaultProjectionSimulations
+	}
+	params.Simulations = min(params.Simulations, maxProjectionSimulations)
+
+	if options.Seed != nil {
+		params.Seed = *options.Seed
+	} else {
+		params.Seed = time.Now().UnixNano()
+	}
+
+	timeHorizon := ""
+	if investmentProfile := profile.InvestmentProfile; investmentProfile != nil {
+		params.MonthlyContribution = investmentProfile.MonthlyCashFlow
+		params.ExpectedReturn = float64(investmentProfile.ExpectedAnnualizedRateOfReturn) / 100
+		timeHorizon = investmentProfile.TimeHorizon
+	}
+
+	if options.VolatilityPercentage != nil {
+		params.Volatility = *options.VolatilityPercentage / 100
+	} else {
+		params.Volatility = s.realizedVolatility(userID, today, defaultCurrency)
+	}
+
+	if params.Months <= 0 {
+		params.Months = projectionMonths(today, goals, timeHorizon)
+	}
+	// a shorter requested horizon is extended so each goal is evaluated on its own target date
+	params.Months = max(params.Months, latestGoalMonths(today, goals))
+
+	targets := make([]*float64, len(goals))
+	for i, goal := range goals {
+		if amount, ok := convertWithRates(goal.TargetAmount, goal.Currency, defaultCurrency, rates); ok {
+			targets[i] = &amount
+		} else {
+			log.Printf("No exchange rate found for %s to %s", goal.Currency, defaultCurrency)
+		}
+	}
+
+	projection := simulateProjection(params, goals, targets)
+	projection.Currency = defaultCurrency
+	return projection, nil
+}
+
+func (s *GoalService) currentNetWorth(userID, defaultCurrency string, rates map[string]float64) (float64, error) {
+	holdings, err := s.holdingSvc.ListHoldings(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	accounts, err := s.accountSvc.ListAccounts(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0.0
+	for _, holding := range holdings {
+		if value, ok := convertWithRates(holding.TotalValue, holding.Currency, defaultCurrency, rates); ok {
+			total += value
+		}
+	}
+	for _, account := range accounts {
+		if value, ok := convertWithRates(account.Balance, account.Currency, defaultCurrency, rates); ok {
+			total += value
+		}
+	}
+	return total, nil
+}
+
+// realizedVolatility returns the annualized volatility of the last year of snapshots as a fraction,
+// or the default when there is not enough history
+func (s *GoalService) realizedVolatility(userID string, today time.Time, defaultCurrency string) float64 {
+	startDate := today.AddDate(-1, 0, 0)
+	snapshots, err := s.dailyAssetSvc.GetUserDailyTotalAssetValues(userID, startDate, today)
+	if err != nil {
+		log.Printf("Failed to get snapshots for volatility: %v", err)
+		return defaultProjectionVolatility / 100
+	}
+	flows, err := loadPortfolioCashFlows(s.accountSvc, s.tradeSvc, s.exchangeSvc, userID, defaultCurrency, startDate, today)
+	if err != nil {
+		log.Printf("Failed to get cash flows for volatility: %v", err)
+		return defaultProjectionVolatility / 100
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Date.Before(snapshots[j].Date)
+	})
+	values := make([]cashFlow, len(snapshots))
+	for i, snapshot := range snapshots {
+		values[i] = cashFlow{Date: snapshot.Date, Amount: snapshot.TotalValue}
+	}
+
+	metrics := riskMetrics(values, flows, 0)
+	if metrics.AnnualizedVolatilityPercentage == nil || *metrics.AnnualizedVolatilityPercentage <= 0 {
+		return defaultProjectionVolatility / 100
+	}
+	return *metrics.AnnualizedVolatilityPercentage / 100
+}
+
+// projectionMonths covers the latest goal, or else the profile's time horizon when it starts with
+// a number of years (e.g. "10" or "10 years")
+func projectionMonths(today time.Time, goals []models.Goal, timeHorizon string) int {
+	if months := latestGoalMonths(today, goals); months > 0 {
+		return months
+	}
+
+	years := defaultProjectionYears
+	if match := leadingNumberPattern.FindStringSubmatch(timeHorizon); match != nil {
+		if parsed, err := strconv.Atoi(match[1]); err == nil && parsed > 0 {
+			years = min(parsed, maxProjectionYears)
+		}
+	}
+	return years * 12
+}
+
+// latestGoalMonths returns the months until the latest goal, up to maxProjectionYears
+func latestGoalMonths(today time.Time, goals []models.Goal) int {
+	months := 0
+	for _, goal := range goals {
+		months = max(months, monthsBetween(today, goal.TargetDate))
+	}
+	return min(months, maxProjectionYears*12)
+}
+
+// monthsBetween counts whole months from start to end, rounding a partial month up
+func monthsBetween(start, end time.Time) int {
+	months := (end.Year()-start.Year())*12 + int(end.Month()-start.Month())
+	if end.Day() > start.Day() {
+		months++
+	}
+	return max(months, 0)
+}
+
+type simulationParams struct {
+	StartDate           time.Time
+	StartValue          float64
+	MonthlyContribution float64
+	ExpectedReturn      float64 // annual, as a fraction
+	Volatility          float64 // annual, as a fraction
+	Months              int
+	Simulations         int
+	Seed                int64
+}
+
+// simulateProjection draws log-normal monthly returns whose compounded mean matches the expected
+// annual return. Contributions are added at the end of every month. targets holds each goal's
+// amount in the default currency, or nil when it could not be converted. Goals dated after the
+// last simulated month are flagged as beyond the horizon instead of being evaluated early.
+func simulateProjection(params simulationParams, goals []models.Goal, targets []*float64) *models.GoalProjection {
+	projection := &models.GoalProjection{
+		StartDate:                params.StartDate,
+		StartValue:               params.StartValue,
+		MonthlyContribution:      params.MonthlyContribution,
+		ExpectedReturnPercentage: params.ExpectedReturn * 100,
+		VolatilityPercentage:     params.Volatility * 100,
+		Months:                   params.Months,
+		Simulations:              params.Simulations,
+		Seed:                     params.Seed,
+		Goals:                    make([]models.GoalProbability, len(goals)),
+		Percentiles:              make([]models.ProjectionPercentile, len(projectionPercentiles)),
+	}
+
+	goalMonths := make(map[int][]int)
+	for i, goal := range goals {
+		projection.Goals[i] = models.GoalProbability{Goal: goal, TargetAmountInDefaultCurrency: targets[i]}
+		month := monthsBetween(params.StartDate, goal.TargetDate)
+		if month > params.Months {
+			projection.Goals[i].BeyondHorizon = true
+			continue
+		}
+		goalMonths[month] = append(goalMonths[month], i)
+	}
+	for i, p := range projectionPercentiles {
+		projection.Percentiles[i] = models.ProjectionPercentile{Percentile: p, Points: make([]models.SeriesPoint, 0, params.Months+1)}
+	}
+
+	drift := (math.Log(1+params.ExpectedReturn) - params.Volatility*params.Volatility/2) / 12
+	shock := params.Volatility / math.Sqrt(12)
+	rng := rand.New(rand.NewSource(params.Seed))
+
+	values := make([]float64, params.Simulations)
+	for i := range values {
+		values[i] = params.StartValue
+	}
+	sorted := make([]float64, len(values))
+
+	for month := 0; month <= params.Months; month++ {
+		if month > 0 {
+			for i := range values {
+				values[i] = values[i]*math.Exp(drift+shock*rng.NormFloat64()) + params.MonthlyContribution
+			}
+		}
+
+		copy(sorted, values)
+		sort.Float64s(sorted)
+		date := params.StartDate.AddDate(0, month, 0)
+		for i, p := range projectionPercentiles {
+			projection.Percentiles[i].Points = append(projection.Percentiles[i].Points, models.SeriesPoint{
+				Date:  date,
+				Value: percentileOf(sorted, p),
+			})
+		}
+
+		for _, goalIndex := range goalMonths[month] {
+			goal := &projection.Goals[goalIndex]
+			median := percentileOf(sorted, 50)
+			goal.MedianValueAtTargetDate = &median
+			if goal.TargetAmountInDefaultCurrency == nil {
+				continue
+			}
+			reached := len(sorted) - sort.SearchFloat64s(sorted, *goal.TargetAmountInDefaultCurrency)
+			goal.ProbabilityPercentage = percentage(float64(reached) / float64(len(sorted)))
+		}
+	}
+
+	return projection
+}
+
+// percentileOf returns the p-th percentile of sorted values using the nearest-rank method
+func percentileOf(sorted []float64, p int) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(float64(p)/100*float64(len(sorted)))) - 1
+	return sorted[max(0, min(rank, len(sorted)-1))]
+}
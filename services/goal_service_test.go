@@ -0,0 +1,93 @@
+package services
+
+import (
+	"testing"
+
+	"asset-diary/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulateProjection(t *testing.T) {
+	params := simulationParams{
+		StartDate:           date(2026, 1, 1),
+		StartValue:          100000,
+		MonthlyContribution: 1000,
+		ExpectedReturn:      0.06,
+		Volatility:          0.15,
+		Months:              120,
+		Simulations:         2000,
+		Seed:                42,
+	}
+	easy, hard := 50000.0, 10000000.0
+	goals := []models.Goal{
+		{Name: "easy", TargetDate: date(2031, 1, 1)},
+		{Name: "hard", TargetDate: date(2036, 1, 1)},
+		{Name: "no rate", TargetDate: date(2036, 1, 1)},
+	}
+
+	t.Run("reproducible with a seed", func(t *testing.T) {
+		first := simulateProjection(params, goals, []*float64{&easy, &hard, nil})
+		second := simulateProjection(params, goals, []*float64{&easy, &hard, nil})
+
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("goal probabilities and percentiles", func(t *testing.T) {
+		projection := simulateProjection(params, goals, []*float64{&easy, &hard, nil})
+
+		assert.Greater(t, *projection.Goals[0].ProbabilityPercentage, 99.0)
+		assert.Less(t, *projection.Goals[1].ProbabilityPercentage, 1.0)
+		assert.Nil(t, projection.Goals[2].ProbabilityPercentage)
+		assert.Greater(t, *projection.Goals[2].MedianValueAtTargetDate, 0.0)
+		assert.False(t, projection.Goals[1].BeyondHorizon)
+
+		assert.Len(t, projection.Percentiles, 5)
+		for _, percentile := range projection.Percentiles {
+			assert.Len(t, percentile.Points, 121)
+			assert.Equal(t, 100000.0, percentile.Points[0].Value)
+			assert.Equal(t, date(2036, 1, 1), percentile.Points[120].Date)
+		}
+		final := func(i int) float64 { return projection.Percentiles[i].Points[120].Value }
+		assert.Less(t, final(0), final(2))
+		assert.Less(t, final(2), final(4))
+	})
+
+	t.Run("goals after the horizon are not evaluated early", func(t *testing.T) {
+		short := params
+		short.Months = 60
+
+		projection := simulateProjection(short, goals, []*float64{&easy, &hard, nil})
+
+		assert.NotNil(t, projection.Goals[0].ProbabilityPercentage)
+		assert.False(t, projection.Goals[0].BeyondHorizon)
+		assert.True(t, projection.Goals[1].BeyondHorizon)
+		assert.Nil(t, projection.Goals[1].ProbabilityPercentage)
+		assert.Nil(t, projection.Goals[1].MedianValueAtTargetDate)
+	})
+
+	t.Run("without volatility the path is deterministic", func(t *testing.T) {
+		flat := params
+		flat.Volatility = 0
+		flat.MonthlyContribution = 0
+		flat.Months = 12
+
+		projection := simulateProjection(flat, nil, nil)
+
+		for _, percentile := range projection.Percentiles {
+			assert.InDelta(t, 106000, percentile.Points[12].Value, 1e-6)
+		}
+	})
+}
+
+func TestProjectionMonths(t *testing.T) {
+	today := date(2026, 1, 15)
+
+	assert.Equal(t, 30, projectionMonths(today, []models.Goal{
+		{TargetDate: date(2027, 1, 1)},
+		{TargetDate: date(2028, 7, 10)},
+	}, ""))
+	assert.Equal(t, 240, projectionMonths(today, nil, "20 years"))
+	assert.Equal(t, maxProjectionYears*12, projectionMonths(today, []models.Goal{{TargetDate: date(2100, 1, 1)}}, ""))
+	assert.Equal(t, defaultProjectionYears*12, projectionMonths(today, nil, "long term"))
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"asset-diary/models"
+	"asset-diary/repositories"
+	"asset-diary/services/interfaces"
+)
+
+// priceHistoryEdgeTolerance allows stored history to start or end a few days off the requested
+// range, since markets are closed on weekends and holidays
+const priceHistoryEdgeTolerance = 7 * 24 * time.Hour
+
+// closingPriceLookbackDays is how far back the daily recording re-reads prices, filling in closes
+// that were published late or missed by an earlier run
+const closingPriceLookbackDays = 7
+
+type HistoricalPriceServiceInterface interface {
+	GetPriceHistory(assetType, symbol string, startDate, endDate time.Time) ([]models.PriceHistory, error)
+	RecordClosingPrices() error
+}
+
+type HistoricalPriceService struct {
+	repo      repositories.PriceHistoryRepositoryInterface
+	tradeRepo repositories.TradeRepositoryInterface
+	provider  interfaces.HistoricalPriceProviderInterface
+}
+
+func NewHistoricalPriceService(
+	repo repositories.PriceHistoryRepositoryInterface,
+	tradeRepo repositories.TradeRepositoryInterface,
+	provider interfaces.HistoricalPriceProviderInterface,
+) *HistoricalPriceService {
+	return &HistoricalPriceService{
+		repo:      repo,
+		tradeRepo: tradeRepo,
+		provider:  provider,
+	}
+}
+
+// GetPriceHistory returns stored daily prices, fetching and storing the range from the upstream
+// provider when the stored history does not cover it. Upstream failures are logged and whatever
+// is stored is returned.
+func (s *HistoricalPriceService) GetPriceHistory(assetType, symbol string, startDate, endDate time.Time) ([]models.PriceHistory, error) {
+	stored, err := s.repo.GetPrices(assetType, symbol, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	if coversRange(stored, startDate, endDate) {
+		return stored, nil
+	}
+
+	fetched, err := s.fetch(assetType, symbol, startDate, endDate)
+	if err != nil {
+		log.Printf("Failed to fetch price history for %s %s: %v", assetType, symbol, err)
+		return stored, nil
+	}
+	if len(fetched) == 0 {
+		return stored, nil
+	}
+
+	if err := s.repo.UpsertPrices(fetched); err != nil {
+		return nil, err
+	}
+	return s.repo.GetPrices(assetType, symbol, startDate, endDate)
+}
+
+// RecordClosingPrices stores the recent daily prices of every symbol held by any user and of the
+// benchmark catalog. Symbols that fail are reported together after trying all of them.
+func (s *HistoricalPriceService) RecordClosingPrices() error {
+	symbols, err := s.tradeRepo.ListHeldSymbols()
+	if err != nil {
+		return err
+	}
+	symbols = withBenchmarkSymbols(symbols)
+
+	// the job is not tied to a user; every market closes on or before the current UTC date, so
+	// the UTC date covers the latest session of each of them
+	endDate := LocalDate(time.Now(), time.UTC)
+	startDate := endDate.AddDate(0, 0, -closingPriceLookbackDays)
+	var failed []string
+	fetchedTaiwan := false
+
+	for _, symbol := range symbols {
+		if marketOf(symbol.AssetType, symbol.Symbol) == MarketTW {
+			if fetchedTaiwan {
+				time.Sleep(twseRequestInterval)
+			}
+			fetchedTaiwan = true
+		}
+
+		prices, err := s.fetch(symbol.AssetType, symbol.Symbol, startDate, endDate)
+		if err != nil {
+			log.Printf("Failed to fetch closing prices for %s %s: %v", symbol.AssetType, symbol.Symbol, err)
+			failed = append(failed, symbol.Symbol)
+			continue
+		}
+		if err := s.repo.UpsertPrices(prices); err != nil {
+			failed = append(failed, symbol.Symbol)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to record closing prices for %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func (s *HistoricalPriceService) fetch(assetType, symbol string, startDate, endDate time.Time) ([]models.PriceHistory, error) {
+	switch assetType {
+	case "stock":
+		return s.provider.GetStockPriceHistory(symbol, startDate, endDate)
+	case "crypto":
+		return s.provider.GetCryptoPriceHistory(symbol, startDate, endDate)
+	}
+	return nil, fmt.Errorf("unsupported asset type: %s", assetType)
+}
+
+// withBenchmarkSymbols adds the catalog benchmarks that are not already in symbols
+func withBenchmarkSymbols(symbols []models.PriceSymbol) []models.PriceSymbol {
+	seen := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		seen[priceHistoryKey(symbol.AssetType, symbol.Symbol)] = true
+	}
+	for _, benchmark := range benchmarkCatalog {
+		if !seen[priceHistoryKey(benchmark.AssetType, benchmark.Symbol)] {
+			symbols = append(symbols, models.PriceSymbol{AssetType: benchmark.AssetType, Symbol: benchmark.Symbol})
+		}
+	}
+	return symbols
+}
+
+func coversRange(prices []models.PriceHistory, startDate, endDate time.Time) bool {
+	if len(prices) == 0 {
+		return false
+	}
+	return prices[0].Date.Sub(startDate) <= priceHistoryEdgeTolerance &&
+		endDate.Sub(prices[len(prices)-1].Date) <= priceHistoryEdgeTolerance
+}
+
+// closeOnOrBefore returns the latest close not after date from prices sorted by date
+func closeOnOrBefore(prices []models.PriceHistory, date time.Time) (float64, bool) {
+	found := false
+	closePrice := 0.0
+	for _, price := range prices {
+		if price.Date.After(date) {
+			break
+		}
+		closePrice = price.Close
+		found = true
+	}
+	return closePrice, found
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"asset-diary/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPriceHistoryRepository is a mock implementation of PriceHistoryRepositoryInterface
+type MockPriceHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockPriceHistoryRepository) UpsertPrices(prices []models.PriceHistory) error {
+	args := m.Called(prices)
+	return args.Error(0)
+}
+
+func (m *MockPriceHistoryRepository) GetPrices(assetType, symbol string, startDate, endDate time.Time) ([]models.PriceHistory, error) {
+	args := m.Called(assetType, symbol, startDate, endDate)
+	return args.Get(0).([]models.PriceHistory), args.Error(1)
+}
+
+// MockHistoricalPriceProvider is a mock implementation of HistoricalPriceProviderInterface
+type MockHistoricalPriceProvider struct {
+	mock.Mock
+}
+
+func (m *MockHistoricalPriceProvider) GetStockPriceHistory(symbol string, startDate, endDate time.Time) ([]models.PriceHistory, error) {
+	args := m.Called(symbol, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PriceHistory), args.Error(1)
+}
+
+func (m *MockHistoricalPriceProvider) GetCryptoPriceHistory(symbol string, startDate, endDate time.Time) ([]models.PriceHistory, error) {
+	args := m.Called(symbol, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PriceHistory), args.Error(1)
+}
+
+func TestRecordClosingPrices(t *testing.T) {
+	repo := new(MockPriceHistoryRepository)
+	tradeRepo := new(MockTradeRepository)
+	provider := new(MockHistoricalPriceProvider)
+	service := NewHistoricalPriceService(repo, tradeRepo, provider)
+
+	tradeRepo.On("ListHeldSymbols").Return([]models.PriceSymbol{
+		{AssetType: "stock", Symbol: "AAPL"},
+		{AssetType: "crypto", Symbol: "BTC"},
+	}, nil)
+
+	closeOn := func(assetType, symbol string) []models.PriceHistory {
+		return []models.PriceHistory{{AssetType: assetType, Symbol: symbol, Date: date(2026, 10, 16), Close: 100}}
+	}
+	provider.On("GetStockPriceHistory", "AAPL", mock.Anything, mock.Anything).Return(closeOn("stock", "AAPL"), nil)
+	provider.On("GetCryptoPriceHistory", "BTC", mock.Anything, mock.Anything).Return(closeOn("crypto", "BTC"), nil)
+	provider.On("GetStockPriceHistory", "0050", mock.Anything, mock.Anything).Return(closeOn("stock", "0050"), nil)
+	provider.On("GetStockPriceHistory", "SPY", mock.Anything, mock.Anything).Return(nil, errors.New("rate limited"))
+	repo.On("UpsertPrices", mock.Anything).Return(nil)
+
+	err := service.RecordClosingPrices()
+
+	assert.EqualError(t, err, "failed to record closing prices for SPY")
+	repo.AssertNumberOfCalls(t, "UpsertPrices", 3)
+	repo.AssertCalled(t, "UpsertPrices", closeOn("stock", "0050"))
+
+	// the range ends today and looks back a week
+	call := provider.Calls[0]
+	startDate, endDate := call.Arguments.Get(1).(time.Time), call.Arguments.Get(2).(time.Time)
+	assert.Equal(t, closingPriceLookbackDays, int(endDate.Sub(startDate).Hours()/24))
+	assert.Equal(t, time.Now().UTC().Truncate(24*time.Hour), endDate)
+}
@@ -7,10 +7,13 @@ import (
 	"log"
 	"sort"
 	"sync"
+	"time"
 )
 
 type HoldingServiceInterface interface {
 	ListHoldings(userID string) ([]models.Holding, error)
+	ListHoldingsByTag(userID, tag string) ([]models.Holding, error)
+	ListAccountHoldings(userID string) ([]models.Holding, error)
 }
 
 type HoldingService struct {
@@ -46,6 +49,36 @@ func NewHoldingService(
 }
 
 func (s *HoldingService) ListHoldings(userID string) ([]models.Holding, error) {
+	trades, err := s.tradeService.ListTrades(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildHoldings(userID, trades, false)
+}
+
+// ListHoldingsByTag computes holdings from only the trades labelled with the given tag, so each
+// strategy can be measured on its own. Sells must carry the tag too to reduce the strategy's position.
+func (s *HoldingService) ListHoldingsByTag(userID, tag string) ([]models.Holding, error) {
+	trades, err := s.tradeService.ListTradesByTag(userID, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildHoldings(userID, trades, false)
+}
+
+// ListAccountHoldings is like ListHoldings but keeps positions of the same ticker in different accounts apart
+func (s *HoldingService) ListAccountHoldings(userID string) ([]models.Holding, error) {
+	trades, err := s.tradeService.ListTrades(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildHoldings(userID, trades, true)
+}
+
+func (s *HoldingService) buildHoldings(userID string, trades []models.Trade, perAccount bool) ([]models.Holding, error) {
 	defaultCurrency, err := s.profileService.GetDefaultCurrency(userID)
 	if err != nil {
 		log.Printf("Error getting user profile: %v", err)
@@ -58,26 +91,25 @@ func (s *HoldingService) ListHoldings(userID string) ([]models.Holding, error) {
 		return nil, fmt.Errorf("failed to get exchange rates: %w", err)
 	}
 
-	trades, err := s.tradeService.ListTrades(userID)
-	if err != nil {
-		return nil, err
-	}
-
 	tradesMap := make(map[string][]models.Trade)
 	for _, trade := range trades {
 		key := fmt.Sprintf("%s_%s_%s", trade.AssetType, trade.Ticker, trade.Currency)
+		if perAccount {
+			key = trade.AccountID + "_" + key
+		}
 		tradesMap[key] = append(tradesMap[key], trade)
 	}
 
 	holdings := make(map[string]*models.Holding)
-	for _, trades := range tradesMap {
-		key := fmt.Sprintf("%s_%s_%s", trades[0].AssetType, trades[0].Ticker, trades[0].Currency)
-
+	for key, trades := range tradesMap {
 		holding, err := calculateHolding(trades)
 		if err != nil {
 			log.Printf("Error calculating holding for trade %s: %v", trades[0].ID, err)
 			continue
 		}
+		if perAccount {
+			holding.AccountID = trades[0].AccountID
+		}
 
 		if holding.Quantity > 0 {
 			holdings[key] = holding
@@ -119,6 +151,11 @@ func (s *HoldingService) ListHoldings(userID string) ([]models.Holding, error) {
 
 	wg.Wait()
 
+	historicalRates := s.lotRates(assets, defaultCurrency)
+	for i := range assets {
+		applyDefaultCurrencyGain(&assets[i], defaultCurrency, rates, historicalRates)
+	}
+
 	return assets, nil
 }
 
@@ -139,8 +176,9 @@ func calculateHolding(trades []models.Trade) (*models.Holding, error) {
 	})
 
 	type fifoBuyTrade struct {
-		Quantity float64
-		Price    float64
+		TradeDate time.Time
+		Quantity  float64
+		Price     float64
 	}
 
 	var buyQueue []fifoBuyTrade
@@ -149,8 +187,9 @@ func calculateHolding(trades []models.Trade) (*models.Holding, error) {
 		switch trade.Type {
 		case "buy":
 			buyQueue = append(buyQueue, fifoBuyTrade{
-				Quantity: trade.Quantity,
-				Price:    trade.Price,
+				TradeDate: trade.TradeDate,
+				Quantity:  trade.Quantity,
+				Price:     trade.Price,
 			})
 			holding.TotalCost += trade.Quantity * trade.Price
 			holding.Quantity += trade.Quantity
@@ -169,6 +208,14 @@ func calculateHolding(trades []models.Trade) (*models.Holding, error) {
 
 			for sellQuantity > 0 && len(buyQueue) > 0 {
 				oldestBuy := &buyQueue[0]
+				matched := min(oldestBuy.Quantity, sellQuantity)
+				holding.RealizedLots = append(holding.RealizedLots, models.RealizedLot{
+					AcquisitionDate: oldestBuy.TradeDate,
+					DisposalDate:    trade.TradeDate,
+					Quantity:        matched,
+					CostPrice:       oldestBuy.Price,
+					SalePrice:       trade.Price,
+				})
 				if oldestBuy.Quantity <= sellQuantity {
 					holding.TotalCost -= oldestBuy.Quantity * oldestBuy.Price
 					sellQuantity -= oldestBuy.Quantity
@@ -193,5 +240,85 @@ func calculateHolding(trades []models.Trade) (*models.Holding, error) {
 		}
 	}
 
+	if holding.Quantity > 0 {
+		for _, lot := range buyQueue {
+			holding.OpenLots = append(holding.OpenLots, models.HoldingLot{
+				TradeDate: lot.TradeDate,
+				Quantity:  lot.Quantity,
+				Price:     lot.Price,
+			})
+		}
+	}
+
 	return holding, nil
 }
+
+// lotRates reads the stored exchange rates on the trade dates of the open lots in a foreign
+// currency with one query. Listing holdings never fetches rates; the backfill and the exchange
+// rate cron fill the history.
+func (s *HoldingService) lotRates(holdings []models.Holding, defaultCurrency string) map[string]map[string]float64 {
+	seen := make(map[string]bool)
+	var dates []time.Time
+	for _, h := range holdings {
+		if h.Currency == defaultCurrency {
+			continue
+		}
+		for _, lot := range h.OpenLots {
+			day := lot.TradeDate.Format("2006-01-02")
+			if !seen[day] {
+				seen[day] = true
+				dates = append(dates, lot.TradeDate)
+			}
+		}
+	}
+	if len(dates) == 0 {
+		return make(map[string]map[string]float64)
+	}
+
+	historicalRates, err := s.exchangeService.GetStoredRates(defaultCurrency, dates)
+	if err != nil {
+		log.Printf("Error getting stored exchange rates, using today's: %v", err)
+		return make(map[string]map[string]float64)
+	}
+	return historicalRates
+}
+
+// applyDefaultCurrencyGain fills the default currency cost and gain of h from its open lots.
+// historicalRates holds the rates by date across the holdings of one listing; a date without
+// stored rates is converted at today's rates, which are cached for the rest of the listing.
+func applyDefaultCurrencyGain(h *models.Holding, defaultCurrency string, rates map[string]float64, historicalRates map[string]map[string]float64) {
+	rate, ok := rates[h.Currency]
+	if !ok || rate <= 0 {
+		return
+	}
+
+	costInDefault := h.TotalCost / rate
+	if h.Currency != defaultCurrency {
+		costInDefault = 0
+		for _, lot := range h.OpenLots {
+			day := lot.TradeDate.Format("2006-01-02")
+			lotRates, cached := historicalRates[day]
+			if !cached {
+				lotRates = rates
+				historicalRates[day] = lotRates
+			}
+
+			lotCost, ok := convertWithRates(lot.Quantity*lot.Price, h.Currency, defaultCurrency, lotRates)
+			if !ok {
+				lotCost = lot.Quantity * lot.Price / rate
+			}
+			costInDefault += lotCost
+		}
+	}
+
+	splitCurrencyGain(h, rate, costInDefault)
+}
+
+// splitCurrencyGain sets the default currency gain of h given today's rate of its currency and
+// the cost basis converted at historical rates
+func splitCurrencyGain(h *models.Holding, rate, costInDefault float64) {
+	h.TotalCostInDefaultCurrency = costInDefault
+	h.GainLossInDefaultCurrency = h.TotalValue/rate - costInDefault
+	h.PriceEffectInDefaultCurrency = h.GainLoss / rate
+	h.CurrencyEffectInDefaultCurrency = h.TotalCost/rate - costInDefault
+}
@@ -21,6 +21,11 @@ func (m *MockTradeService) ListTrades(userID string) ([]models.Trade, error) {
 	return args.Get(0).([]models.Trade), args.Error(1)
 }
 
+func (m *MockTradeService) ListTradesByTag(userID, tag string) ([]models.Trade, error) {
+	args := m.Called(userID, tag)
+	return args.Get(0).([]models.Trade), args.Error(1)
+}
+
 // Add stub methods to satisfy TradeServiceInterface
 func (m *MockTradeService) CreateTrade(userID string, trade models.Trade) (*models.Trade, error) {
 	args := m.Called(userID, trade)
@@ -43,6 +48,11 @@ func (m *MockTradeService) IsAccountOwnedByUser(accountID, userID string) (bool,
 }
 
 func (m *MockTradeService) IsTradeOwnedByUser(tradeID, userID string) (bool, error) {
+	args := m.Called(tradeID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTradeService) AreTagsOwnedByUser(tagIDs []string, userID string) (bool, error) {
 	panic("not implemented")
 }
 
@@ -79,6 +89,14 @@ func (m *MockProfileService) GetDefaultCurrency(userID string) (string, error) {
 	return args.Get(0).(string), args.Error(1)
 }
 
+func (m *MockProfileService) GetLocation(userID string) (*time.Location, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*time.Location), args.Error(1)
+}
+
 type MockExchangeRateService struct {
 	mock.Mock
 }
@@ -96,6 +114,38 @@ func (m *MockExchangeRateService) FetchAndStoreRates() error {
 	return args.Error(0)
 }
 
+func (m *MockExchangeRateService) GetHistoricalRates(baseCurrency string, date time.Time) (map[string]float64, error) {
+	args := m.Called(baseCurrency, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]float64), args.Error(1)
+}
+
+func (m *MockExchangeRateService) GetRecordedRates(baseCurrency string, date time.Time) (map[string]float64, error) {
+	args := m.Called(baseCurrency, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]float64), args.Error(1)
+}
+
+func (m *MockExchangeRateService) GetRangeRates(baseCurrency string, startDate, endDate time.Time) (map[string]map[string]float64, error) {
+	args := m.Called(baseCurrency, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]map[string]float64), args.Error(1)
+}
+
+func (m *MockExchangeRateService) GetStoredRates(baseCurrency string, dates []time.Time) (map[string]map[string]float64, error) {
+	args := m.Called(baseCurrency, dates)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]map[string]float64), args.Error(1)
+}
+
 type MockPriceService struct {
 	mock.Mock
 }
@@ -432,6 +482,7 @@ func TestListHoldings(t *testing.T) {
 				"TWD": 30.0,
 				"USD": 1.0,
 			}, nil)
+			mockExchangeService.On("GetStoredRates", "USD", mock.Anything).Return(map[string]map[string]float64{}, nil).Maybe()
 
 			service := NewHoldingService(mockTradeService, priceService, mockProfileService, mockExchangeService)
 
@@ -476,3 +527,74 @@ func TestListHoldings(t *testing.T) {
 		})
 	}
 }
+
+func TestListHoldingsByTag(t *testing.T) {
+	mockTradeService := new(MockTradeService)
+	mockTradeService.On("ListTradesByTag", "user1", "dividend").Return([]models.Trade{
+		{Type: "buy", AssetType: "stock", Ticker: "0056", Quantity: 1000, Price: 30, Currency: "TWD"},
+		{Type: "buy", AssetType: "stock", Ticker: "0056", Quantity: 1000, Price: 36, Currency: "TWD"},
+	}, nil)
+
+	priceService := new(MockPriceService)
+	priceService.On("GetStockPrice", "0056").Return(&models.TickerInfo{Price: 35.0}, nil)
+
+	mockProfileService := new(MockProfileService)
+	mockProfileService.On("GetDefaultCurrency", "user1").Return("TWD", nil)
+
+	mockExchangeService := new(MockExchangeRateService)
+	mockExchangeService.On("GetRatesByBaseCurrency", "TWD").Return(map[string]float64{"TWD": 1.0}, nil)
+
+	service := NewHoldingService(mockTradeService, priceService, mockProfileService, mockExchangeService)
+
+	holdings, err := service.ListHoldingsByTag("user1", "dividend")
+
+	assert.NoError(t, err)
+	assert.Len(t, holdings, 1)
+	assert.Equal(t, 2000.0, holdings[0].Quantity)
+	assert.Equal(t, 33.0, holdings[0].AverageCost)
+	assert.Equal(t, 70000.0, holdings[0].TotalValueInDefaultCurrency)
+	mockTradeService.AssertNotCalled(t, "ListTrades", "user1")
+	mockTradeService.AssertExpectations(t)
+}
+
+func TestListHoldingsSplitsCurrencyGain(t *testing.T) {
+	firstBuy := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	secondBuy := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+
+	mockTradeService := new(MockTradeService)
+	mockTradeService.On("ListTrades", "user1").Return([]models.Trade{
+		{Type: "buy", AssetType: "stock", Ticker: "AAPL", Quantity: 10, Price: 100, Currency: "USD", TradeDate: firstBuy},
+		{Type: "buy", AssetType: "stock", Ticker: "AAPL", Quantity: 10, Price: 120, Currency: "USD", TradeDate: secondBuy},
+		{Type: "sell", AssetType: "stock", Ticker: "AAPL", Quantity: 5, Price: 130, Currency: "USD", TradeDate: secondBuy.AddDate(0, 1, 0)},
+	}, nil)
+
+	priceService := new(MockPriceService)
+	priceService.On("GetStockPrice", "AAPL").Return(&models.TickerInfo{Price: 150.0}, nil)
+
+	mockProfileService := new(MockProfileService)
+	mockProfileService.On("GetDefaultCurrency", "user1").Return("TWD", nil)
+
+	mockExchangeService := new(MockExchangeRateService)
+	mockExchangeService.On("GetRatesByBaseCurrency", "TWD").Return(map[string]float64{"TWD": 1.0, "USD": 1.0 / 31}, nil)
+	// nothing is stored for the second buy, so it is converted at today's rate
+	mockExchangeService.On("GetStoredRates", "TWD", mock.MatchedBy(func(dates []time.Time) bool {
+		return len(dates) == 2
+	})).Return(map[string]map[string]float64{
+		"2025-01-02": {"TWD": 1.0, "USD": 1.0 / 30},
+	}, nil).Once()
+
+	service := NewHoldingService(mockTradeService, priceService, mockProfileService, mockExchangeService)
+
+	holdings, err := service.ListHoldings("user1")
+
+	assert.NoError(t, err)
+	assert.Len(t, holdings, 1)
+	h := holdings[0]
+	// open lots: 5 bought at 30 TWD/USD and 10 bought at today's 31 TWD/USD
+	assert.InDelta(t, 5*100*30+10*120*31, h.TotalCostInDefaultCurrency, 1e-6)
+	assert.InDelta(t, 15*150*31-52200, h.GainLossInDefaultCurrency, 1e-6)
+	assert.InDelta(t, (2250-1700)*31, h.PriceEffectInDefaultCurrency, 1e-6)
+	assert.InDelta(t, 1700*31-52200, h.CurrencyEffectInDefaultCurrency, 1e-6)
+	assert.InDelta(t, h.GainLossInDefaultCurrency, h.PriceEffectInDefaultCurrency+h.CurrencyEffectInDefaultCurrency, 1e-6)
+	mockExchangeService.AssertExpectations(t)
+}
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"asset-diary/models"
+)
+
+const interestIncomeKey = "interest"
+
+type IncomeServiceInterface interface {
+	GetIncome(userID string, startDate, endDate time.Time) (*models.IncomeReport, error)
+}
+
+type IncomeService struct {
+	accountSvc  AccountServiceInterface
+	holdingSvc  HoldingServiceInterface
+	tradeSvc    TradeServiceInterface
+	profileSvc  ProfileServiceInterface
+	exchangeSvc ExchangeRateServiceInterface
+}
+
+func NewIncomeService(
+	accountSvc AccountServiceInterface,
+	holdingSvc HoldingServiceInterface,
+	tradeSvc TradeServiceInterface,
+	profileSvc ProfileServiceInterface,
+	exchangeSvc ExchangeRateServiceInterface,
+) *IncomeService {
+	return &IncomeService{
+		accountSvc:  accountSvc,
+		holdingSvc:  holdingSvc,
+		tradeSvc:    tradeSvc,
+		profileSvc:  profileSvc,
+		exchangeSvc: exchangeSvc,
+	}
+}
+
+// GetIncome aggregates the income received between the dates and projects the dividends of the
+// twelve months following the user's local today. A zero endDate means the user's local today and
+// a zero startDate the year before endDate.
+func (s *IncomeService) GetIncome(userID string, startDate, endDate time.Time) (*models.IncomeReport, error) {
+	defaultCurrency, err := s.profileSvc.GetDefaultCurrency(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user profile: %w", err)
+	}
+
+	loc, err := s.profileSvc.GetLocation(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user timezone: %w", err)
+	}
+	today := LocalDate(time.Now(), loc)
+	if endDate.IsZero() {
+		endDate = today
+	}
+	if startDate.IsZero() {
+		startDate = endDate.AddDate(-1, 0, 0)
+	}
+
+	rates, err := s.exchangeSvc.GetRatesByBaseCurrency(defaultCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rates: %w", err)
+	}
+
+	accounts, err := s.accountSvc.ListAccounts(userID)
+	if err != nil {
+		return nil, err
+	}
+	accountNames := make(map[string]string, len(accounts))
+	for _, account := range accounts {
+		accountNames[account.ID] = account.Name
+	}
+
+	trailingStart := today.AddDate(-1, 0, 0)
+	txns, err := s.accountSvc.ListUserTransactions(userID, earliest(startDate, trailingStart), latest(endDate, today))
+	if err != nil {
+		return nil, err
+	}
+
+	received := []models.AccountTransaction{}
+	trailingDividends := []models.AccountTransaction{}
+	for _, txn := range txns {
+		if !txn.IsIncome() {
+			continue
+		}
+		if !txn.TransactionDate.Before(startDate) && !txn.TransactionDate.After(endDate) {
+			received = append(received, txn)
+		}
+		if txn.Type == models.AccountTransactionTypeDividend && txn.TransactionDate.After(trailingStart) && !txn.TransactionDate.After(today) {
+			trailingDividends = append(trailingDividends, txn)
+		}
+	}
+
+	// income is valued at the rate of its payment date, like the cost of the holdings
+	historicalRates := make(map[string]map[string]float64)
+	paymentRates := func(date time.Time) map[string]float64 {
+		day := date.Format("2006-01-02")
+		if cached, ok := historicalRates[day]; ok {
+			return cached
+		}
+		dayRates, err := s.exchangeSvc.GetHistoricalRates(defaultCurrency, date)
+		if err != nil {
+			log.Printf("Error getting exchange rates on %s, using today's: %v", day, err)
+			dayRates = rates
+		}
+		historicalRates[day] = dayRates
+		return dayRates
+	}
+
+	report := buildIncomeReport(defaultCurrency, received, accountNames, paymentRates)
+	report.StartDate = startDate
+	report.EndDate = endDate
+
+	if len(trailingDividends) > 0 {
+		holdings, err := s.holdingSvc.ListHoldings(userID)
+		if err != nil {
+			return nil, err
+		}
+		trades, err := s.tradeSvc.ListTrades(userID)
+		if err != nil {
+			return nil, err
+		}
+		report.Projection = projectDividends(defaultCurrency, rates, holdings, trades, trailingDividends)
+	}
+
+	return report, nil
+}
+
+// incomeGroup sums payments per key, remembering display names
+type incomeGroup struct {
+	names    map[string]string
+	amounts  map[string]float64
+	payments map[string]int
+}
+
+func newIncomeGroup() *incomeGroup {
+	return &incomeGroup{names: map[string]string{}, amounts: map[string]float64{}, payments: map[string]int{}}
+}
+
+func (g *incomeGroup) add(key, name string, amount float64) {
+	if _, ok := g.names[key]; !ok {
+		g.names[key] = name
+	}
+	g.amounts[key] += amount
+	g.payments[key]++
+}
+
+// buckets returns the groups ordered by key when byKey is set, and by amount, largest first, otherwise
+func (g *incomeGroup) buckets(byKey bool) []models.IncomeBucket {
+	buckets := make([]models.IncomeBucket, 0, len(g.amounts))
+	for key, amount := range g.amounts {
+		buckets = append(buckets, models.IncomeBucket{Key: key, Name: g.names[key], Amount: amount, Payments: g.payments[key]})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if !byKey && buckets[i].Amount != buckets[j].Amount {
+			return buckets[i].Amount > buckets[j].Amount
+		}
+		return buckets[i].Key < buckets[j].Key
+	})
+	return buckets
+}
+
+func buildIncomeReport(
+	defaultCurrency string,
+	received []models.AccountTransaction,
+	accountNames map[string]string,
+	ratesOn func(time.Time) map[string]float64,
+) *models.IncomeReport {
+	byMonth := newIncomeGroup()
+	byTicker := newIncomeGroup()
+	byAccount := newIncomeGroup()
+	unconverted := map[string]bool{}
+	total := 0.0
+
+	for _, txn := range received {
+		amount, ok := convertWithRates(txn.Amount, txn.Currency, defaultCurrency, ratesOn(txn.TransactionDate))
+		if !ok {
+			log.Printf("No exchange rate found for %s to %s", txn.Currency, defaultCurrency)
+			unconverted[txn.Currency] = true
+			continue
+		}
+		total += amount
+
+		month := txn.TransactionDate.Format("2006-01")
+		byMonth.add(month, month, amount)
+		if txn.Ticker != nil {
+			byTicker.add(*txn.Ticker, *txn.Ticker, amount)
+		} else {
+			byTicker.add(interestIncomeKey, "Interest", amount)
+		}
+		byAccount.add(txn.AccountID, accountNames[txn.AccountID], amount)
+	}
+
+	unconvertedCurrencies := make([]string, 0, len(unconverted))
+	for currency := range unconverted {
+		unconvertedCurrencies = append(unconvertedCurrencies, currency)
+	}
+	sort.Strings(unconvertedCurrencies)
+
+	return &models.IncomeReport{
+		Currency:              defaultCurrency,
+		TotalReceived:         total,
+		ByMonth:               byMonth.buckets(true),
+		ByTicker:              byTicker.buckets(false),
+		ByAccount:             byAccount.buckets(false),
+		Projection:            models.IncomeProjection{ByMonth: []models.IncomeBucket{}, Holdings: []models.HoldingIncomeProjection{}},
+		UnconvertedCurrencies: unconvertedCurrencies,
+	}
+}
+
+// projectDividends repeats each dividend of the trailing year one year later. A payment becomes an
+// amount per share using the shares the paid accounts held on its date, and is projected on the
+// current quantity of the holding at today's rates.
+func projectDividends(
+	defaultCurrency string,
+	rates map[string]float64,
+	holdings []models.Holding,
+	trades []models.Trade,
+	dividends []models.AccountTransaction,
+) models.IncomeProjection {
+	type payout struct {
+		date   time.Time
+		amount float64
+		shares float64
+	}
+
+	// payouts of the same security on the same date are combined across accounts
+	payouts := make(map[string]map[string]*payout)
+	for _, txn := range dividends {
+		if txn.Ticker == nil || txn.AssetType == nil {
+			continue
+		}
+		amount, ok := convertWithRates(txn.Amount, txn.Currency, defaultCurrency, rates)
+		if !ok {
+			continue
+		}
+		shares := sharesHeld(trades, txn.AccountID, *txn.AssetType, *txn.Ticker, txn.TransactionDate)
+		if shares <= 0 {
+			continue
+		}
+
+		key := priceHistoryKey(*txn.AssetType, strings.ToUpper(*txn.Ticker))
+		day := txn.TransactionDate.Format("2006-01-02")
+		if payouts[key] == nil {
+			payouts[key] = make(map[string]*payout)
+		}
+		if payouts[key][day] == nil {
+			payouts[key][day] = &payout{date: txn.TransactionDate}
+		}
+		payouts[key][day].amount += amount
+		payouts[key][day].shares += shares
+	}
+
+	projection := models.IncomeProjection{ByMonth: []models.IncomeBucket{}, Holdings: []models.HoldingIncomeProjection{}}
+	byMonth := newIncomeGroup()
+	for _, holding := range holdings {
+		holdingPayouts := payouts[priceHistoryKey(holding.AssetType, strings.ToUpper(holding.Ticker))]
+		if len(holdingPayouts) == 0 {
+			continue
+		}
+
+		annual := 0.0
+		for _, p := range holdingPayouts {
+			amount := p.amount / p.shares * holding.Quantity
+			annual += amount
+			month := p.date.AddDate(1, 0, 0).Format("2006-01")
+			byMonth.add(month, month, amount)
+		}
+		projection.Total += annual
+
+		result := models.HoldingIncomeProjection{
+			AssetType:                              holding.AssetType,
+			Ticker:                                 holding.Ticker,
+			TickerName:                             holding.TickerName,
+			Quantity:                               holding.Quantity,
+			PaymentsPerYear:                        len(holdingPayouts),
+			ProjectedAnnualIncomeInDefaultCurrency: annual,
+		}
+		if holding.TotalCostInDefaultCurrency > 0 {
+			result.YieldOnCostPercentage = percentage(annual / holding.TotalCostInDefaultCurrency)
+		}
+		if holding.TotalValueInDefaultCurrency > 0 {
+			result.CurrentYieldPercentage = percentage(annual / holding.TotalValueInDefaultCurrency)
+		}
+		projection.Holdings = append(projection.Holdings, result)
+	}
+
+	sort.Slice(projection.Holdings, func(i, j int) bool {
+		return projection.Holdings[i].ProjectedAnnualIncomeInDefaultCurrency > projection.Holdings[j].ProjectedAnnualIncomeInDefaultCurrency
+	})
+	projection.ByMonth = byMonth.buckets(true)
+	return projection
+}
+
+// sharesHeld is the quantity of a security the account held at the end of date
+func sharesHeld(trades []models.Trade, accountID, assetType, ticker string, date time.Time) float64 {
+	shares := 0.0
+	for _, trade := range trades {
+		if trade.AccountID != accountID || trade.AssetType != assetType || !strings.EqualFold(trade.Ticker, ticker) {
+			continue
+		}
+		if trade.TradeDate.After(date) {
+			continue
+		}
+		switch trade.Type {
+		case "buy":
+			shares += trade.Quantity
+		case "sell":
+			shares -= trade.Quantity
+		}
+	}
+	return shares
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
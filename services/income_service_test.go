@@ -0,0 +1,90 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"asset-diary/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func dividend(accountID, ticker string, day time.Time, amount float64, currency string) models.AccountTransaction {
+	assetType := "stock"
+	return models.AccountTransaction{
+		AccountID:       accountID,
+		Type:            models.AccountTransactionTypeDividend,
+		Amount:          amount,
+		Currency:        currency,
+		TransactionDate: day,
+		AssetType:       &assetType,
+		Ticker:          &ticker,
+	}
+}
+
+func TestBuildIncomeReport(t *testing.T) {
+	received := []models.AccountTransaction{
+		dividend("acc-1", "0056", date(2026, 1, 15), 2000, "TWD"),
+		dividend("acc-2", "AAPL", date(2026, 2, 13), 10, "USD"),
+		{AccountID: "acc-1", Type: models.AccountTransactionTypeInterest, Amount: 50, Currency: "TWD", TransactionDate: date(2026, 2, 20)},
+		dividend("acc-3", "7203", date(2026, 2, 25), 100, "JPY"),
+	}
+	ratesOn := func(day time.Time) map[string]float64 {
+		return map[string]float64{"USD": 1.0 / 32}
+	}
+
+	report := buildIncomeReport("TWD", received, map[string]string{"acc-1": "Broker", "acc-2": "US Broker"}, ratesOn)
+
+	assert.InDelta(t, 2000+320+50, report.TotalReceived, 1e-9)
+	assert.Equal(t, []string{"JPY"}, report.UnconvertedCurrencies)
+
+	assert.Len(t, report.ByMonth, 2)
+	assert.Equal(t, "2026-01", report.ByMonth[0].Key)
+	assert.InDelta(t, 370, report.ByMonth[1].Amount, 1e-9)
+	assert.Equal(t, 2, report.ByMonth[1].Payments)
+
+	assert.Equal(t, "0056", report.ByTicker[0].Key)
+	assert.Equal(t, "AAPL", report.ByTicker[1].Key)
+	assert.Equal(t, interestIncomeKey, report.ByTicker[2].Key)
+
+	assert.Equal(t, "Broker", report.ByAccount[0].Name)
+	assert.InDelta(t, 2050, report.ByAccount[0].Amount, 1e-9)
+}
+
+func TestProjectDividends(t *testing.T) {
+	trades := []models.Trade{
+		{AccountID: "acc-1", Type: "buy", AssetType: "stock", Ticker: "0056", Quantity: 1000, TradeDate: date(2025, 1, 2)},
+		{AccountID: "acc-2", Type: "buy", AssetType: "stock", Ticker: "0056", Quantity: 1000, TradeDate: date(2025, 1, 2)},
+		{AccountID: "acc-1", Type: "buy", AssetType: "stock", Ticker: "0056", Quantity: 2000, TradeDate: date(2025, 8, 1)},
+	}
+	dividends := []models.AccountTransaction{
+		// 1 TWD per share paid into both accounts, then 1.5 TWD per share on 4000 shares
+		dividend("acc-1", "0056", date(2025, 7, 15), 1000, "TWD"),
+		dividend("acc-2", "0056", date(2025, 7, 15), 1000, "TWD"),
+		dividend("acc-1", "0056", date(2025, 10, 15), 4500, "TWD"),
+		dividend("acc-2", "0056", date(2025, 10, 15), 1500, "TWD"),
+		dividend("acc-1", "SOLD", date(2025, 9, 1), 300, "TWD"),
+	}
+	holdings := []models.Holding{{
+		AssetType:                   "stock",
+		Ticker:                      "0056",
+		Quantity:                    5000,
+		TotalCostInDefaultCurrency:  175000,
+		TotalValueInDefaultCurrency: 200000,
+	}}
+
+	projection := projectDividends("TWD", map[string]float64{"TWD": 1}, holdings, trades, dividends)
+
+	assert.Len(t, projection.Holdings, 1)
+	h := projection.Holdings[0]
+	assert.Equal(t, 2, h.PaymentsPerYear)
+	assert.InDelta(t, 5000*1+5000*1.5, h.ProjectedAnnualIncomeInDefaultCurrency, 1e-9)
+	assert.InDelta(t, 12500.0/175000*100, *h.YieldOnCostPercentage, 1e-9)
+	assert.InDelta(t, 12500.0/200000*100, *h.CurrentYieldPercentage, 1e-9)
+	assert.InDelta(t, 12500, projection.Total, 1e-9)
+
+	assert.Len(t, projection.ByMonth, 2)
+	assert.Equal(t, "2026-07", projection.ByMonth[0].Key)
+	assert.InDelta(t, 5000, projection.ByMonth[0].Amount, 1e-9)
+	assert.Equal(t, "2026-10", projection.ByMonth[1].Key)
+}
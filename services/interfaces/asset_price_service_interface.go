@@ -6,3 +6,10 @@ type AssetPriceServiceInterface interface {
 	GetStockPrice(symbol string) (*models.TickerInfo, error)
 	GetCryptoPrice(symbol string) (*models.TickerInfo, error)
 }
+
+// BatchAssetPriceServiceInterface quotes several symbols with as few upstream requests as
+// possible. Results follow the order of the requests, one per request.
+type BatchAssetPriceServiceInterface interface {
+	AssetPriceServiceInterface
+	GetPrices(requests []models.PriceQuoteRequest) []models.PriceQuoteResult
+}
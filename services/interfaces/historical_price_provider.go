@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"time"
+
+	"asset-diary/models"
+)
+
+// HistoricalPriceProviderInterface fetches daily prices of a symbol from an upstream provider
+type HistoricalPriceProviderInterface interface {
+	GetStockPriceHistory(symbol string, startDate, endDate time.Time) ([]models.PriceHistory, error)
+	GetCryptoPriceHistory(symbol string, startDate, endDate time.Time) ([]models.PriceHistory, error)
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"asset-diary/models"
+	"asset-diary/repositories"
+
+	"gorm.io/gorm"
+)
+
+type JournalServiceInterface interface {
+	ListEntries(userID string, filter models.JournalEntryFilter) ([]models.JournalEntryResponse, error)
+	GetEntry(userID, entryID string) (*models.JournalEntryResponse, error)
+	CreateEntry(userID string, req models.JournalEntryCreateRequest) (*models.JournalEntryResponse, error)
+	UpdateEntry(userID, entryID string, req models.JournalEntryUpdateRequest) (*models.JournalEntryResponse, error)
+	DeleteEntry(userID, entryID string) error
+}
+
+type JournalService struct {
+	repo              repositories.JournalRepositoryInterface
+	tradeService      TradeServiceInterface
+	dailyAssetService DailyTotalAssetValueServiceInterface
+}
+
+// NewJournalService creates a new JournalService instance
+func NewJournalService(
+	repo repositories.JournalRepositoryInterface,
+	tradeService TradeServiceInterface,
+	dailyAssetService DailyTotalAssetValueServiceInterface,
+) *JournalService {
+	return &JournalService{
+		repo:              repo,
+		tradeService:      tradeService,
+		dailyAssetService: dailyAssetService,
+	}
+}
+
+// ListEntries returns the user's journal entries, each with the portfolio value recorded on its entry date
+func (s *JournalService) ListEntries(userID string, filter models.JournalEntryFilter) ([]models.JournalEntryResponse, error) {
+	// tags are stored normalized, so the filter has to be as well to match
+	filter.Tag = normalizeJournalTag(filter.Tag)
+
+	entries, err := s.repo.ListEntries(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := s.portfolioValuesFor(userID, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.JournalEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		responses = append(responses, toJournalEntryResponse(entry, values))
+	}
+	return responses, nil
+}
+
+func (s *JournalService) GetEntry(userID, entryID string) (*models.JournalEntryResponse, error) {
+	entry, err := s.repo.GetEntry(userID, entryID)
+	if err != nil {
+		return nil, journalNotFoundOr(err)
+	}
+	return s.withPortfolioValue(userID, entry)
+}
+
+func (s *JournalService) CreateEntry(userID string, req models.JournalEntryCreateRequest) (*models.JournalEntryResponse, error) {
+	entry := models.JournalEntry{
+		Title: req.Title,
+		Body:  req.Body,
+		Tags:  normalizeJournalTags(req.Tags),
+	}
+	if req.EntryDate != "" {
+		entryDate, err := time.Parse("2006-01-02", req.EntryDate)
+		if err != nil {
+			return nil, models.NewAppError(models.ErrCodeInvalidRequest, "Invalid entryDate format, use YYYY-MM-DD")
+		}
+		entry.EntryDate = &entryDate
+	}
+	if req.AssetType != "" {
+		entry.AssetType = &req.AssetType
+	}
+	if ticker := strings.ToUpper(strings.TrimSpace(req.Ticker)); ticker != "" {
+		entry.Ticker = &ticker
+	}
+
+	tradeIDs, err := s.validateTradeIDs(userID, req.TradeIDs)
+	if err != nil {
+		return nil, err
+	}
+	entry.TradeIDs = tradeIDs
+
+	created, err := s.repo.CreateEntry(userID, entry)
+	if err != nil {
+		return nil, err
+	}
+	return s.withPortfolioValue(userID, created)
+}
+
+func (s *JournalService) UpdateEntry(userID, entryID string, req models.JournalEntryUpdateRequest) (*models.JournalEntryResponse, error) {
+	if req.EntryDate != nil && *req.EntryDate != "" {
+		if _, err := time.Parse("2006-01-02", *req.EntryDate); err != nil {
+			return nil, models.NewAppError(models.ErrCodeInvalidRequest, "Invalid entryDate format, use YYYY-MM-DD")
+		}
+	}
+	if req.Tags != nil {
+		tags := normalizeJournalTags(*req.Tags)
+		req.Tags = &tags
+	}
+	if req.Ticker != nil {
+		ticker := strings.ToUpper(strings.TrimSpace(*req.Ticker))
+		req.Ticker = &ticker
+	}
+	if req.TradeIDs != nil {
+		tradeIDs, err := s.validateTradeIDs(userID, *req.TradeIDs)
+		if err != nil {
+			return nil, err
+		}
+		req.TradeIDs = &tradeIDs
+	}
+
+	updated, err := s.repo.UpdateEntry(userID, entryID, req)
+	if err != nil {
+		return nil, journalNotFoundOr(err)
+	}
+	return s.withPortfolioValue(userID, updated)
+}
+
+func (s *JournalService) DeleteEntry(userID, entryID string) error {
+	deleted, err := s.repo.DeleteEntry(userID, entryID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return models.NewAppError(models.ErrCodeNotFound, "Journal entry not found")
+	}
+	return nil
+}
+
+// validateTradeIDs removes duplicates and makes sure every linked trade belongs to the user
+func (s *JournalService) validateTradeIDs(userID string, tradeIDs []string) ([]string, error) {
+	seen := make(map[string]bool, len(tradeIDs))
+	result := make([]string, 0, len(tradeIDs))
+	for _, tradeID := range tradeIDs {
+		if tradeID == "" || seen[tradeID] {
+			continue
+		}
+		seen[tradeID] = true
+
+		owned, err := s.tradeService.IsTradeOwnedByUser(tradeID, userID)
+		if err != nil || !owned {
+			return nil, models.NewAppError(models.ErrCodeInvalidRequest, "Invalid or unauthorized trade id: "+tradeID)
+		}
+		result = append(result, tradeID)
+	}
+	return result, nil
+}
+
+func (s *JournalService) withPortfolioValue(userID string, entry *models.JournalEntry) (*models.JournalEntryResponse, error) {
+	values, err := s.portfolioValuesFor(userID, []models.JournalEntry{*entry})
+	if err != nil {
+		return nil, err
+	}
+	response := toJournalEntryResponse(*entry, values)
+	return &response, nil
+}
+
+// portfolioValuesFor loads the daily snapshots covering all entry dates with a single range query
+func (s *JournalService) portfolioValuesFor(userID string, entries []models.JournalEntry) (map[string]models.UserDailyTotalAssetValue, error) {
+	values := make(map[string]models.UserDailyTotalAssetValue)
+
+	var minDate, maxDate *time.Time
+	for _, entry := range entries {
+		if entry.EntryDate == nil {
+			continue
+		}
+		if minDate == nil || entry.EntryDate.Before(*minDate) {
+			minDate = entry.EntryDate
+		}
+		if maxDate == nil || entry.EntryDate.After(*maxDate) {
+			maxDate = entry.EntryDate
+		}
+	}
+	if minDate == nil {
+		return values, nil
+	}
+
+	snapshots, err := s.dailyAssetService.GetUserDailyTotalAssetValues(userID, *minDate, *maxDate)
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshot := range snapshots {
+		values[snapshot.Date.Format("2006-01-02")] = snapshot
+	}
+	return values, nil
+}
+
+func toJournalEntryResponse(entry models.JournalEntry, values map[string]models.UserDailyTotalAssetValue) models.JournalEntryResponse {
+	response := models.JournalEntryResponse{
+		ID:        entry.ID,
+		Title:     entry.Title,
+		Body:      entry.Body,
+		Tags:      entry.Tags,
+		EntryDate: entry.EntryDate,
+		AssetType: entry.AssetType,
+		Ticker:    entry.Ticker,
+		TradeIDs:  entry.TradeIDs,
+		CreatedAt: entry.CreatedAt,
+		UpdatedAt: entry.UpdatedAt,
+	}
+	if response.Tags == nil {
+		response.Tags = []string{}
+	}
+	if response.TradeIDs == nil {
+		response.TradeIDs = []string{}
+	}
+
+	if entry.EntryDate != nil {
+		if snapshot, ok := values[entry.EntryDate.Format("2006-01-02")]; ok {
+			response.PortfolioValue = &models.JournalPortfolioValue{
+				Date:       snapshot.Date,
+				TotalValue: snapshot.TotalValue,
+				Currency:   snapshot.Currency,
+			}
+		}
+	}
+	return response
+}
+
+func normalizeJournalTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = normalizeJournalTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+func normalizeJournalTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+func journalNotFoundOr(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.NewAppError(models.ErrCodeNotFound, "Journal entry not found")
+	}
+	return err
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"asset-diary/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockJournalRepository struct {
+	mock.Mock
+}
+
+func (m *MockJournalRepository) ListEntries(userID string, filter models.JournalEntryFilter) ([]models.JournalEntry, error) {
+	args := m.Called(userID, filter)
+	return args.Get(0).([]models.JournalEntry), args.Error(1)
+}
+
+func (m *MockJournalRepository) GetEntry(userID, entryID string) (*models.JournalEntry, error) {
+	args := m.Called(userID, entryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.JournalEntry), args.Error(1)
+}
+
+func (m *MockJournalRepository) CreateEntry(userID string, entry models.JournalEntry) (*models.JournalEntry, error) {
+	args := m.Called(userID, entry)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.JournalEntry), args.Error(1)
+}
+
+func (m *MockJournalRepository) UpdateEntry(userID, entryID string, req models.JournalEntryUpdateRequest) (*models.JournalEntry, error) {
+	args := m.Called(userID, entryID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.JournalEntry), args.Error(1)
+}
+
+func (m *MockJournalRepository) DeleteEntry(userID, entryID string) (bool, error) {
+	args := m.Called(userID, entryID)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockDailyTotalAssetValueService struct {
+	mock.Mock
+}
+
+func (m *MockDailyTotalAssetValueService) RecordDailyTotalAssetValue() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockDailyTotalAssetValueService) GetUserDailyTotalAssetValues(userID string, startDate, endDate time.Time) ([]models.UserDailyTotalAssetValue, error) {
+	args := m.Called(userID, startDate, endDate)
+	return args.Get(0).([]models.UserDailyTotalAssetValue), args.Error(1)
+}
+
+func (m *MockDailyTotalAssetValueService) GetBreakdown(userID string, date time.Time) (*models.DailyAssetBreakdown, error) {
+	args := m.Called(userID, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DailyAssetBreakdown), args.Error(1)
+}
+
+func (m *MockDailyTotalAssetValueService) GetTickerSeries(userID, assetType, ticker string, startDate, endDate time.Time) ([]models.TickerValuePoint, error) {
+	args := m.Called(userID, assetType, ticker, startDate, endDate)
+	return args.Get(0).([]models.TickerValuePoint), args.Error(1)
+}
+
+func (m *MockDailyTotalAssetValueService) GetAccountSeries(userID, accountID string, startDate, endDate time.Time) ([]models.AccountValuePoint, error) {
+	args := m.Called(userID, accountID, startDate, endDate)
+	return args.Get(0).([]models.AccountValuePoint), args.Error(1)
+}
+
+func TestNormalizeJournalTags(t *testing.T) {
+	tags := normalizeJournalTags([]string{" Earnings ", "earnings", "", "  ", "Macro", "EARNINGS"})
+
+	assert.Equal(t, []string{"earnings", "macro"}, tags)
+}
+
+func TestJournalServiceCreateEntry(t *testing.T) {
+	mockRepo := new(MockJournalRepository)
+	mockTradeService := new(MockTradeService)
+	mockDailyAssetService := new(MockDailyTotalAssetValueService)
+	service := NewJournalService(mockRepo, mockTradeService, mockDailyAssetService)
+
+	entryDate := date(2026, 3, 2)
+
+	t.Run("normalizes tags and ticker and attaches the portfolio value", func(t *testing.T) {
+		mockTradeService.On("IsTradeOwnedByUser", "trade-1", "user1").Return(true, nil).Once()
+		mockRepo.On("CreateEntry", "user1", mock.MatchedBy(func(entry models.JournalEntry) bool {
+			return assert.ObjectsAreEqual([]string{"earnings", "tsmc"}, []string(entry.Tags)) &&
+				*entry.Ticker == "2330" &&
+				assert.ObjectsAreEqual([]string{"trade-1"}, entry.TradeIDs) &&
+				entry.EntryDate.Equal(entryDate)
+		})).Return(&models.JournalEntry{
+			ID:        "entry-1",
+			Title:     "Q4 results",
+			Tags:      []string{"earnings", "tsmc"},
+			EntryDate: &entryDate,
+			TradeIDs:  []string{"trade-1"},
+		}, nil).Once()
+		mockDailyAssetService.On("GetUserDailyTotalAssetValues", "user1", entryDate, entryDate).Return([]models.UserDailyTotalAssetValue{
+			{Date: entryDate, TotalValue: 1500000, Currency: "TWD"},
+		}, nil).Once()
+
+		response, err := service.CreateEntry("user1", models.JournalEntryCreateRequest{
+			Title:     "Q4 results",
+			Tags:      []string{" Earnings", "TSMC", "earnings"},
+			EntryDate: "2026-03-02",
+			Ticker:    " 2330 ",
+			TradeIDs:  []string{"trade-1", "trade-1"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"earnings", "tsmc"}, []string(response.Tags))
+		assert.NotNil(t, response.PortfolioValue)
+		assert.InDelta(t, 1500000, response.PortfolioValue.TotalValue, 1e-9)
+		mockRepo.AssertExpectations(t)
+		mockTradeService.AssertExpectations(t)
+		mockDailyAssetService.AssertExpectations(t)
+	})
+
+	t.Run("rejects trades of another user", func(t *testing.T) {
+		mockTradeService.On("IsTradeOwnedByUser", "trade-2", "user1").Return(false, nil).Once()
+
+		response, err := service.CreateEntry("user1", models.JournalEntryCreateRequest{
+			Title:    "Someone else's trade",
+			TradeIDs: []string{"trade-2"},
+		})
+
+		assert.Nil(t, response)
+		appErr, ok := err.(*models.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, models.ErrCodeInvalidRequest, appErr.Code)
+		// only the entry of the previous case was created
+		mockRepo.AssertNumberOfCalls(t, "CreateEntry", 1)
+	})
+}
+
+func TestJournalServiceListEntriesNormalizesTagFilter(t *testing.T) {
+	mockRepo := new(MockJournalRepository)
+	service := NewJournalService(mockRepo, new(MockTradeService), new(MockDailyTotalAssetValueService))
+
+	mockRepo.On("ListEntries", "user1", models.JournalEntryFilter{Tag: "earnings", Ticker: "2330"}).
+		Return([]models.JournalEntry{{ID: "entry-1", Title: "Q4 results", Tags: []string{"earnings"}}}, nil).Once()
+
+	responses, err := service.ListEntries("user1", models.JournalEntryFilter{Tag: " Earnings ", Ticker: "2330"})
+
+	assert.NoError(t, err)
+	assert.Len(t, responses, 1)
+	assert.Equal(t, []string{}, responses[0].TradeIDs)
+	assert.Nil(t, responses[0].PortfolioValue)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestJournalServiceOwnership(t *testing.T) {
+	mockRepo := new(MockJournalRepository)
+	service := NewJournalService(mockRepo, new(MockTradeService), new(MockDailyTotalAssetValueService))
+
+	t.Run("an entry of another user is not found", func(t *testing.T) {
+		mockRepo.On("GetEntry", "user2", "entry-1").Return(nil, gorm.ErrRecordNotFound).Once()
+
+		response, err := service.GetEntry("user2", "entry-1")
+
+		assert.Nil(t, response)
+		appErr, ok := err.(*models.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, models.ErrCodeNotFound, appErr.Code)
+	})
+
+	t.Run("updating an entry of another user is not found", func(t *testing.T) {
+		title := "Changed"
+		req := models.JournalEntryUpdateRequest{Title: &title}
+		mockRepo.On("UpdateEntry", "user2", "entry-1", req).Return(nil, gorm.ErrRecordNotFound).Once()
+
+		response, err := service.UpdateEntry("user2", "entry-1", req)
+
+		assert.Nil(t, response)
+		appErr, ok := err.(*models.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, models.ErrCodeNotFound, appErr.Code)
+	})
+
+	t.Run("deleting an entry of another user is not found", func(t *testing.T) {
+		mockRepo.On("DeleteEntry", "user2", "entry-1").Return(false, nil).Once()
+
+		err := service.DeleteEntry("user2", "entry-1")
+
+		appErr, ok := err.(*models.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, models.ErrCodeNotFound, appErr.Code)
+	})
+
+	mockRepo.AssertExpectations(t)
+}
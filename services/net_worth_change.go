@@ -0,0 +1,185 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"asset-diary/models"
+)
+
+// GetNetWorthChange decomposes the change in net worth between the first and last snapshot within
+// the period into contributions, income, market gains, FX effect and fees
+func (s *PerformanceService) GetNetWorthChange(userID string, startDate, endDate time.Time) (*models.NetWorthChange, error) {
+	defaultCurrency, err := s.profileSvc.GetDefaultCurrency(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user profile: %w", err)
+	}
+
+	snapshots, err := s.dailyAssetSvc.GetUserDailyTotalAssetValues(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return &models.NetWorthChange{Currency: defaultCurrency, UnconvertedCurrencies: []string{}}, nil
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Date.Before(snapshots[j].Date)
+	})
+
+	opening, err := s.dailyAssetSvc.GetBreakdown(userID, snapshots[0].Date)
+	if err != nil {
+		return nil, err
+	}
+	closing, err := s.dailyAssetSvc.GetBreakdown(userID, snapshots[len(snapshots)-1].Date)
+	if err != nil {
+		return nil, err
+	}
+
+	txns, err := s.accountSvc.ListUserTransactions(userID, opening.Date, closing.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	trades, err := s.tradeSvc.ListTrades(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ratesOn := historicalRatesLookup(s.exchangeSvc, defaultCurrency)
+
+	change := decomposeNetWorthChange(defaultCurrency, opening, closing, txns, trades, ratesOn)
+	return &change, nil
+}
+
+// decomposeNetWorthChange splits the change between two snapshots. Each position's market gain is
+// its change in value less the net amount traded, in its own currency, converted at the closing
+// rate. The FX effect revalues the opening value of every component, and every cash movement, from
+// its own rate to the closing rate.
+func decomposeNetWorthChange(
+	defaultCurrency string,
+	opening, closing *models.DailyAssetBreakdown,
+	txns []models.AccountTransaction,
+	trades []models.Trade,
+	ratesOn func(time.Time) map[string]float64,
+) models.NetWorthChange {
+	result := models.NetWorthChange{
+		StartDate:  &opening.Date,
+		EndDate:    &closing.Date,
+		Currency:   defaultCurrency,
+		StartValue: opening.TotalValue,
+		EndValue:   closing.TotalValue,
+		Change:     closing.TotalValue - opening.TotalValue,
+	}
+	inPeriod := func(date time.Time) bool {
+		return date.After(opening.Date) && !date.After(closing.Date)
+	}
+
+	// the snapshots' own rates keep the decomposition consistent with their totals
+	closingRates := map[string]float64{}
+	for currency, rate := range ratesOn(closing.Date) {
+		closingRates[currency] = rate
+	}
+	for _, component := range append(append([]models.UserDailyAssetComponent{}, closing.Holdings...), closing.Accounts...) {
+		if component.FXRate != nil && *component.FXRate > 0 {
+			closingRates[component.Currency] = *component.FXRate
+		}
+	}
+
+	unconverted := map[string]bool{}
+	atClose := func(amount float64, currency string) (float64, bool) {
+		value, ok := convertWithRates(amount, currency, defaultCurrency, closingRates)
+		if !ok {
+			unconverted[currency] = true
+		}
+		return value, ok
+	}
+	// flow converts a movement at the rate of its date and adds its revaluation to the closing rate
+	flow := func(amount float64, currency string, date time.Time) float64 {
+		value, ok := convertWithRates(amount, currency, defaultCurrency, ratesOn(date))
+		if !ok {
+			unconverted[currency] = true
+			return 0
+		}
+		if closeValue, ok := atClose(amount, currency); ok {
+			result.FXEffect += closeValue - value
+		}
+		return value
+	}
+
+	for _, component := range append(append([]models.UserDailyAssetComponent{}, opening.Holdings...), opening.Accounts...) {
+		if component.FXRate == nil || component.ValueInDefaultCurrency == nil {
+			continue
+		}
+		if closeValue, ok := atClose(component.Value, component.Currency); ok {
+			result.FXEffect += closeValue - *component.ValueInDefaultCurrency
+		}
+	}
+
+	for _, txn := range txns {
+		if !inPeriod(txn.TransactionDate) {
+			continue
+		}
+		amount := flow(txn.SignedAmount(), txn.Currency, txn.TransactionDate)
+		switch txn.Type {
+		case models.AccountTransactionTypeDeposit:
+			result.Deposits += amount
+		case models.AccountTransactionTypeWithdrawal:
+			result.Withdrawals -= amount
+		case models.AccountTransactionTypeDividend, models.AccountTransactionTypeInterest:
+			result.InvestmentIncome += amount
+		case models.AccountTransactionTypeFee:
+			result.Fees -= amount
+		}
+	}
+
+	type position struct {
+		currency string
+		change   float64 // closing value less opening value less net amount bought, in currency
+	}
+	positions := make(map[string]*position)
+	positionOf := func(accountID, assetType, ticker, currency string) *position {
+		key := fmt.Sprintf("%s_%s_%s_%s", accountID, assetType, strings.ToUpper(ticker), currency)
+		if positions[key] == nil {
+			positions[key] = &position{currency: currency}
+		}
+		return positions[key]
+	}
+	for _, component := range opening.Holdings {
+		if component.FXRate != nil {
+			positionOf(component.AccountID, component.AssetType, component.Ticker, component.Currency).change -= component.Value
+		}
+	}
+	for _, component := range closing.Holdings {
+		if component.FXRate != nil {
+			positionOf(component.AccountID, component.AssetType, component.Ticker, component.Currency).change += component.Value
+		}
+	}
+	for _, trade := range trades {
+		if !inPeriod(trade.TradeDate) {
+			continue
+		}
+		amount := trade.Quantity * trade.Price
+		if trade.Type == "sell" {
+			amount = -amount
+		}
+		result.NetPurchases += flow(amount, trade.Currency, trade.TradeDate)
+		positionOf(trade.AccountID, trade.AssetType, trade.Ticker, trade.Currency).change -= amount
+	}
+	for _, p := range positions {
+		if gain, ok := atClose(p.change, p.currency); ok {
+			result.MarketGain += gain
+		}
+	}
+
+	result.NetContributions = result.Deposits - result.Withdrawals + result.NetPurchases
+	result.Other = result.Change - result.NetContributions - result.InvestmentIncome - result.MarketGain - result.FXEffect + result.Fees
+
+	result.UnconvertedCurrencies = make([]string, 0, len(unconverted))
+	for currency := range unconverted {
+		result.UnconvertedCurrencies = append(result.UnconvertedCurrencies, currency)
+	}
+	sort.Strings(result.UnconvertedCurrencies)
+	return result
+}
@@ -0,0 +1,511 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"asset-diary/models"
+)
+
+type PerformanceServiceInterface interface {
+	GetPerformance(userID string, startDate, endDate time.Time) (*models.PerformanceReport, error)
+	GetNetWorthChange(userID string, startDate, endDate time.Time) (*models.NetWorthChange, error)
+	GetReturnCalendar(userID string, startDate, endDate time.Time) (*models.ReturnCalendar, error)
+}
+
+type PerformanceService struct {
+	dailyAssetSvc DailyTotalAssetValueServiceInterface
+	accountSvc    AccountServiceInterface
+	tradeSvc      TradeServiceInterface
+	profileSvc    ProfileServiceInterface
+	exchangeSvc   ExchangeRateServiceInterface
+}
+
+func NewPerformanceService(
+	dailyAssetSvc DailyTotalAssetValueServiceInterface,
+	accountSvc AccountServiceInterface,
+	tradeSvc TradeServiceInterface,
+	profileSvc ProfileServiceInterface,
+	exchangeSvc ExchangeRateServiceInterface,
+) *PerformanceService {
+	return &PerformanceService{
+		dailyAssetSvc: dailyAssetSvc,
+		accountSvc:    accountSvc,
+		tradeSvc:      tradeSvc,
+		profileSvc:    profileSvc,
+		exchangeSvc:   exchangeSvc,
+	}
+}
+
+// cashFlow is an amount seen from the investor: negative when money goes into the investment
+type cashFlow struct {
+	Date   time.Time
+	Amount float64
+}
+
+// GetPerformance computes portfolio returns between the first and last snapshot within the period.
+// Account and holding breakdowns are money-weighted over the same span, from the positions in the
+// first snapshot and the trades since, up to their values in the last snapshot.
+func (s *PerformanceService) GetPerformance(userID string, startDate, endDate time.Time) (*models.PerformanceReport, error) {
+	defaultCurrency, err := s.profileSvc.GetDefaultCurrency(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user profile: %w", err)
+	}
+
+	snapshots, err := s.dailyAssetSvc.GetUserDailyTotalAssetValues(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	txns, err := s.accountSvc.ListUserTransactions(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	trades, err := s.tradeSvc.ListTrades(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ratesOn := historicalRatesLookup(s.exchangeSvc, defaultCurrency)
+	flows := portfolioCashFlows(txns, tradesBetween(trades, startDate, endDate), defaultCurrency, ratesOn)
+
+	report := &models.PerformanceReport{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Currency:  defaultCurrency,
+		Portfolio: portfolioPerformance(snapshots, flows),
+		Accounts:  []models.PositionPerformance{},
+		Holdings:  []models.PositionPerformance{},
+	}
+	if len(snapshots) == 0 {
+		return report, nil
+	}
+
+	opening, err := s.dailyAssetSvc.GetBreakdown(userID, report.Portfolio.StartDate)
+	if err != nil {
+		return nil, err
+	}
+	closing, err := s.dailyAssetSvc.GetBreakdown(userID, report.Portfolio.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	periodTrades := tradesBetween(trades, opening.Date.AddDate(0, 0, 1), closing.Date)
+
+	report.Holdings = holdingPerformances(opening, closing, periodTrades)
+
+	accounts, err := s.accountSvc.ListAccounts(userID)
+	if err != nil {
+		return nil, err
+	}
+	report.Accounts = accountPerformances(accounts, opening, closing, periodTrades, defaultCurrency, ratesOn)
+
+	return report, nil
+}
+
+// historicalRatesLookup returns the rates of baseCurrency on a date, reading each date once. The
+// current rates stand in for dates whose rates cannot be read.
+func historicalRatesLookup(exchangeSvc ExchangeRateServiceInterface, baseCurrency string) func(time.Time) map[string]float64 {
+	historicalRates := make(map[string]map[string]float64)
+	var currentRates map[string]float64
+	return func(date time.Time) map[string]float64 {
+		day := date.Format("2006-01-02")
+		if cached, ok := historicalRates[day]; ok {
+			return cached
+		}
+		dayRates, err := exchangeSvc.GetHistoricalRates(baseCurrency, date)
+		if err != nil {
+			log.Printf("Error getting exchange rates on %s, using today's: %v", day, err)
+			if currentRates == nil {
+				if currentRates, err = exchangeSvc.GetRatesByBaseCurrency(baseCurrency); err != nil {
+					log.Printf("Error getting exchange rates: %v", err)
+				}
+			}
+			dayRates = currentRates
+		}
+		historicalRates[day] = dayRates
+		return dayRates
+	}
+}
+
+// tradesBetween returns the trades made from startDate to endDate inclusive
+func tradesBetween(trades []models.Trade, startDate, endDate time.Time) []models.Trade {
+	result := make([]models.Trade, 0, len(trades))
+	for _, trade := range trades {
+		if !trade.TradeDate.Before(startDate) && !trade.TradeDate.After(endDate) {
+			result = append(result, trade)
+		}
+	}
+	return result
+}
+
+// loadPortfolioCashFlows reads the external flows of the user's portfolio between the dates
+func loadPortfolioCashFlows(
+	accountSvc AccountServiceInterface,
+	tradeSvc TradeServiceInterface,
+	exchangeSvc ExchangeRateServiceInterface,
+	userID, defaultCurrency string,
+	startDate, endDate time.Time,
+) ([]cashFlow, error) {
+	txns, err := accountSvc.ListUserTransactions(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	trades, err := tradeSvc.ListTrades(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ratesOn := historicalRatesLookup(exchangeSvc, defaultCurrency)
+	return portfolioCashFlows(txns, tradesBetween(trades, startDate, endDate), defaultCurrency, ratesOn), nil
+}
+
+// portfolioCashFlows converts the money put into the portfolio into the default currency at the
+// rate of each flow's date. Trades are not booked against account cash, so the amount bought
+// counts as money put in and the amount sold as money taken out, next to deposits and withdrawals.
+func portfolioCashFlows(
+	txns []models.AccountTransaction,
+	trades []models.Trade,
+	defaultCurrency string,
+	ratesOn func(time.Time) map[string]float64,
+) []cashFlow {
+	flows := make([]cashFlow, 0, len(txns)+len(trades))
+	add := func(amount float64, currency string, date time.Time) {
+		value, ok := convertWithRates(amount, currency, defaultCurrency, ratesOn(date))
+		if !ok {
+			log.Printf("No exchange rate found for %s to %s", currency, defaultCurrency)
+			return
+		}
+		flows = append(flows, cashFlow{Date: date, Amount: value})
+	}
+
+	for _, txn := range txns {
+		if txn.Type == models.AccountTransactionTypeDeposit || txn.Type == models.AccountTransactionTypeWithdrawal {
+			add(txn.SignedAmount(), txn.Currency, txn.TransactionDate)
+		}
+	}
+	for _, trade := range trades {
+		// a trade's cash flow is seen from the investor, the opposite of money put in
+		add(-tradeCashFlow(trade, 1).Amount, trade.Currency, trade.TradeDate)
+	}
+
+	sort.SliceStable(flows, func(i, j int) bool {
+		return flows[i].Date.Before(flows[j].Date)
+	})
+	return flows
+}
+
+// portfolioPerformance chain-links the daily snapshots into a time-weighted return and solves the
+// money-weighted return over the same span. Money put in counts as a positive external flow.
+func portfolioPerformance(snapshots []models.UserDailyTotalAssetValue, deposits []cashFlow) models.PortfolioPerformance {
+	result := models.PortfolioPerformance{}
+	if len(snapshots) == 0 {
+		return result
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Date.Before(snapshots[j].Date)
+	})
+	first, last := snapshots[0], snapshots[len(snapshots)-1]
+	result.StartDate = first.Date
+	result.EndDate = last.Date
+	result.StartValue = first.TotalValue
+	result.EndValue = last.TotalValue
+
+	values := make([]cashFlow, len(snapshots))
+	for i, snapshot := range snapshots {
+		values[i] = cashFlow{Date: snapshot.Date, Amount: snapshot.TotalValue}
+	}
+
+	var periodFlows []cashFlow
+	for _, flow := range deposits {
+		if flow.Date.After(first.Date) && !flow.Date.After(last.Date) {
+			periodFlows = append(periodFlows, flow)
+			result.NetContributions += flow.Amount
+		}
+	}
+	result.InvestmentGain = result.EndValue - result.StartValue - result.NetContributions
+
+	days := last.Date.Sub(first.Date).Hours() / 24
+	if days <= 0 {
+		return result
+	}
+
+	if twr, ok := timeWeightedReturn(values, periodFlows); ok {
+		result.TimeWeightedReturnPercentage = percentage(twr)
+		if days >= 365 {
+			result.AnnualizedTimeWeightedReturnPercentage = percentage(math.Pow(1+twr, 365/days) - 1)
+		}
+	}
+
+	investorFlows := []cashFlow{{Date: first.Date, Amount: -first.TotalValue}}
+	for _, flow := range periodFlows {
+		investorFlows = append(investorFlows, cashFlow{Date: flow.Date, Amount: -flow.Amount})
+	}
+	investorFlows = append(investorFlows, cashFlow{Date: last.Date, Amount: last.TotalValue})
+	result.MoneyWeightedReturnPercentage, result.AnnualizedMoneyWeightedReturnPercentage = moneyWeightedReturn(investorFlows)
+
+	return result
+}
+
+// holdingPerformances measures each holding in its own currency, from its value in the opening
+// snapshot through the trades made after it to its value in the closing snapshot
+func holdingPerformances(opening, closing *models.DailyAssetBreakdown, trades []models.Trade) []models.PositionPerformance {
+	positions := make(map[string]*models.PositionPerformance)
+	flows := make(map[string][]cashFlow)
+	var keys []string
+	positionOf := func(assetType, ticker, name, currency string) (string, *models.PositionPerformance) {
+		key := fmt.Sprintf("%s_%s_%s", assetType, strings.ToUpper(ticker), currency)
+		if _, ok := positions[key]; !ok {
+			positions[key] = &models.PositionPerformance{
+				Ticker:     ticker,
+				TickerName: name,
+				AssetType:  assetType,
+				Currency:   currency,
+			}
+			keys = append(keys, key)
+		}
+		return key, positions[key]
+	}
+
+	for _, component := range opening.Holdings {
+		_, position := positionOf(component.AssetType, component.Ticker, component.Name, component.Currency)
+		position.StartValue += component.Value
+	}
+	for _, component := range closing.Holdings {
+		_, position := positionOf(component.AssetType, component.Ticker, component.Name, component.Currency)
+		position.EndValue += component.Value
+	}
+	for _, trade := range trades {
+		key, position := positionOf(trade.AssetType, trade.Ticker, trade.TickerName, trade.Currency)
+		flows[key] = append(flows[key], tradeCashFlow(trade, 1))
+		addTradeToPosition(position, trade, 1)
+	}
+
+	sort.Strings(keys)
+	result := make([]models.PositionPerformance, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, finishPosition(*positions[key], opening.Date, closing.Date, flows[key]))
+	}
+	return result
+}
+
+// accountPerformances measures the securities held in each account in the default currency, with
+// trades converted at the rate of their date. Cash balances are left out because trades are not
+// booked against them.
+func accountPerformances(
+	accounts []models.Account,
+	opening, closing *models.DailyAssetBreakdown,
+	trades []models.Trade,
+	defaultCurrency string,
+	ratesOn func(time.Time) map[string]float64,
+) []models.PositionPerformance {
+	positions := make(map[string]*models.PositionPerformance, len(accounts))
+	for _, acc := range accounts {
+		positions[acc.ID] = &models.PositionPerformance{
+			AccountID:   acc.ID,
+			AccountName: acc.Name,
+			Currency:    defaultCurrency,
+		}
+	}
+	for _, component := range opening.Holdings {
+		if position, ok := positions[component.AccountID]; ok && component.ValueInDefaultCurrency != nil {
+			position.StartValue += *component.ValueInDefaultCurrency
+		}
+	}
+	for _, component := range closing.Holdings {
+		if position, ok := positions[component.AccountID]; ok && component.ValueInDefaultCurrency != nil {
+			position.EndValue += *component.ValueInDefaultCurrency
+		}
+	}
+
+	flows := make(map[string][]cashFlow)
+	for _, trade := range trades {
+		position, ok := positions[trade.AccountID]
+		if !ok {
+			continue
+		}
+		factor, ok := convertWithRates(1, trade.Currency, defaultCurrency, ratesOn(trade.TradeDate))
+		if !ok {
+			log.Printf("No exchange rate found for %s to %s", trade.Currency, defaultCurrency)
+			continue
+		}
+		flows[trade.AccountID] = append(flows[trade.AccountID], tradeCashFlow(trade, factor))
+		addTradeToPosition(position, trade, factor)
+	}
+
+	result := make([]models.PositionPerformance, 0, len(accounts))
+	for _, acc := range accounts {
+		result = append(result, finishPosition(*positions[acc.ID], opening.Date, closing.Date, flows[acc.ID]))
+	}
+	return result
+}
+
+func tradeCashFlow(trade models.Trade, factor float64) cashFlow {
+	amount := trade.Quantity * trade.Price * factor
+	if trade.Type == "buy" {
+		amount = -amount
+	}
+	return cashFlow{Date: trade.TradeDate, Amount: amount}
+}
+
+func addTradeToPosition(position *models.PositionPerformance, trade models.Trade, factor float64) {
+	amount := trade.Quantity * trade.Price * factor
+	switch trade.Type {
+	case "buy":
+		position.Invested += amount
+	case "sell":
+		position.Proceeds += amount
+	}
+}
+
+// finishPosition adds the gain and the money-weighted return of a position held at startValue on
+// startDate and at endValue on endDate
+func finishPosition(position models.PositionPerformance, startDate, endDate time.Time, trades []cashFlow) models.PositionPerformance {
+	position.GainLoss = position.Proceeds + position.EndValue - position.Invested - position.StartValue
+	if position.StartValue == 0 && len(trades) == 0 {
+		return position
+	}
+
+	flows := make([]cashFlow, 0, len(trades)+2)
+	if position.StartValue != 0 {
+		flows = append(flows, cashFlow{Date: startDate, Amount: -position.StartValue})
+	}
+	flows = append(flows, trades...)
+	flows = append(flows, cashFlow{Date: endDate, Amount: position.EndValue})
+	position.MoneyWeightedReturnPercentage, position.AnnualizedMoneyWeightedReturnPercentage = moneyWeightedReturn(flows)
+	return position
+}
+
+// timeWeightedReturn links the sub-period returns between consecutive values, removing the
+// external flows that happened on or before each value date
+func timeWeightedReturn(values []cashFlow, flows []cashFlow) (float64, bool) {
+	if len(values) < 2 {
+		return 0, false
+	}
+
+	index, linked := timeWeightedIndex(values, flows)
+	return index[len(index)-1] - 1, linked
+}
+
+// timeWeightedIndex returns the cumulative growth of one unit invested at the first value date,
+// one point per value. Sub-periods starting from a non-positive value are treated as flat.
+func timeWeightedIndex(values []cashFlow, flows []cashFlow) ([]float64, bool) {
+	index := make([]float64, len(values))
+	if len(values) == 0 {
+		return index, false
+	}
+
+	growth := 1.0
+	linked := false
+	index[0] = growth
+	for i := 1; i < len(values); i++ {
+		previous, current := values[i-1], values[i]
+		flow := 0.0
+		for _, f := range flows {
+			if f.Date.After(previous.Date) && !f.Date.After(current.Date) {
+				flow += f.Amount
+			}
+		}
+		if previous.Amount > 0 {
+			growth *= (current.Amount - flow) / previous.Amount
+			linked = true
+		}
+		index[i] = growth
+	}
+
+	return index, linked
+}
+
+// moneyWeightedReturn returns the money-weighted return over the span of the flows together with its
+// annualized rate. The annualized rate is only reported for spans of at least a year.
+func moneyWeightedReturn(flows []cashFlow) (*float64, *float64) {
+	rate, err := xirr(flows)
+	if err != nil {
+		return nil, nil
+	}
+
+	start, end := flows[0].Date, flows[0].Date
+	for _, flow := range flows {
+		if flow.Date.Before(start) {
+			start = flow.Date
+		}
+		if flow.Date.After(end) {
+			end = flow.Date
+		}
+	}
+	days := end.Sub(start).Hours() / 24
+
+	period := percentage(math.Pow(1+rate, days/365) - 1)
+	if days < 365 {
+		return period, nil
+	}
+	return period, percentage(rate)
+}
+
+// xirr finds the annual rate at which the net present value of the flows is zero, using bisection
+// since it always converges once the root is bracketed
+func xirr(flows []cashFlow) (float64, error) {
+	if len(flows) < 2 {
+		return 0, errors.New("at least two cash flows are required")
+	}
+
+	hasPositive, hasNegative := false, false
+	start := flows[0].Date
+	for _, flow := range flows {
+		hasPositive = hasPositive || flow.Amount > 0
+		hasNegative = hasNegative || flow.Amount < 0
+		if flow.Date.Before(start) {
+			start = flow.Date
+		}
+	}
+	if !hasPositive || !hasNegative {
+		return 0, errors.New("cash flows must contain both a positive and a negative amount")
+	}
+
+	npv := func(rate float64) float64 {
+		total := 0.0
+		for _, flow := range flows {
+			years := flow.Date.Sub(start).Hours() / 24 / 365
+			total += flow.Amount / math.Pow(1+rate, years)
+		}
+		return total
+	}
+
+	low, high := -0.999999, 1.0
+	for npv(low)*npv(high) > 0 {
+		high *= 2
+		if high > 1e9 {
+			return 0, errors.New("rate could not be bracketed")
+		}
+	}
+
+	for i := 0; i < 300; i++ {
+		mid := (low + high) / 2
+		value := npv(mid)
+		if math.Abs(value) < 1e-9 || (high-low)/2 < 1e-12 {
+			return mid, nil
+		}
+		if npv(low)*value < 0 {
+			high = mid
+		} else {
+			low = mid
+		}
+	}
+
+	return (low + high) / 2, nil
+}
+
+func percentage(fraction float64) *float64 {
+	value := fraction * 100
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil
+	}
+	return &value
+}
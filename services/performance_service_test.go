@@ -0,0 +1,319 @@
+package services
+
+import (
+	"asset-diary/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestXIRR(t *testing.T) {
+	t.Run("single investment held for a year", func(t *testing.T) {
+		rate, err := xirr([]cashFlow{
+			{Date: date(2025, 1, 1), Amount: -1000},
+			{Date: date(2026, 1, 1), Amount: 1100},
+		})
+
+		assert.NoError(t, err)
+		assert.InDelta(t, 0.10, rate, 1e-6)
+	})
+
+	t.Run("loss is a negative rate", func(t *testing.T) {
+		rate, err := xirr([]cashFlow{
+			{Date: date(2025, 1, 1), Amount: -1000},
+			{Date: date(2026, 1, 1), Amount: 800},
+		})
+
+		assert.NoError(t, err)
+		assert.InDelta(t, -0.20, rate, 1e-6)
+	})
+
+	t.Run("flows without a sign change have no rate", func(t *testing.T) {
+		_, err := xirr([]cashFlow{
+			{Date: date(2025, 1, 1), Amount: -1000},
+			{Date: date(2026, 1, 1), Amount: -100},
+		})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestTimeWeightedReturn(t *testing.T) {
+	values := []cashFlow{
+		{Date: date(2025, 1, 1), Amount: 100},
+		{Date: date(2025, 1, 2), Amount: 110},
+		{Date: date(2025, 1, 3), Amount: 165},
+	}
+	deposits := []cashFlow{{Date: date(2025, 1, 3), Amount: 50}}
+
+	twr, ok := timeWeightedReturn(values, deposits)
+
+	assert.True(t, ok)
+	// 10% on day two, then (165 - 50) / 110 on day three
+	assert.InDelta(t, 1.1*(115.0/110.0)-1, twr, 1e-9)
+}
+
+func TestPortfolioPerformance(t *testing.T) {
+	snapshots := []models.UserDailyTotalAssetValue{
+		{Date: date(2025, 1, 1), TotalValue: 1000},
+		{Date: date(2025, 7, 1), TotalValue: 1600},
+		{Date: date(2026, 1, 1), TotalValue: 1650},
+	}
+	deposits := []cashFlow{
+		{Date: date(2024, 12, 31), Amount: 999}, // before the first snapshot, already in its value
+		{Date: date(2025, 7, 1), Amount: 500},
+	}
+
+	result := portfolioPerformance(snapshots, deposits)
+
+	assert.Equal(t, 1000.0, result.StartValue)
+	assert.Equal(t, 1650.0, result.EndValue)
+	assert.Equal(t, 500.0, result.NetContributions)
+	assert.Equal(t, 150.0, result.InvestmentGain)
+	assert.NotNil(t, result.TimeWeightedReturnPercentage)
+	assert.InDelta(t, (1100.0/1000.0)*(1650.0/1600.0)*100-100, *result.TimeWeightedReturnPercentage, 1e-9)
+	assert.NotNil(t, result.AnnualizedTimeWeightedReturnPercentage)
+	assert.NotNil(t, result.MoneyWeightedReturnPercentage)
+	assert.Greater(t, *result.MoneyWeightedReturnPercentage, 0.0)
+}
+
+func TestPortfolioCashFlows(t *testing.T) {
+	txns := []models.AccountTransaction{
+		{Type: models.AccountTransactionTypeDeposit, Amount: 1000, Currency: "USD", TransactionDate: date(2026, 3, 1)},
+		{Type: models.AccountTransactionTypeWithdrawal, Amount: 5000, Currency: "TWD", TransactionDate: date(2026, 3, 20)},
+		dividend("acc-1", "AAPL", date(2026, 3, 15), 10, "USD"),
+	}
+	trades := []models.Trade{
+		{Type: "buy", Quantity: 5, Price: 200, Currency: "USD", TradeDate: date(2026, 3, 2)},
+		{Type: "sell", Quantity: 1000, Price: 600, Currency: "TWD", TradeDate: date(2026, 3, 10)},
+		{Type: "buy", Quantity: 100, Price: 3000, Currency: "JPY", TradeDate: date(2026, 3, 11)},
+	}
+	ratesOn := func(day time.Time) map[string]float64 {
+		if day.Before(date(2026, 3, 2)) {
+			return map[string]float64{"USD": 1.0 / 30}
+		}
+		return map[string]float64{"USD": 1.0 / 32}
+	}
+
+	flows := portfolioCashFlows(txns, trades, "TWD", ratesOn)
+
+	// each flow at its own date's rate; the JPY trade has no rate and the dividend is income
+	assert.Equal(t, []cashFlow{
+		{Date: date(2026, 3, 1), Amount: 30000},
+		{Date: date(2026, 3, 2), Amount: 32000},
+		{Date: date(2026, 3, 10), Amount: -600000},
+		{Date: date(2026, 3, 20), Amount: -5000},
+	}, flows)
+}
+
+func TestPortfolioPerformanceIgnoresTrades(t *testing.T) {
+	snapshots := []models.UserDailyTotalAssetValue{
+		{Date: date(2026, 1, 1), TotalValue: 1000},
+		{Date: date(2026, 1, 2), TotalValue: 1500},
+		{Date: date(2026, 1, 3), TotalValue: 1000},
+	}
+	// 500 bought on day two and sold again on day three at the same price
+	trades := []models.Trade{
+		{Type: "buy", Quantity: 5, Price: 100, Currency: "TWD", TradeDate: date(2026, 1, 2)},
+		{Type: "sell", Quantity: 5, Price: 100, Currency: "TWD", TradeDate: date(2026, 1, 3)},
+	}
+	ratesOn := func(time.Time) map[string]float64 { return map[string]float64{} }
+
+	result := portfolioPerformance(snapshots, portfolioCashFlows(nil, trades, "TWD", ratesOn))
+
+	assert.InDelta(t, 0, result.InvestmentGain, 1e-9)
+	assert.InDelta(t, 0, *result.TimeWeightedReturnPercentage, 1e-9)
+	assert.InDelta(t, 0, *result.MoneyWeightedReturnPercentage, 1e-6)
+}
+
+func TestPositionPerformances(t *testing.T) {
+	holding := func(accountID, ticker, currency string, value, fxRate float64) models.UserDailyAssetComponent {
+		valueInDefault := value / fxRate
+		return models.UserDailyAssetComponent{
+			ComponentType:          models.SnapshotComponentHolding,
+			AccountID:              accountID,
+			AssetType:              "stock",
+			Ticker:                 ticker,
+			Name:                   ticker,
+			Currency:               currency,
+			Value:                  value,
+			FXRate:                 &fxRate,
+			ValueInDefaultCurrency: &valueInDefault,
+		}
+	}
+	opening := &models.DailyAssetBreakdown{
+		Date:     date(2025, 1, 1),
+		Holdings: []models.UserDailyAssetComponent{holding("acc-1", "2330", "TWD", 100000, 1), holding("acc-2", "AAPL", "USD", 1000, 1.0/30)},
+	}
+	closing := &models.DailyAssetBreakdown{
+		Date:     date(2026, 1, 1),
+		Holdings: []models.UserDailyAssetComponent{holding("acc-1", "2330", "TWD", 120000, 1), holding("acc-2", "AAPL", "USD", 2200, 1.0/32)},
+	}
+	trades := []models.Trade{
+		{AccountID: "acc-2", Type: "buy", AssetType: "stock", Ticker: "AAPL", Quantity: 5, Price: 200, Currency: "USD", TradeDate: date(2025, 7, 1)},
+	}
+	ratesOn := func(time.Time) map[string]float64 { return map[string]float64{"USD": 1.0 / 31} }
+
+	holdings := holdingPerformances(opening, closing, trades)
+
+	require.Len(t, holdings, 2)
+	tsmc := holdings[0]
+	assert.Equal(t, "2330", tsmc.Ticker)
+	assert.Equal(t, 100000.0, tsmc.StartValue)
+	assert.Equal(t, 120000.0, tsmc.EndValue)
+	assert.InDelta(t, 20000, tsmc.GainLoss, 1e-9)
+	assert.InDelta(t, 20, *tsmc.MoneyWeightedReturnPercentage, 1e-6)
+	apple := holdings[1]
+	assert.Equal(t, 1000.0, apple.Invested)
+	assert.InDelta(t, 200, apple.GainLoss, 1e-9)
+
+	accounts := accountPerformances([]models.Account{{ID: "acc-1", Name: "Broker"}, {ID: "acc-2", Name: "US Broker"}}, opening, closing, trades, "TWD", ratesOn)
+
+	require.Len(t, accounts, 2)
+	assert.Equal(t, "US Broker", accounts[1].AccountName)
+	assert.InDelta(t, 30000, accounts[1].StartValue, 1e-6)
+	assert.InDelta(t, 31000, accounts[1].Invested, 1e-6)
+	assert.InDelta(t, 70400, accounts[1].EndValue, 1e-6)
+	assert.InDelta(t, 70400-31000-30000, accounts[1].GainLoss, 1e-6)
+}
+
+func accountComponent(accountID, currency string, balance, fxRate float64) models.UserDailyAssetComponent {
+	valueInDefault := balance / fxRate
+	return models.UserDailyAssetComponent{
+		ComponentType:          models.SnapshotComponentAccount,
+		AccountID:              accountID,
+		Currency:               currency,
+		Value:                  balance,
+		FXRate:                 &fxRate,
+		ValueInDefaultCurrency: &valueInDefault,
+	}
+}
+
+func TestDecomposeNetWorthChange(t *testing.T) {
+	usdHolding := func(quantity, price, fxRate float64) models.UserDailyAssetComponent {
+		component := holdingComponent("acc-2", "AAPL", quantity, price, fxRate)
+		component.Currency = "USD"
+		return component
+	}
+	twdHolding := func(quantity, price float64) models.UserDailyAssetComponent {
+		component := holdingComponent("acc-1", "2330", quantity, price, 1)
+		component.Currency = "TWD"
+		return component
+	}
+
+	opening := &models.DailyAssetBreakdown{
+		Date:       date(2026, 8, 31),
+		TotalValue: 643000,
+		Holdings:   []models.UserDailyAssetComponent{twdHolding(1000, 600), usdHolding(10, 100, 1.0/30)},
+		Accounts:   []models.UserDailyAssetComponent{accountComponent("acc-1", "TWD", 10000, 1), accountComponent("acc-2", "USD", 100, 1.0/30)},
+	}
+	closing := &models.DailyAssetBreakdown{
+		Date:       date(2026, 9, 30),
+		TotalValue: 474440,
+		Holdings:   []models.UserDailyAssetComponent{twdHolding(500, 700), usdHolding(20, 110, 1.0/32)},
+		Accounts:   []models.UserDailyAssetComponent{accountComponent("acc-1", "TWD", 51000, 1), accountComponent("acc-2", "USD", 95, 1.0/32)},
+	}
+	trades := []models.Trade{
+		{AccountID: "acc-1", Type: "buy", AssetType: "stock", Ticker: "2330", Quantity: 1000, Price: 500, Currency: "TWD", TradeDate: date(2026, 1, 5)},
+		{AccountID: "acc-1", Type: "sell", AssetType: "stock", Ticker: "2330", Quantity: 500, Price: 650, Currency: "TWD", TradeDate: date(2026, 9, 10)},
+		{AccountID: "acc-2", Type: "buy", AssetType: "stock", Ticker: "AAPL", Quantity: 10, Price: 105, Currency: "USD", TradeDate: date(2026, 9, 15)},
+	}
+	txns := []models.AccountTransaction{
+		{AccountID: "acc-1", Type: models.AccountTransactionTypeDeposit, Amount: 50000, Currency: "TWD", TransactionDate: date(2026, 9, 1)},
+		{AccountID: "acc-1", Type: models.AccountTransactionTypeWithdrawal, Amount: 10000, Currency: "TWD", TransactionDate: date(2026, 9, 20)},
+		dividend("acc-1", "2330", date(2026, 9, 25), 1000, "TWD"),
+		{AccountID: "acc-2", Type: models.AccountTransactionTypeFee, Amount: 5, Currency: "USD", TransactionDate: date(2026, 9, 30)},
+	}
+	ratesOn := func(day time.Time) map[string]float64 {
+		if day.Equal(date(2026, 9, 15)) {
+			return map[string]float64{"USD": 1.0 / 31}
+		}
+		return map[string]float64{"USD": 1.0 / 32}
+	}
+
+	change := decomposeNetWorthChange("TWD", opening, closing, txns, trades, ratesOn)
+
+	assert.InDelta(t, -168560, change.Change, 1e-6)
+	assert.InDelta(t, 50000, change.Deposits, 1e-6)
+	assert.InDelta(t, 10000, change.Withdrawals, 1e-6)
+	assert.InDelta(t, -325000+32550, change.NetPurchases, 1e-6)
+	assert.InDelta(t, -252450, change.NetContributions, 1e-6)
+	assert.InDelta(t, 1000, change.InvestmentIncome, 1e-6)
+	assert.InDelta(t, 160, change.Fees, 1e-6)
+	// 2330 gained 75000 TWD; AAPL gained 150 USD at the closing rate
+	assert.InDelta(t, 79800, change.MarketGain, 1e-6)
+	// the dollar rose from 30 to 32 on the opening 1100 USD and from 31 to 32 on the 1050 USD bought
+	assert.InDelta(t, 3250, change.FXEffect, 1e-6)
+	assert.InDelta(t, 0, change.Other, 1e-6)
+	assert.Empty(t, change.UnconvertedCurrencies)
+}
+
+func TestReturnCalendar(t *testing.T) {
+	values := []cashFlow{
+		{Date: date(2026, 8, 30), Amount: 100},
+		{Date: date(2026, 8, 31), Amount: 110},
+		{Date: date(2026, 9, 1), Amount: 121},
+		{Date: date(2026, 9, 2), Amount: 171},
+		{Date: date(2026, 9, 3), Amount: 153.9},
+		{Date: date(2026, 9, 4), Amount: 146.205},
+	}
+	flows := []cashFlow{{Date: date(2026, 9, 2), Amount: 50}}
+
+	calendar := returnCalendar(values, flows)
+
+	require.Len(t, calendar.Daily, 6)
+	assert.Nil(t, calendar.Daily[0].ReturnPercentage)
+	assert.InDelta(t, 10, *calendar.Daily[1].ReturnPercentage, 1e-9)
+	// the deposit is not a return
+	assert.InDelta(t, 0, *calendar.Daily[3].ReturnPercentage, 1e-9)
+	assert.InDelta(t, -5, *calendar.Daily[5].ReturnPercentage, 1e-9)
+
+	require.Len(t, calendar.Monthly, 2)
+	assert.Equal(t, "2026-08", calendar.Monthly[0].Period)
+	assert.Equal(t, 1, calendar.Monthly[0].Days)
+	assert.InDelta(t, 10, *calendar.Monthly[0].ReturnPercentage, 1e-9)
+	assert.InDelta(t, -5.95, *calendar.Monthly[1].ReturnPercentage, 1e-9)
+	require.Len(t, calendar.Yearly, 1)
+	assert.Equal(t, 5, calendar.Yearly[0].Days)
+	assert.InDelta(t, 3.455, *calendar.Yearly[0].ReturnPercentage, 1e-9)
+
+	assert.Equal(t, 2, calendar.PositiveDays)
+	assert.Equal(t, 2, calendar.NegativeDays)
+	assert.Equal(t, date(2026, 8, 31), calendar.BestDay.Date)
+	assert.Equal(t, date(2026, 9, 3), calendar.WorstDay.Date)
+
+	assert.Equal(t, date(2026, 8, 31), calendar.LongestWinningStreak.StartDate)
+	assert.Equal(t, 2, calendar.LongestWinningStreak.Days)
+	assert.InDelta(t, 21, *calendar.LongestWinningStreak.ReturnPercentage, 1e-9)
+	assert.Equal(t, 2, calendar.LongestLosingStreak.Days)
+	assert.InDelta(t, -14.5, *calendar.LongestLosingStreak.ReturnPercentage, 1e-9)
+	assert.Equal(t, models.ReturnStreakLosing, calendar.CurrentStreak.Type)
+	assert.Equal(t, date(2026, 9, 4), calendar.CurrentStreak.EndDate)
+}
+
+func TestReturnCalendarExcludesTrades(t *testing.T) {
+	values := []cashFlow{
+		{Date: date(2026, 9, 1), Amount: 1000},
+		{Date: date(2026, 9, 2), Amount: 1550},
+		{Date: date(2026, 9, 3), Amount: 1050},
+	}
+	trades := []models.Trade{
+		{Type: "buy", Quantity: 10, Price: 50, Currency: "TWD", TradeDate: date(2026, 9, 2)},
+		{Type: "sell", Quantity: 10, Price: 50, Currency: "TWD", TradeDate: date(2026, 9, 3)},
+	}
+	ratesOn := func(time.Time) map[string]float64 { return map[string]float64{} }
+
+	calendar := returnCalendar(values, portfolioCashFlows(nil, trades, "TWD", ratesOn))
+
+	require.Len(t, calendar.Daily, 3)
+	assert.InDelta(t, 5, *calendar.Daily[1].ReturnPercentage, 1e-9)
+	// the sale is not a loss
+	assert.InDelta(t, 0, *calendar.Daily[2].ReturnPercentage, 1e-9)
+	assert.Equal(t, 0, calendar.NegativeDays)
+}
@@ -4,15 +4,22 @@ import (
 	"asset-diary/models"
 	"asset-diary/repositories"
 	"asset-diary/services/interfaces"
+	"errors"
+	"log"
 	"os"
 	"strconv"
+	"sync"
 	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
 type priceServiceCacheDecorator struct {
-	service   interfaces.AssetPriceServiceInterface
-	cacheRepo repositories.PriceCacheRepositoryInterface
-	cacheTTL  time.Duration
+	service      interfaces.AssetPriceServiceInterface
+	cacheRepo    repositories.PriceCacheRepositoryInterface
+	cacheTTL     time.Duration
+	maxStaleness time.Duration
+	group        singleflight.Group
 }
 
 func NewPriceServiceCacheDecorator(service interfaces.AssetPriceServiceInterface, cacheRepo repositories.PriceCacheRepositoryInterface) *priceServiceCacheDecorator {
@@ -25,20 +32,33 @@ func NewPriceServiceCacheDecorator(service interfaces.AssetPriceServiceInterface
 		}
 	}
 
+	// How long past the TTL a price may still be served while it is refreshed; 0 disables
+	maxStaleness := 60 * time.Minute
+
+	envStaleness := os.Getenv("PRICE_CACHE_MAX_STALENESS_MINUTES")
+	if envStaleness != "" {
+		if staleness, err := strconv.Atoi(envStaleness); err == nil && staleness >= 0 {
+			maxStaleness = time.Duration(staleness) * time.Minute
+		}
+	}
+
 	return &priceServiceCacheDecorator{
-		service:   service,
-		cacheRepo: cacheRepo,
-		cacheTTL:  cacheTTL,
+		service:      service,
+		cacheRepo:    cacheRepo,
+		cacheTTL:     cacheTTL,
+		maxStaleness: maxStaleness,
 	}
 }
 
+// getFromCache returns the cached price marked with its age, and whether it is past the TTL
 func (d *priceServiceCacheDecorator) getFromCache(assetType string, symbol string) (*models.TickerInfo, bool) {
 	cached, err := d.cacheRepo.Get(assetType, symbol)
 	if err != nil || cached == nil {
 		return nil, false
 	}
 
-	if time.Now().After(cached.ExpiresAt) {
+	now := time.Now()
+	if now.After(cached.KeepUntil()) {
 		return nil, false
 	}
 
@@ -49,48 +69,150 @@ func (d *priceServiceCacheDecorator) getFromCache(assetType string, symbol strin
 		Name:        cached.Name,
 		Currency:    cached.Currency,
 		LastUpdated: cached.UpdatedAt.Format(time.RFC3339),
+		AgeSeconds:  int64(now.Sub(cached.UpdatedAt).Seconds()),
+		Stale:       now.After(cached.ExpiresAt),
+		Provider:    cached.Provider,
 	}, true
 }
 
 func (d *priceServiceCacheDecorator) setInCache(info *models.TickerInfo) error {
-	expiresAt := time.Now().Add(d.cacheTTL)
+	now := time.Now()
+	expiresAt := now.Add(d.cacheTTL)
 
 	cache := &models.PriceCache{
-		AssetType: info.AssetType,
-		Symbol:    info.Symbol,
-		Name:      info.Name,
-		Price:     info.Price,
-		Currency:  info.Currency,
-		ExpiresAt: expiresAt,
+		AssetType:  info.AssetType,
+		Symbol:     info.Symbol,
+		Name:       info.Name,
+		Price:      info.Price,
+		Currency:   info.Currency,
+		Provider:   info.Provider,
+		ExpiresAt:  expiresAt,
+		StaleUntil: expiresAt.Add(d.maxStaleness),
+		UpdatedAt:  now,
 	}
 
 	return d.cacheRepo.Set(cache)
 }
 
-func (d *priceServiceCacheDecorator) GetStockPrice(symbol string) (*models.TickerInfo, error) {
-	if cached, found := d.getFromCache("stock", symbol); found {
-		return cached, nil
-	}
-
-	info, err := d.service.GetStockPrice(symbol)
+// refresh fetches a price and caches it, sharing one upstream call among concurrent callers for
+// the same symbol. Each caller gets its own copy of the result.
+func (d *priceServiceCacheDecorator) refresh(assetType string, symbol string, fetch func(string) (*models.TickerInfo, error)) (*models.TickerInfo, error) {
+	v, err, _ := d.group.Do(assetType+"_"+symbol, func() (interface{}, error) {
+		info, err := fetch(symbol)
+		if err != nil {
+			return nil, err
+		}
+		_ = d.setInCache(info)
+		return info, nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	info := *v.(*models.TickerInfo)
+	return &info, nil
+}
 
-	_ = d.setInCache(info)
-	return info, nil
+// refreshInBackground updates a stale price without holding up the caller serving it
+func (d *priceServiceCacheDecorator) refreshInBackground(assetType string, symbol string, fetch func(string) (*models.TickerInfo, error)) {
+	go func() {
+		if _, err := d.refresh(assetType, symbol, fetch); err != nil {
+			log.Printf("Failed to refresh %s price for %s: %v", assetType, symbol, err)
+		}
+	}()
 }
 
-func (d *priceServiceCacheDecorator) GetCryptoPrice(symbol string) (*models.TickerInfo, error) {
-	if cached, found := d.getFromCache("crypto", symbol); found {
+// getPrice serves a fresh cached price as is, a stale one while it is refreshed in the background,
+// and otherwise waits for the upstream
+func (d *priceServiceCacheDecorator) getPrice(assetType string, symbol string, fetch func(string) (*models.TickerInfo, error)) (*models.TickerInfo, error) {
+	if cached, found := d.getFromCache(assetType, symbol); found {
+		if cached.Stale {
+			d.refreshInBackground(assetType, symbol, fetch)
+		}
 		return cached, nil
 	}
 
-	info, err := d.service.GetCryptoPrice(symbol)
-	if err != nil {
-		return nil, err
+	return d.refresh(assetType, symbol, fetch)
+}
+
+func (d *priceServiceCacheDecorator) GetStockPrice(symbol string) (*models.TickerInfo, error) {
+	return d.getPrice("stock", symbol, d.service.GetStockPrice)
+}
+
+func (d *priceServiceCacheDecorator) GetCryptoPrice(symbol string) (*models.TickerInfo, error) {
+	return d.getPrice("crypto", symbol, d.service.GetCryptoPrice)
+}
+
+// GetPrices serves the cached quotes, refreshing stale ones in the background, and resolves the
+// rest through the wrapped service, in one batch when it supports batches and one symbol at a time
+// otherwise. Each miss goes through the same keyed group as GetStockPrice and GetCryptoPrice, so a
+// symbol another caller is already fetching is waited for instead of fetched again.
+func (d *priceServiceCacheDecorator) GetPrices(requests []models.PriceQuoteRequest) []models.PriceQuoteResult {
+	results := newPriceQuoteResults(requests)
+
+	var missing []int
+	var missingRequests []models.PriceQuoteRequest
+	for i, result := range results {
+		if cached, found := d.getFromCache(result.AssetType, result.Symbol); found {
+			results[i].Quote = cached
+			if cached.Stale {
+				switch result.AssetType {
+				case "stock":
+					d.refreshInBackground(result.AssetType, result.Symbol, d.service.GetStockPrice)
+				case "crypto":
+					d.refreshInBackground(result.AssetType, result.Symbol, d.service.GetCryptoPrice)
+				}
+			}
+			continue
+		}
+		missing = append(missing, i)
+		missingRequests = append(missingRequests, models.PriceQuoteRequest{AssetType: result.AssetType, Symbol: result.Symbol})
+	}
+	if len(missing) == 0 {
+		return results
 	}
 
-	_ = d.setInCache(info)
-	return info, nil
+	fetch := d.missFetcher(missingRequests)
+
+	var wg sync.WaitGroup
+	for j, i := range missing {
+		wg.Add(1)
+		go func(j int, result *models.PriceQuoteResult) {
+			defer wg.Done()
+			info, err := d.refresh(result.AssetType, result.Symbol, func(string) (*models.TickerInfo, error) {
+				return fetch(j)
+			})
+			setPriceQuote(result, info, err)
+		}(j, &results[i])
+	}
+	wg.Wait()
+
+	return results
+}
+
+// missFetcher returns how to fetch the jth of the missing requests. A batch service is asked once,
+// for every miss, the first time any of them is not already being fetched by another caller.
+func (d *priceServiceCacheDecorator) missFetcher(requests []models.PriceQuoteRequest) func(j int) (*models.TickerInfo, error) {
+	batch, ok := d.service.(interfaces.BatchAssetPriceServiceInterface)
+	if !ok {
+		return func(j int) (*models.TickerInfo, error) {
+			switch requests[j].AssetType {
+			case "stock":
+				return d.service.GetStockPrice(requests[j].Symbol)
+			case "crypto":
+				return d.service.GetCryptoPrice(requests[j].Symbol)
+			default:
+				return nil, errors.New("unsupported asset type")
+			}
+		}
+	}
+
+	var once sync.Once
+	var fetched []models.PriceQuoteResult
+	return func(j int) (*models.TickerInfo, error) {
+		once.Do(func() { fetched = batch.GetPrices(requests) })
+		if fetched[j].Quote == nil {
+			return nil, errors.New(fetched[j].Error)
+		}
+		return fetched[j].Quote, nil
+	}
 }
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"asset-diary/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockPriceCacheRepository is a mock implementation of PriceCacheRepositoryInterface
+type MockPriceCacheRepository struct {
+	mock.Mock
+}
+
+func (m *MockPriceCacheRepository) Get(assetType string, symbol string) (*models.PriceCache, error) {
+	args := m.Called(assetType, symbol)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PriceCache), args.Error(1)
+}
+
+func (m *MockPriceCacheRepository) Set(cache *models.PriceCache) error {
+	args := m.Called(cache)
+	return args.Error(0)
+}
+
+func TestCacheDecoratorGetPrices(t *testing.T) {
+	cacheRepo := new(MockPriceCacheRepository)
+	priceService := new(MockPriceService)
+	decorator := NewPriceServiceCacheDecorator(priceService, cacheRepo)
+
+	cacheRepo.On("Get", "stock", "2330").Return(&models.PriceCache{
+		AssetType: "stock", Symbol: "2330", Price: 1000, Currency: "TWD", ExpiresAt: time.Now().Add(time.Minute),
+	}, nil)
+	cacheRepo.On("Get", mock.Anything, mock.Anything).Return(nil, nil)
+	cacheRepo.On("Set", mock.Anything).Return(nil)
+	priceService.On("GetStockPrice", "AAPL").Return(&models.TickerInfo{AssetType: "stock", Symbol: "AAPL", Price: 200, Currency: "USD"}, nil)
+	priceService.On("GetCryptoPrice", "NOPE").Return(nil, errors.New(InvalidSymbolError))
+
+	results := decorator.GetPrices([]models.PriceQuoteRequest{
+		{AssetType: "stock", Symbol: "2330"},
+		{AssetType: "stock", Symbol: " aapl "},
+		{AssetType: "crypto", Symbol: "nope"},
+	})
+
+	require.Len(t, results, 3)
+	assert.Equal(t, 1000.0, results[0].Quote.Price)
+	assert.Equal(t, "AAPL", results[1].Symbol)
+	assert.Equal(t, 200.0, results[1].Quote.Price)
+	assert.Nil(t, results[2].Quote)
+	assert.Equal(t, InvalidSymbolError, results[2].Error)
+
+	priceService.AssertNotCalled(t, "GetStockPrice", "2330")
+	cacheRepo.AssertNumberOfCalls(t, "Set", 1)
+}
+
+func TestCacheDecoratorServesStalePriceWhileRefreshing(t *testing.T) {
+	cacheRepo := new(MockPriceCacheRepository)
+	priceService := new(MockPriceService)
+	decorator := NewPriceServiceCacheDecorator(priceService, cacheRepo)
+
+	updatedAt := time.Now().Add(-10 * time.Minute)
+	cacheRepo.On("Get", "stock", "AAPL").Return(&models.PriceCache{
+		AssetType: "stock", Symbol: "AAPL", Price: 190, Currency: "USD",
+		ExpiresAt: time.Now().Add(-5 * time.Minute), StaleUntil: time.Now().Add(time.Hour), UpdatedAt: updatedAt,
+	}, nil)
+	stored := make(chan *models.PriceCache, 1)
+	cacheRepo.On("Set", mock.Anything).Run(func(args mock.Arguments) {
+		stored <- args.Get(0).(*models.PriceCache)
+	}).Return(nil)
+	priceService.On("GetStockPrice", "AAPL").Return(&models.TickerInfo{AssetType: "stock", Symbol: "AAPL", Price: 200, Currency: "USD"}, nil)
+
+	info, err := decorator.GetStockPrice("AAPL")
+
+	require.NoError(t, err)
+	assert.Equal(t, 190.0, info.Price)
+	assert.True(t, info.Stale)
+	assert.InDelta(t, 600, info.AgeSeconds, 1)
+
+	// the refresh runs in the background and stores the new price with a stale window past its TTL
+	select {
+	case cache := <-stored:
+		assert.Equal(t, 200.0, cache.Price)
+		assert.Equal(t, decorator.maxStaleness, cache.StaleUntil.Sub(cache.ExpiresAt))
+	case <-time.After(time.Second):
+		t.Fatal("stale price was not refreshed")
+	}
+}
+
+func TestCacheDecoratorCoalescesConcurrentFetches(t *testing.T) {
+	cacheRepo := new(MockPriceCacheRepository)
+	priceService := new(MockPriceService)
+	decorator := NewPriceServiceCacheDecorator(priceService, cacheRepo)
+
+	release := make(chan struct{})
+	var misses sync.WaitGroup
+	cacheRepo.On("Get", "crypto", "BTC").Run(func(mock.Arguments) { misses.Done() }).Return(nil, nil)
+	cacheRepo.On("Set", mock.Anything).Return(nil)
+	priceService.On("GetCryptoPrice", "BTC").Run(func(mock.Arguments) { <-release }).Return(&models.TickerInfo{AssetType: "crypto", Symbol: "BTC", Price: 60000, Currency: "USD"}, nil)
+
+	const callers = 5
+	var wg sync.WaitGroup
+	results := make([]*models.TickerInfo, callers)
+	misses.Add(callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = decorator.GetCryptoPrice("BTC")
+		}(i)
+	}
+	// let every caller reach the cache miss before the upstream answers
+	misses.Wait()
+	close(release)
+	wg.Wait()
+
+	priceService.AssertNumberOfCalls(t, "GetCryptoPrice", 1)
+	for _, result := range results {
+		require.NotNil(t, result)
+		assert.Equal(t, 60000.0, result.Price)
+	}
+	assert.NotSame(t, results[0], results[1])
+}
+
+func TestFillPriceQuotes(t *testing.T) {
+	results := newPriceQuoteResults([]models.PriceQuoteRequest{
+		{AssetType: "stock", Symbol: "AAPL"},
+		{AssetType: "stock", Symbol: "MSFT"},
+		{AssetType: "stock", Symbol: "aapl"},
+		{AssetType: "stock", Symbol: "ZZZZ"},
+	})
+
+	var requested []string
+	fillPriceQuotes(results, []int{0, 1, 2, 3}, func(symbols []string) (map[string]*models.TickerInfo, error) {
+		requested = symbols
+		return map[string]*models.TickerInfo{
+			"AAPL": {Symbol: "AAPL", Price: 200},
+			"MSFT": {Symbol: "MSFT", Price: 400},
+		}, nil
+	})
+
+	assert.Equal(t, []string{"AAPL", "MSFT", "ZZZZ"}, requested)
+	assert.Equal(t, 200.0, results[2].Quote.Price)
+	assert.Equal(t, InvalidSymbolError, results[3].Error)
+
+	failed := newPriceQuoteResults([]models.PriceQuoteRequest{{AssetType: "stock", Symbol: "2330"}})
+	fillPriceQuotes(failed, []int{0}, func(symbols []string) (map[string]*models.TickerInfo, error) {
+		return nil, errors.New("failed to fetch Taiwan stock data: 503 Service Unavailable")
+	})
+	assert.Equal(t, "failed to fetch Taiwan stock data: 503 Service Unavailable", failed[0].Error)
+}
+
+// MockBatchPriceService is a MockPriceService that also quotes in batches
+type MockBatchPriceService struct {
+	MockPriceService
+}
+
+func (m *MockBatchPriceService) GetPrices(requests []models.PriceQuoteRequest) []models.PriceQuoteResult {
+	args := m.Called(requests)
+	return args.Get(0).([]models.PriceQuoteResult)
+}
+
+func TestCacheDecoratorCoalescesBatchMisses(t *testing.T) {
+	cacheRepo := new(MockPriceCacheRepository)
+	priceService := new(MockBatchPriceService)
+	decorator := NewPriceServiceCacheDecorator(priceService, cacheRepo)
+
+	batching := make(chan struct{})
+	release := make(chan struct{})
+	var misses sync.WaitGroup
+	cacheRepo.On("Get", "crypto", "BTC").Run(func(mock.Arguments) { misses.Done() }).Return(nil, nil)
+	cacheRepo.On("Set", mock.Anything).Return(nil)
+	priceService.On("GetPrices", []models.PriceQuoteRequest{{AssetType: "crypto", Symbol: "BTC"}}).Run(func(mock.Arguments) {
+		close(batching)
+		<-release
+	}).Return([]models.PriceQuoteResult{
+		{AssetType: "crypto", Symbol: "BTC", Quote: &models.TickerInfo{AssetType: "crypto", Symbol: "BTC", Price: 60000, Currency: "USD"}},
+	})
+
+	misses.Add(2)
+	var batchResults []models.PriceQuoteResult
+	var single *models.TickerInfo
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		batchResults = decorator.GetPrices([]models.PriceQuoteRequest{{AssetType: "crypto", Symbol: "btc"}})
+	}()
+	<-batching
+	go func() {
+		defer wg.Done()
+		single, _ = decorator.GetCryptoPrice("BTC")
+	}()
+	// let the single symbol caller reach the cache miss while the batch is in flight
+	misses.Wait()
+	close(release)
+	wg.Wait()
+
+	priceService.AssertNumberOfCalls(t, "GetPrices", 1)
+	priceService.AssertNotCalled(t, "GetCryptoPrice", "BTC")
+	require.Len(t, batchResults, 1)
+	require.NotNil(t, batchResults[0].Quote)
+	assert.Equal(t, 60000.0, batchResults[0].Quote.Price)
+	require.NotNil(t, single)
+	assert.Equal(t, 60000.0, single.Price)
+}
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"asset-diary/models"
+)
+
+// Price providers a chain can name
+const (
+	PriceProviderTWSE    = "twse"
+	PriceProviderFMP     = "fmp"
+	PriceProviderBinance = "binance"
+	PriceProviderGemini  = "gemini"
+)
+
+const (
+	defaultPriceProviderTimeout = 10 * time.Second
+	defaultBreakerThreshold     = 3
+	defaultBreakerCooldown      = time.Minute
+)
+
+// PriceChainInternational is the chain quoting every stock market besides Taiwan and the US
+const PriceChainInternational = "INTL"
+
+// defaultPriceProviderChains keep the upstream used before chains were configurable; Gemini is
+// opt-in since its answers are the least reliable
+var defaultPriceProviderChains = map[string][]string{
+	MarketTW:                {PriceProviderTWSE},
+	MarketUS:                {PriceProviderFMP},
+	PriceChainInternational: {PriceProviderFMP},
+	MarketCrypto:            {PriceProviderBinance},
+}
+
+// priceChainOf returns the chain quoting a symbol of assetType
+func priceChainOf(assetType, symbol string) string {
+	if assetType == "crypto" {
+		return MarketCrypto
+	}
+	if parsed := ParseStockSymbol(symbol); parsed.International() {
+		return PriceChainInternational
+	}
+	return marketOf(assetType, symbol)
+}
+
+// priceProvider is one upstream source. quote fetches a single symbol of the given asset type;
+// batch, when set, fetches several stocks at once.
+type priceProvider struct {
+	name       string
+	assetTypes []string
+	quote      func(assetType, symbol string) (*models.TickerInfo, error)
+	batch      func(symbols []string) (map[string]*models.TickerInfo, error)
+	timeout    time.Duration
+	breaker    *circuitBreaker
+}
+
+func (p *priceProvider) supports(assetType string) bool {
+	for _, t := range p.assetTypes {
+		if t == assetType {
+			return true
+		}
+	}
+	return false
+}
+
+// circuitBreaker skips a provider for a cool-down period after consecutive failures. Once the
+// cool-down ends a single failure opens it again, while a success closes it.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !time.Now().Before(b.openUntil)
+}
+
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil || err.Error() == InvalidSymbolError {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// withTimeout gives up on call after timeout, leaving it to finish in the background. A panic in
+// call is returned as an error since it no longer runs on the request goroutine.
+func withTimeout[T any](name string, timeout time.Duration, call func() (T, error)) (T, error) {
+	type outcome struct {
+		value T
+		err   error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				var zero T
+				done <- outcome{zero, fmt.Errorf("%s failed: %v", name, p)}
+			}
+		}()
+		value, err := call()
+		done <- outcome{value, err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.value, o.err
+	case <-time.After(timeout):
+		var zero T
+		return zero, fmt.Errorf("%s timed out after %s", name, timeout)
+	}
+}
+
+// PriceProviderRegistry quotes each market through an ordered chain of providers, moving to the
+// next provider when one fails or its circuit breaker is open. An invalid symbol is final.
+type PriceProviderRegistry struct {
+	chains map[string][]*priceProvider
+}
+
+// NewPriceProviderRegistry builds the chains from PRICE_PROVIDERS_TW, PRICE_PROVIDERS_US,
+// PRICE_PROVIDERS_INTL and PRICE_PROVIDERS_CRYPTO, each a comma separated list of provider names
+func NewPriceProviderRegistry(assetPriceService *AssetPriceService, geminiService *GeminiAssetPriceService) (*PriceProviderRegistry, error) {
+	threshold := defaultBreakerThreshold
+	if v, err := strconv.Atoi(os.Getenv("PRICE_PROVIDER_FAILURE_THRESHOLD")); err == nil && v > 0 {
+		threshold = v
+	}
+	cooldown := defaultBreakerCooldown
+	if v, err := strconv.Atoi(os.Getenv("PRICE_PROVIDER_COOLDOWN_SECONDS")); err == nil && v > 0 {
+		cooldown = time.Duration(v) * time.Second
+	}
+
+	providers := []*priceProvider{
+		{
+			name:       PriceProviderTWSE,
+			assetTypes: []string{"stock"},
+			quote: func(_, symbol string) (*models.TickerInfo, error) {
+				return assetPriceService.getTaiwanStockPrice(symbol)
+			},
+			batch: assetPriceService.getTaiwanStockPrices,
+		},
+		{
+			name:       PriceProviderFMP,
+			assetTypes: []string{"stock"},
+			quote: func(_, symbol string) (*models.TickerInfo, error) {
+				return assetPriceService.getFMPStockPrice(symbol)
+			},
+			batch: assetPriceService.getFMPStockPrices,
+		},
+		{
+			name:       PriceProviderBinance,
+			assetTypes: []string{"crypto"},
+			quote: func(_, symbol string) (*models.TickerInfo, error) {
+				return assetPriceService.getCryptoPrice(symbol)
+			},
+		},
+		{
+			name:       PriceProviderGemini,
+			assetTypes: []string{"stock", "crypto"},
+			quote: func(assetType, symbol string) (*models.TickerInfo, error) {
+				if assetType == "crypto" {
+					return geminiService.GetCryptoPrice(symbol)
+				}
+				return geminiService.GetStockPrice(symbol)
+			},
+		},
+	}
+	for _, p := range providers {
+		p.timeout = defaultPriceProviderTimeout
+		envTimeout := os.Getenv("PRICE_PROVIDER_" + strings.ToUpper(p.name) + "_TIMEOUT_SECONDS")
+		if v, err := strconv.Atoi(envTimeout); err == nil && v > 0 {
+			p.timeout = time.Duration(v) * time.Second
+		}
+		p.breaker = newCircuitBreaker(threshold, cooldown)
+	}
+
+	chains := make(map[string][]string, len(defaultPriceProviderChains))
+	for market, names := range defaultPriceProviderChains {
+		chains[market] = names
+		if env := os.Getenv("PRICE_PROVIDERS_" + market); env != "" {
+			chains[market] = strings.Split(env, ",")
+		}
+	}
+
+	return newPriceProviderRegistry(providers, chains)
+}
+
+// newPriceProviderRegistry resolves the provider names of each market's chain
+func newPriceProviderRegistry(providers []*priceProvider, chains map[string][]string) (*PriceProviderRegistry, error) {
+	byName := make(map[string]*priceProvider, len(providers))
+	for _, p := range providers {
+		byName[p.name] = p
+	}
+
+	registry := &PriceProviderRegistry{chains: make(map[string][]*priceProvider, len(chains))}
+	for market, names := range chains {
+		assetType := "stock"
+		if market == MarketCrypto {
+			assetType = "crypto"
+		}
+		for _, name := range names {
+			name = strings.ToLower(strings.TrimSpace(name))
+			p, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown price provider %q for market %s", name, market)
+			}
+			if !p.supports(assetType) {
+				return nil, fmt.Errorf("price provider %q does not quote %s", name, assetType)
+			}
+			registry.chains[market] = append(registry.chains[market], p)
+		}
+		if len(registry.chains[market]) == 0 {
+			return nil, fmt.Errorf("no price provider configured for market %s", market)
+		}
+	}
+	return registry, nil
+}
+
+func (r *PriceProviderRegistry) GetStockPrice(symbol string) (*models.TickerInfo, error) {
+	return r.getPrice("stock", strings.ToUpper(strings.TrimSpace(symbol)))
+}
+
+func (r *PriceProviderRegistry) GetCryptoPrice(symbol string) (*models.TickerInfo, error) {
+	if symbol == "" {
+		return nil, fmt.Errorf("symbol is required")
+	}
+	return r.getPrice("crypto", symbol)
+}
+
+// getPrice tries the market's providers in order and returns the first answer
+func (r *PriceProviderRegistry) getPrice(assetType, symbol string) (*models.TickerInfo, error) {
+	market := priceChainOf(assetType, symbol)
+	chain, ok := r.chains[market]
+	if !ok {
+		return nil, errors.New("unsupported asset type")
+	}
+
+	var lastErr error
+	for _, p := range chain {
+		if !p.breaker.allow() {
+			continue
+		}
+		info, err := withTimeout(p.name, p.timeout, func() (*models.TickerInfo, error) {
+			return p.quote(assetType, symbol)
+		})
+		p.breaker.record(err)
+		if err == nil {
+			info.Provider = p.name
+			return info, nil
+		}
+		if err.Error() == InvalidSymbolError {
+			return nil, err
+		}
+		log.Printf("PriceProviderRegistry: %s failed to quote %s: %v", p.name, symbol, err)
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		return nil, fmt.Errorf("no price provider available for market %s", market)
+	}
+	return nil, lastErr
+}
+
+// GetPrices quotes each market through its chain concurrently. Within a chain, the symbols a
+// provider could not quote because of an upstream failure move on to the next provider.
+func (r *PriceProviderRegistry) GetPrices(requests []models.PriceQuoteRequest) []models.PriceQuoteResult {
+	results := newPriceQuoteResults(requests)
+
+	byMarket := make(map[string][]int)
+	for i, result := range results {
+		if result.AssetType != "stock" && result.AssetType != "crypto" {
+			results[i].Error = "unsupported asset type"
+			continue
+		}
+		market := priceChainOf(result.AssetType, result.Symbol)
+		byMarket[market] = append(byMarket[market], i)
+	}
+
+	var wg sync.WaitGroup
+	for market, indices := range byMarket {
+		wg.Add(1)
+		go func(market string, indices []int) {
+			defer wg.Done()
+			r.quoteChain(results, market, indices)
+		}(market, indices)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func (r *PriceProviderRegistry) quoteChain(results []models.PriceQuoteResult, market string, pending []int) {
+	attempted := false
+	for _, p := range r.chains[market] {
+		if len(pending) == 0 {
+			return
+		}
+		if !p.breaker.allow() {
+			continue
+		}
+		attempted = true
+		for _, i := range pending {
+			results[i].Error = ""
+		}
+
+		if p.batch != nil {
+			fillPriceQuotes(results, pending, func(symbols []string) (map[string]*models.TickerInfo, error) {
+				quotes, err := withTimeout(p.name, p.timeout, func() (map[string]*models.TickerInfo, error) {
+					return p.batch(symbols)
+				})
+				p.breaker.record(err)
+				return quotes, err
+			})
+		} else {
+			var wg sync.WaitGroup
+			for _, i := range pending {
+				wg.Add(1)
+				go func(result *models.PriceQuoteResult) {
+					defer wg.Done()
+					info, err := withTimeout(p.name, p.timeout, func() (*models.TickerInfo, error) {
+						return p.quote(result.AssetType, result.Symbol)
+					})
+					p.breaker.record(err)
+					setPriceQuote(result, info, err)
+				}(&results[i])
+			}
+			wg.Wait()
+		}
+
+		var failed []int
+		for _, i := range pending {
+			switch {
+			case results[i].Quote != nil:
+				results[i].Quote.Provider = p.name
+			case results[i].Error != InvalidSymbolError:
+				failed = append(failed, i)
+			}
+		}
+		pending = failed
+	}
+
+	if !attempted {
+		for _, i := range pending {
+			results[i].Error = fmt.Sprintf("no price provider available for market %s", market)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"asset-diary/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubPriceProvider answers every stock quote with price or err and counts the calls
+func stubPriceProvider(name string, price float64, err error, calls *int) *priceProvider {
+	return &priceProvider{
+		name:       name,
+		assetTypes: []string{"stock"},
+		quote: func(assetType, symbol string) (*models.TickerInfo, error) {
+			*calls++
+			if err != nil {
+				return nil, err
+			}
+			return &models.TickerInfo{AssetType: assetType, Symbol: symbol, Price: price}, nil
+		},
+		timeout: time.Second,
+		breaker: newCircuitBreaker(2, time.Minute),
+	}
+}
+
+func TestPriceProviderRegistryFallsBackAndOpensBreaker(t *testing.T) {
+	var primaryCalls, backupCalls int
+	primary := stubPriceProvider(PriceProviderFMP, 0, errors.New("failed to fetch US stock data: 503 Service Unavailable"), &primaryCalls)
+	backup := stubPriceProvider(PriceProviderGemini, 200, nil, &backupCalls)
+	registry, err := newPriceProviderRegistry([]*priceProvider{primary, backup}, map[string][]string{
+		MarketUS: {"fmp", "gemini"},
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		info, err := registry.GetStockPrice("aapl")
+		require.NoError(t, err)
+		assert.Equal(t, 200.0, info.Price)
+		assert.Equal(t, PriceProviderGemini, info.Provider)
+	}
+
+	// the breaker opens after two failures, so the third quote skips the primary
+	assert.Equal(t, 2, primaryCalls)
+	assert.Equal(t, 3, backupCalls)
+}
+
+func TestPriceProviderRegistryInvalidSymbolIsFinal(t *testing.T) {
+	var primaryCalls, backupCalls int
+	primary := stubPriceProvider(PriceProviderFMP, 0, errors.New(InvalidSymbolError), &primaryCalls)
+	backup := stubPriceProvider(PriceProviderGemini, 200, nil, &backupCalls)
+	registry, err := newPriceProviderRegistry([]*priceProvider{primary, backup}, map[string][]string{
+		MarketUS: {"fmp", "gemini"},
+	})
+	require.NoError(t, err)
+
+	_, err = registry.GetStockPrice("NOPE")
+
+	assert.EqualError(t, err, InvalidSymbolError)
+	assert.Equal(t, 0, backupCalls)
+	assert.True(t, primary.breaker.allow())
+}
+
+func TestPriceProviderRegistryTimeout(t *testing.T) {
+	slow := &priceProvider{
+		name:       PriceProviderTWSE,
+		assetTypes: []string{"stock"},
+		quote: func(assetType, symbol string) (*models.TickerInfo, error) {
+			time.Sleep(time.Second)
+			return &models.TickerInfo{Symbol: symbol}, nil
+		},
+		timeout: 10 * time.Millisecond,
+		breaker: newCircuitBreaker(3, time.Minute),
+	}
+	registry, err := newPriceProviderRegistry([]*priceProvider{slow}, map[string][]string{MarketTW: {"twse"}})
+	require.NoError(t, err)
+
+	_, err = registry.GetStockPrice("2330")
+
+	assert.EqualError(t, err, "twse timed out after 10ms")
+}
+
+func TestPriceProviderRegistryGetPrices(t *testing.T) {
+	var batchCalls, backupCalls int
+	twse := &priceProvider{
+		name:       PriceProviderTWSE,
+		assetTypes: []string{"stock"},
+		batch: func(symbols []string) (map[string]*models.TickerInfo, error) {
+			batchCalls++
+			return map[string]*models.TickerInfo{"2330": {Symbol: "2330", Price: 1000}}, nil
+		},
+		timeout: time.Second,
+		breaker: newCircuitBreaker(3, time.Minute),
+	}
+	var primaryCalls int
+	fmp := stubPriceProvider(PriceProviderFMP, 0, errors.New("failed to fetch US stock data: 429 Too Many Requests"), &primaryCalls)
+	fmp.batch = func(symbols []string) (map[string]*models.TickerInfo, error) {
+		return nil, errors.New("failed to fetch US stock data: 429 Too Many Requests")
+	}
+	backup := stubPriceProvider(PriceProviderGemini, 200, nil, &backupCalls)
+	registry, err := newPriceProviderRegistry([]*priceProvider{twse, fmp, backup}, map[string][]string{
+		MarketTW: {"twse"},
+		MarketUS: {"fmp", "gemini"},
+	})
+	require.NoError(t, err)
+
+	results := registry.GetPrices([]models.PriceQuoteRequest{
+		{AssetType: "stock", Symbol: "2330"},
+		{AssetType: "stock", Symbol: "1234"},
+		{AssetType: "stock", Symbol: "AAPL"},
+		{AssetType: "crypto", Symbol: "BTC"},
+	})
+
+	require.Len(t, results, 4)
+	assert.Equal(t, PriceProviderTWSE, results[0].Quote.Provider)
+	assert.Equal(t, InvalidSymbolError, results[1].Error)
+	assert.Equal(t, 200.0, results[2].Quote.Price)
+	assert.Equal(t, PriceProviderGemini, results[2].Quote.Provider)
+	assert.Empty(t, results[2].Error)
+	assert.Equal(t, "no price provider available for market CRYPTO", results[3].Error)
+	assert.Equal(t, 1, batchCalls)
+}
+
+func TestNewPriceProviderRegistryRejectsBadChains(t *testing.T) {
+	var calls int
+	providers := []*priceProvider{stubPriceProvider(PriceProviderFMP, 0, nil, &calls)}
+
+	_, err := newPriceProviderRegistry(providers, map[string][]string{MarketUS: {"yahoo"}})
+	assert.EqualError(t, err, `unknown price provider "yahoo" for market US`)
+
+	_, err = newPriceProviderRegistry(providers, map[string][]string{MarketCrypto: {"fmp"}})
+	assert.EqualError(t, err, `price provider "fmp" does not quote crypto`)
+}
@@ -1,6 +1,8 @@
 package services
 
 import (
+	"time"
+
 	"asset-diary/models"
 	"asset-diary/repositories"
 )
@@ -10,6 +12,7 @@ type ProfileServiceInterface interface {
 	ChangePassword(userID string, currentPassword, newPassword string) error
 	UpdateProfile(userID string, req *models.UserUpdateRequest) (*models.Profile, error)
 	GetDefaultCurrency(userID string) (string, error)
+	GetLocation(userID string) (*time.Location, error)
 }
 
 type ProfileService struct {
@@ -31,6 +34,11 @@ func (s *ProfileService) ChangePassword(userID string, currentPassword, newPassw
 }
 
 func (s *ProfileService) UpdateProfile(userID string, req *models.UserUpdateRequest) (*models.Profile, error) {
+	if req.InvestmentProfile != nil && req.InvestmentProfile.Timezone != "" {
+		if _, err := time.LoadLocation(req.InvestmentProfile.Timezone); err != nil {
+			return nil, models.NewAppError(models.ErrCodeInvalidRequest, "Unknown timezone, use an IANA name such as Asia/Taipei")
+		}
+	}
 	return s.repo.UpdateProfile(userID, req)
 }
 
@@ -45,3 +53,23 @@ func (s *ProfileService) GetDefaultCurrency(userID string) (string, error) {
 	}
 	return profile.InvestmentProfile.DefaultCurrency, nil
 }
+
+// GetLocation returns the user's timezone, which decides the calendar day of snapshots and dates
+func (s *ProfileService) GetLocation(userID string) (*time.Location, error) {
+	profile, err := s.GetProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	timezone := models.DefaultTimezone
+	if profile.InvestmentProfile != nil && profile.InvestmentProfile.Timezone != "" {
+		timezone = profile.InvestmentProfile.Timezone
+	}
+	return time.LoadLocation(timezone)
+}
+
+// LocalDate returns the calendar date t falls on in loc, as midnight UTC like the stored dates
+func LocalDate(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,465 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+
+	"asset-diary/models"
+	"asset-diary/repositories"
+)
+
+const defaultRebalanceTolerance = 5.0
+
+// feeSchedule holds the trading rules of a market used to turn rebalancing amounts into orders
+type feeSchedule struct {
+	LotSize        float64
+	CommissionRate float64
+	MinCommission  float64
+	SellTaxRate    float64
+}
+
+// feeSchedules are typical retail rates; they only feed estimates. Taiwan stocks trade in board
+// lots of 1000 shares with a 0.1425% commission (minimum NT$20) and 0.3% transaction tax on sells.
+var feeSchedules = map[string]feeSchedule{
+	MarketTW:     {LotSize: 1000, CommissionRate: 0.001425, MinCommission: 20, SellTaxRate: 0.003},
+	MarketUS:     {LotSize: 1},
+	MarketCrypto: {LotSize: 0.000001, CommissionRate: 0.001},
+}
+
+func feeScheduleFor(market string, oddLots bool) feeSchedule {
+	schedule := feeSchedules[market]
+	if schedule.LotSize == 0 || (oddLots && market == MarketTW) {
+		schedule.LotSize = 1
+	}
+	return schedule
+}
+
+func (f feeSchedule) estimateFee(notional float64, sell bool) float64 {
+	if notional <= 0 {
+		return 0
+	}
+	fee := 0.0
+	if f.CommissionRate > 0 {
+		fee = math.Max(notional*f.CommissionRate, f.MinCommission)
+	}
+	if sell {
+		fee += notional * f.SellTaxRate
+	}
+	return fee
+}
+
+type RebalanceServiceInterface interface {
+	ListTargets(userID string) ([]models.AllocationTarget, error)
+	SetTargets(userID string, req models.AllocationTargetsUpdateRequest) ([]models.AllocationTarget, error)
+	GetRebalance(userID string, newCash float64, oddLots bool) (*models.RebalanceReport, error)
+}
+
+type RebalanceService struct {
+	repo        repositories.AllocationTargetRepositoryInterface
+	holdingSvc  HoldingServiceInterface
+	accountSvc  AccountServiceInterface
+	tradeSvc    TradeServiceInterface
+	tagSvc      TagServiceInterface
+	profileSvc  ProfileServiceInterface
+	exchangeSvc ExchangeRateServiceInterface
+}
+
+func NewRebalanceService(
+	repo repositories.AllocationTargetRepositoryInterface,
+	holdingSvc HoldingServiceInterface,
+	accountSvc AccountServiceInterface,
+	tradeSvc TradeServiceInterface,
+	tagSvc TagServiceInterface,
+	profileSvc ProfileServiceInterface,
+	exchangeSvc ExchangeRateServiceInterface,
+) *RebalanceService {
+	return &RebalanceService{
+		repo:        repo,
+		holdingSvc:  holdingSvc,
+		accountSvc:  accountSvc,
+		tradeSvc:    tradeSvc,
+		tagSvc:      tagSvc,
+		profileSvc:  profileSvc,
+		exchangeSvc: exchangeSvc,
+	}
+}
+
+func (s *RebalanceService) ListTargets(userID string) ([]models.AllocationTarget, error) {
+	return s.repo.ListTargets(userID)
+}
+
+// SetTargets validates and replaces the user's targets. Keys are normalized: asset types are
+// lower-cased, tickers upper-cased and tags resolved by id or name to their id.
+func (s *RebalanceService) SetTargets(userID string, req models.AllocationTargetsUpdateRequest) ([]models.AllocationTarget, error) {
+	defaultTolerance := defaultRebalanceTolerance
+	if req.Tolerance != nil {
+		defaultTolerance = *req.Tolerance
+	}
+
+	var tags []models.Tag
+	if req.TargetType == models.AllocationTargetTypeTag {
+		var err error
+		if tags, err = s.tagSvc.ListTags(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	targets := make([]models.AllocationTarget, 0, len(req.Targets))
+	seen := make(map[string]bool, len(req.Targets))
+	totalWeight := 0.0
+	for _, input := range req.Targets {
+		key, err := normalizeTargetKey(req.TargetType, input.Key, tags)
+		if err != nil {
+			return nil, err
+		}
+		if seen[key] {
+			return nil, models.NewAppError(models.ErrCodeInvalidRequest, fmt.Sprintf("Duplicate target: %s", input.Key))
+		}
+		seen[key] = true
+
+		tolerance := defaultTolerance
+		if input.Tolerance != nil {
+			tolerance = *input.Tolerance
+		}
+		totalWeight += input.Weight
+		targets = append(targets, models.AllocationTarget{
+			TargetType: req.TargetType,
+			TargetKey:  key,
+			Weight:     input.Weight,
+			Tolerance:  tolerance,
+		})
+	}
+
+	if totalWeight > 100+1e-9 {
+		return nil, models.NewAppError(models.ErrCodeInvalidRequest, "Target weights must not add up to more than 100")
+	}
+
+	if err := s.repo.ReplaceTargets(userID, targets); err != nil {
+		return nil, err
+	}
+	return s.repo.ListTargets(userID)
+}
+
+func normalizeTargetKey(targetType, key string, tags []models.Tag) (string, error) {
+	key = strings.TrimSpace(key)
+	switch targetType {
+	case models.AllocationTargetTypeAssetType:
+		key = strings.ToLower(key)
+		if key != "stock" && key != "crypto" && key != AssetTypeCash {
+			return "", models.NewAppError(models.ErrCodeInvalidRequest, fmt.Sprintf("Unknown asset type: %s", key))
+		}
+		return key, nil
+	case models.AllocationTargetTypeTicker:
+		return strings.ToUpper(key), nil
+	default:
+		for _, tag := range tags {
+			if tag.ID == key || strings.EqualFold(tag.Name, key) {
+				return tag.ID, nil
+			}
+		}
+		return "", models.NewAppError(models.ErrCodeInvalidRequest, fmt.Sprintf("Unknown tag: %s", key))
+	}
+}
+
+// GetRebalance compares the current allocation with the user's targets and suggests orders for
+// every target that drifted outside its tolerance band. newCash is added to the cash balance
+// before the plan is made.
+func (s *RebalanceService) GetRebalance(userID string, newCash float64, oddLots bool) (*models.RebalanceReport, error) {
+	targets, err := s.repo.ListTargets(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, models.NewAppError(models.ErrCodeInvalidRequest, "No allocation targets set")
+	}
+
+	defaultCurrency, err := s.profileSvc.GetDefaultCurrency(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user profile: %w", err)
+	}
+
+	rates, err := s.exchangeSvc.GetRatesByBaseCurrency(defaultCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rates: %w", err)
+	}
+
+	holdings, err := s.holdingSvc.ListHoldings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.accountSvc.ListAccounts(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := rebalancePlan{
+		defaultCurrency: defaultCurrency,
+		rates:           rates,
+		holdings:        holdings,
+		groupHoldings:   make(map[string][]models.Holding, len(targets)),
+		groupNames:      make(map[string]string, len(targets)),
+		newCash:         newCash,
+		oddLots:         oddLots,
+		unconverted:     map[string]bool{},
+	}
+	for _, account := range accounts {
+		if value, ok := plan.toDefault(account.Balance, account.Currency); ok {
+			plan.cash += value
+		}
+	}
+
+	targetType := targets[0].TargetType
+	switch targetType {
+	case models.AllocationTargetTypeTag:
+		tags, err := s.tagSvc.ListTags(userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			plan.groupNames[tag.ID] = tag.Name
+		}
+		trades, err := s.tradeSvc.ListTrades(userID)
+		if err != nil {
+			return nil, err
+		}
+		plan.groupHoldings = holdingsByTag(holdings, trades)
+	default:
+		for _, holding := range holdings {
+			key := strings.ToUpper(holding.Ticker)
+			if targetType == models.AllocationTargetTypeAssetType {
+				key = holding.AssetType
+			}
+			plan.groupHoldings[key] = append(plan.groupHoldings[key], holding)
+		}
+	}
+
+	return plan.build(targets), nil
+}
+
+// rebalancePlan holds the inputs of a rebalancing calculation, with all values in the default currency
+type rebalancePlan struct {
+	defaultCurrency string
+	rates           map[string]float64
+	holdings        []models.Holding
+	groupHoldings   map[string][]models.Holding
+	groupNames      map[string]string
+	cash            float64
+	newCash         float64
+	oddLots         bool
+	unconverted     map[string]bool
+}
+
+func (p *rebalancePlan) toDefault(amount float64, currency string) (float64, bool) {
+	value, ok := convertWithRates(amount, currency, p.defaultCurrency, p.rates)
+	if !ok {
+		log.Printf("No exchange rate found for %s to %s", currency, p.defaultCurrency)
+		p.unconverted[currency] = true
+	}
+	return value, ok
+}
+
+// fromDefault converts a default-currency value into the given currency, the inverse of toDefault
+func (p *rebalancePlan) fromDefault(value float64, currency string) (float64, bool) {
+	unit, ok := convertWithRates(1, currency, p.defaultCurrency, p.rates)
+	if !ok || unit <= 0 {
+		return 0, false
+	}
+	return value / unit, true
+}
+
+// positionDelta is the default-currency amount to buy (positive) or sell (negative) of one position
+type positionDelta struct {
+	holding models.Holding
+	value   float64
+}
+
+func (p *rebalancePlan) build(targets []models.AllocationTarget) *models.RebalanceReport {
+	report := &models.RebalanceReport{
+		Currency:              p.defaultCurrency,
+		TargetType:            targets[0].TargetType,
+		NewCash:               p.newCash,
+		Groups:                []models.RebalanceGroup{},
+		Orders:                []models.RebalanceOrder{},
+		UnconvertedCurrencies: []string{},
+	}
+
+	total := p.cash + p.newCash
+	for _, holding := range p.holdings {
+		if value, ok := p.toDefault(holding.TotalValue, holding.Currency); ok {
+			total += value
+		}
+	}
+	report.TotalValue = total
+	report.CashBefore = p.cash
+
+	deltas := map[string]*positionDelta{}
+	var deltaKeys []string
+	for _, target := range targets {
+		isCash := target.TargetType == models.AllocationTargetTypeAssetType && target.TargetKey == AssetTypeCash
+
+		group := models.RebalanceGroup{
+			Key:              target.TargetKey,
+			Name:             target.TargetKey,
+			TargetPercentage: target.Weight,
+			Tolerance:        target.Tolerance,
+			TargetValue:      total * target.Weight / 100,
+		}
+		if name, ok := p.groupNames[target.TargetKey]; ok {
+			group.Name = name
+		}
+
+		type weightedHolding struct {
+			holding models.Holding
+			value   float64
+		}
+		var members []weightedHolding
+		if isCash {
+			group.CurrentValue = p.cash + p.newCash
+		}
+		for _, holding := range p.groupHoldings[target.TargetKey] {
+			value, ok := p.toDefault(holding.TotalValue, holding.Currency)
+			if !ok {
+				continue
+			}
+			group.CurrentValue += value
+			members = append(members, weightedHolding{holding: holding, value: value})
+		}
+
+		if total > 0 {
+			group.CurrentPercentage = group.CurrentValue / total * 100
+		}
+		group.Drift = group.CurrentPercentage - group.TargetPercentage
+		group.WithinTolerance = math.Abs(group.Drift) <= group.Tolerance+1e-9
+		if !group.WithinTolerance {
+			group.AdjustmentValue = group.TargetValue - group.CurrentValue
+		}
+		report.Groups = append(report.Groups, group)
+
+		// Cash is what is left after the orders, and a group without positions has nothing to
+		// size an order against, so both only report their adjustment
+		if isCash || group.AdjustmentValue == 0 || group.CurrentValue <= 0 {
+			continue
+		}
+		for _, member := range members {
+			key := fmt.Sprintf("%s_%s_%s", member.holding.AssetType, strings.ToUpper(member.holding.Ticker), member.holding.Currency)
+			delta, ok := deltas[key]
+			if !ok {
+				delta = &positionDelta{holding: member.holding}
+				deltas[key] = delta
+				deltaKeys = append(deltaKeys, key)
+			}
+			delta.value += group.AdjustmentValue * member.value / group.CurrentValue
+		}
+	}
+
+	cash := p.cash + p.newCash
+	var sells, buys []models.RebalanceOrder
+	for _, key := range deltaKeys {
+		if delta := deltas[key]; delta.value < 0 {
+			if order, ok := p.order(delta.holding, -delta.value, true); ok {
+				sells = append(sells, order)
+				cash += order.AmountInDefaultCurrency
+			}
+		}
+	}
+
+	// Buys are funded from cash, so scale them down when the available cash falls short
+	requested := 0.0
+	for _, key := range deltaKeys {
+		if delta := deltas[key]; delta.value > 0 {
+			requested += delta.value
+		}
+	}
+	scale := 1.0
+	if requested > cash {
+		scale = math.Max(cash, 0) / requested
+	}
+	for _, key := range deltaKeys {
+		if delta := deltas[key]; delta.value > 0 {
+			if order, ok := p.order(delta.holding, delta.value*scale, false); ok {
+				buys = append(buys, order)
+				cash -= order.AmountInDefaultCurrency
+			}
+		}
+	}
+
+	for _, orders := range [][]models.RebalanceOrder{sells, buys} {
+		sort.Slice(orders, func(i, j int) bool {
+			return orders[i].AmountInDefaultCurrency > orders[j].AmountInDefaultCurrency
+		})
+		for _, order := range orders {
+			if feeValue, ok := p.toDefault(order.EstimatedFee, order.Currency); ok {
+				report.EstimatedFees += feeValue
+			}
+		}
+		report.Orders = append(report.Orders, orders...)
+	}
+	report.CashAfter = cash
+
+	for currency := range p.unconverted {
+		report.UnconvertedCurrencies = append(report.UnconvertedCurrencies, currency)
+	}
+	sort.Strings(report.UnconvertedCurrencies)
+
+	return report
+}
+
+// order sizes a trade worth up to value in the default currency, rounded to the market's lot size.
+// Buys round down so that price and fees fit in value; sells round to the nearest lot and never
+// exceed the held quantity. AmountInDefaultCurrency is the net cash moved: proceeds after fees
+// for sells, cost including fees for buys.
+func (p *rebalancePlan) order(holding models.Holding, value float64, sell bool) (models.RebalanceOrder, bool) {
+	if holding.Price <= 0 {
+		return models.RebalanceOrder{}, false
+	}
+	amount, ok := p.fromDefault(value, holding.Currency)
+	if !ok {
+		return models.RebalanceOrder{}, false
+	}
+
+	market := marketOf(holding.AssetType, holding.Ticker)
+	schedule := feeScheduleFor(market, p.oddLots)
+
+	var quantity float64
+	if sell {
+		quantity = math.Round(amount/holding.Price/schedule.LotSize) * schedule.LotSize
+		if quantity > holding.Quantity {
+			quantity = holding.Quantity
+		}
+	} else {
+		quantity = math.Floor(amount/(holding.Price*(1+schedule.CommissionRate))/schedule.LotSize+1e-9) * schedule.LotSize
+	}
+	quantity = math.Round(quantity*1e8) / 1e8
+	if quantity <= 0 {
+		return models.RebalanceOrder{}, false
+	}
+
+	notional := quantity * holding.Price
+	fee := schedule.estimateFee(notional, sell)
+	side := "buy"
+	cashMoved := notional + fee
+	if sell {
+		side = "sell"
+		cashMoved = notional - fee
+	}
+	cashMovedDefault, _ := p.toDefault(cashMoved, holding.Currency)
+
+	return models.RebalanceOrder{
+		Ticker:                  holding.Ticker,
+		TickerName:              holding.TickerName,
+		AssetType:               holding.AssetType,
+		Market:                  market,
+		Side:                    side,
+		Quantity:                quantity,
+		LotSize:                 schedule.LotSize,
+		Price:                   holding.Price,
+		Currency:                holding.Currency,
+		Amount:                  notional,
+		EstimatedFee:            fee,
+		AmountInDefaultCurrency: cashMovedDefault,
+	}, true
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"testing"
+
+	"asset-diary/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRebalancePlan(holdings []models.Holding, cash float64, oddLots bool) *rebalancePlan {
+	plan := &rebalancePlan{
+		defaultCurrency: "TWD",
+		rates:           map[string]float64{"USD": 0.03125},
+		holdings:        holdings,
+		groupHoldings:   map[string][]models.Holding{},
+		groupNames:      map[string]string{},
+		cash:            cash,
+		oddLots:         oddLots,
+		unconverted:     map[string]bool{},
+	}
+	for _, holding := range holdings {
+		plan.groupHoldings[holding.AssetType] = append(plan.groupHoldings[holding.AssetType], holding)
+	}
+	return plan
+}
+
+func assetTypeTargets(weights map[string]float64) []models.AllocationTarget {
+	targets := []models.AllocationTarget{}
+	for _, key := range []string{"stock", "crypto", "cash"} {
+		if weight, ok := weights[key]; ok {
+			targets = append(targets, models.AllocationTarget{
+				TargetType: models.AllocationTargetTypeAssetType,
+				TargetKey:  key,
+				Weight:     weight,
+				Tolerance:  5,
+			})
+		}
+	}
+	return targets
+}
+
+var rebalanceHoldings = []models.Holding{
+	{Ticker: "2330", AssetType: "stock", Currency: "TWD", Quantity: 2000, Price: 1000, TotalValue: 2000000},
+	{Ticker: "BTC", AssetType: "crypto", Currency: "USD", Quantity: 1, Price: 50000, TotalValue: 50000},
+}
+
+func TestRebalancePlan(t *testing.T) {
+	plan := newTestRebalancePlan(rebalanceHoldings, 400000, true)
+
+	report := plan.build(assetTypeTargets(map[string]float64{"stock": 60, "crypto": 30, "cash": 10}))
+
+	assert.InDelta(t, 4000000, report.TotalValue, 1e-6)
+	assert.Len(t, report.Groups, 3)
+	assert.InDelta(t, -10, report.Groups[0].Drift, 1e-9)
+	assert.False(t, report.Groups[0].WithinTolerance)
+	assert.InDelta(t, 400000, report.Groups[0].AdjustmentValue, 1e-6)
+	assert.InDelta(t, 10, report.Groups[1].Drift, 1e-9)
+	assert.True(t, report.Groups[2].WithinTolerance)
+	assert.Zero(t, report.Groups[2].AdjustmentValue)
+
+	assert.Len(t, report.Orders, 2)
+	sell, buy := report.Orders[0], report.Orders[1]
+
+	assert.Equal(t, "sell", sell.Side)
+	assert.Equal(t, "BTC", sell.Ticker)
+	assert.InDelta(t, 0.25, sell.Quantity, 1e-9)
+	assert.InDelta(t, 12.5, sell.EstimatedFee, 1e-9)
+	assert.InDelta(t, 399600, sell.AmountInDefaultCurrency, 1e-6)
+
+	assert.Equal(t, "buy", buy.Side)
+	assert.Equal(t, "2330", buy.Ticker)
+	assert.Equal(t, float64(1), buy.LotSize)
+	assert.InDelta(t, 399, buy.Quantity, 1e-9)
+	assert.InDelta(t, 568.575, buy.EstimatedFee, 1e-9)
+
+	assert.InDelta(t, 968.575, report.EstimatedFees, 1e-6)
+	assert.InDelta(t, 400031.425, report.CashAfter, 1e-6)
+}
+
+func TestRebalancePlanBoardLots(t *testing.T) {
+	plan := newTestRebalancePlan(rebalanceHoldings, 400000, false)
+
+	report := plan.build(assetTypeTargets(map[string]float64{"stock": 60, "crypto": 30, "cash": 10}))
+
+	// 400 shares is less than one board lot of 2330, so only the crypto sell remains
+	assert.Len(t, report.Orders, 1)
+	assert.Equal(t, "sell", report.Orders[0].Side)
+}
+
+func TestRebalancePlanLimitsBuysToCash(t *testing.T) {
+	plan := newTestRebalancePlan(rebalanceHoldings[:1], 100000, true)
+	plan.newCash = 100000
+
+	// Stock is at 2,000,000 of 2,200,000; all of it should be stock but only 200,000 cash is available
+	report := plan.build(assetTypeTargets(map[string]float64{"stock": 100}))
+
+	assert.Len(t, report.Orders, 1)
+	assert.Equal(t, "buy", report.Orders[0].Side)
+	assert.InDelta(t, 199, report.Orders[0].Quantity, 1e-9)
+	assert.GreaterOrEqual(t, report.CashAfter, 0.0)
+}
+
+func TestNormalizeTargetKey(t *testing.T) {
+	tags := []models.Tag{{ID: "tag-1", Name: "Dividend"}}
+
+	key, err := normalizeTargetKey(models.AllocationTargetTypeAssetType, " Stock ", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "stock", key)
+
+	_, err = normalizeTargetKey(models.AllocationTargetTypeAssetType, "bond", nil)
+	assert.Error(t, err)
+
+	key, _ = normalizeTargetKey(models.AllocationTargetTypeTicker, "aapl", nil)
+	assert.Equal(t, "AAPL", key)
+
+	key, err = normalizeTargetKey(models.AllocationTargetTypeTag, "dividend", tags)
+	assert.NoError(t, err)
+	assert.Equal(t, "tag-1", key)
+}
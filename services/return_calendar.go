@@ -0,0 +1,165 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"asset-diary/models"
+)
+
+// GetReturnCalendar returns the daily, monthly and yearly time-weighted returns of the snapshots
+// within the period, with best and worst days and winning and losing streaks. Deposits,
+// withdrawals and trades are removed from the returns.
+func (s *PerformanceService) GetReturnCalendar(userID string, startDate, endDate time.Time) (*models.ReturnCalendar, error) {
+	defaultCurrency, err := s.profileSvc.GetDefaultCurrency(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user profile: %w", err)
+	}
+
+	snapshots, err := s.dailyAssetSvc.GetUserDailyTotalAssetValues(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	flows, err := loadPortfolioCashFlows(s.accountSvc, s.tradeSvc, s.exchangeSvc, userID, defaultCurrency, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Date.Before(snapshots[j].Date)
+	})
+	values := make([]cashFlow, len(snapshots))
+	for i, snapshot := range snapshots {
+		values[i] = cashFlow{Date: snapshot.Date, Amount: snapshot.TotalValue}
+	}
+
+	calendar := returnCalendar(values, flows)
+	calendar.StartDate = startDate
+	calendar.EndDate = endDate
+	calendar.Currency = defaultCurrency
+	return calendar, nil
+}
+
+// periodGrowth compounds returns per period, keeping the periods in the order first seen
+type periodGrowth struct {
+	keys   []string
+	growth map[string]float64
+	days   map[string]int
+}
+
+func newPeriodGrowth() *periodGrowth {
+	return &periodGrowth{growth: map[string]float64{}, days: map[string]int{}}
+}
+
+func (p *periodGrowth) add(key string, r float64) {
+	if _, ok := p.growth[key]; !ok {
+		p.keys = append(p.keys, key)
+		p.growth[key] = 1
+	}
+	p.growth[key] *= 1 + r
+	p.days[key]++
+}
+
+func (p *periodGrowth) returns() []models.PeriodReturn {
+	result := make([]models.PeriodReturn, 0, len(p.keys))
+	for _, key := range p.keys {
+		result = append(result, models.PeriodReturn{Period: key, Days: p.days[key], ReturnPercentage: percentage(p.growth[key] - 1)})
+	}
+	return result
+}
+
+// returnCalendar derives the daily returns of sorted snapshot values from their flow-adjusted
+// index. A return after a gap in the snapshots belongs to the date it ends on; a zero return or a
+// day without value ends a streak.
+func returnCalendar(values []cashFlow, flows []cashFlow) *models.ReturnCalendar {
+	calendar := &models.ReturnCalendar{
+		Daily:   make([]models.DailyReturn, 0, len(values)),
+		Monthly: []models.PeriodReturn{},
+		Yearly:  []models.PeriodReturn{},
+	}
+	if len(values) == 0 {
+		return calendar
+	}
+
+	index, _ := timeWeightedIndex(values, flows)
+	months := newPeriodGrowth()
+	years := newPeriodGrowth()
+
+	var bestReturn, worstReturn float64
+	var streak *models.ReturnStreak
+	streakGrowth := 1.0
+	endStreak := func() {
+		if streak == nil {
+			return
+		}
+		streak.ReturnPercentage = percentage(streakGrowth - 1)
+		longest := &calendar.LongestWinningStreak
+		if streak.Type == models.ReturnStreakLosing {
+			longest = &calendar.LongestLosingStreak
+		}
+		if *longest == nil || streak.Days > (*longest).Days {
+			*longest = streak
+		}
+		streak = nil
+	}
+
+	for i, value := range values {
+		day := models.DailyReturn{Date: value.Date, Value: value.Amount}
+		if i == 0 || values[i-1].Amount <= 0 {
+			calendar.Daily = append(calendar.Daily, day)
+			endStreak()
+			continue
+		}
+
+		r := index[i]/index[i-1] - 1
+		day.ReturnPercentage = percentage(r)
+		calendar.Daily = append(calendar.Daily, day)
+		months.add(value.Date.Format("2006-01"), r)
+		years.add(value.Date.Format("2006"), r)
+
+		if calendar.BestDay == nil || r > bestReturn {
+			best := day
+			calendar.BestDay, bestReturn = &best, r
+		}
+		if calendar.WorstDay == nil || r < worstReturn {
+			worst := day
+			calendar.WorstDay, worstReturn = &worst, r
+		}
+
+		streakType := ""
+		switch {
+		case r > 0:
+			calendar.PositiveDays++
+			streakType = models.ReturnStreakWinning
+		case r < 0:
+			calendar.NegativeDays++
+			streakType = models.ReturnStreakLosing
+		}
+		if streak != nil && streak.Type != streakType {
+			endStreak()
+		}
+		if streakType == "" {
+			continue
+		}
+		if streak == nil {
+			streak = &models.ReturnStreak{Type: streakType, StartDate: value.Date}
+			streakGrowth = 1
+		}
+		streak.EndDate = value.Date
+		streak.Days++
+		streakGrowth *= 1 + r
+	}
+
+	if streak != nil {
+		current := *streak
+		current.ReturnPercentage = percentage(streakGrowth - 1)
+		calendar.CurrentStreak = &current
+	}
+	endStreak()
+
+	calendar.Monthly = months.returns()
+	calendar.Yearly = years.returns()
+	return calendar
+}
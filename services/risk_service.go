@@ -0,0 +1,174 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"asset-diary/models"
+)
+
+type RiskServiceInterface interface {
+	GetRisk(userID string, startDate, endDate time.Time, riskFreeRate float64) (*models.RiskReport, error)
+}
+
+type RiskService struct {
+	dailyAssetSvc DailyTotalAssetValueServiceInterface
+	accountSvc    AccountServiceInterface
+	tradeSvc      TradeServiceInterface
+	profileSvc    ProfileServiceInterface
+	exchangeSvc   ExchangeRateServiceInterface
+}
+
+func NewRiskService(
+	dailyAssetSvc DailyTotalAssetValueServiceInterface,
+	accountSvc AccountServiceInterface,
+	tradeSvc TradeServiceInterface,
+	profileSvc ProfileServiceInterface,
+	exchangeSvc ExchangeRateServiceInterface,
+) *RiskService {
+	return &RiskService{
+		dailyAssetSvc: dailyAssetSvc,
+		accountSvc:    accountSvc,
+		tradeSvc:      tradeSvc,
+		profileSvc:    profileSvc,
+		exchangeSvc:   exchangeSvc,
+	}
+}
+
+// GetRisk computes risk metrics over the period and checks the drawdowns against the maximum
+// short-term loss the user stated in their investment profile. riskFreeRate is an annual percentage.
+// A zero endDate means the user's local today and a zero startDate the year before endDate.
+func (s *RiskService) GetRisk(userID string, startDate, endDate time.Time, riskFreeRate float64) (*models.RiskReport, error) {
+	profile, err := s.profileSvc.GetProfile(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user profile: %w", err)
+	}
+
+	defaultCurrency, err := s.profileSvc.GetDefaultCurrency(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user profile: %w", err)
+	}
+
+	if endDate.IsZero() {
+		loc, err := s.profileSvc.GetLocation(userID)
+		if err != nil {
+			return nil, err
+		}
+		endDate = LocalDate(time.Now(), loc)
+	}
+	if startDate.IsZero() {
+		startDate = endDate.AddDate(-1, 0, 0)
+	}
+
+	snapshots, err := s.dailyAssetSvc.GetUserDailyTotalAssetValues(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	flows, err := loadPortfolioCashFlows(s.accountSvc, s.tradeSvc, s.exchangeSvc, userID, defaultCurrency, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Date.Before(snapshots[j].Date)
+	})
+	values := make([]cashFlow, len(snapshots))
+	for i, snapshot := range snapshots {
+		values[i] = cashFlow{Date: snapshot.Date, Amount: snapshot.TotalValue}
+	}
+
+	report := riskMetrics(values, flows, riskFreeRate)
+	report.StartDate = startDate
+	report.EndDate = endDate
+	report.Currency = defaultCurrency
+
+	if profile.InvestmentProfile != nil && profile.InvestmentProfile.MaxAcceptableShortTermLossPercentage > 0 {
+		tolerance := float64(profile.InvestmentProfile.MaxAcceptableShortTermLossPercentage)
+		report.MaxAcceptableLossPercentage = &tolerance
+		report.MaxDrawdownExceedsTolerance = report.MaxDrawdownPercentage != nil && *report.MaxDrawdownPercentage > tolerance
+		report.CurrentDrawdownExceedsTolerance = report.CurrentDrawdownPercentage != nil && *report.CurrentDrawdownPercentage > tolerance
+	}
+
+	return report, nil
+}
+
+// riskMetrics derives period returns from the flow-adjusted value index of sorted snapshots.
+// Snapshots may have gaps, so returns are annualized with the observed number of periods per year.
+func riskMetrics(values []cashFlow, flows []cashFlow, riskFreeRate float64) *models.RiskReport {
+	report := &models.RiskReport{RiskFreeRatePercentage: riskFreeRate}
+
+	index, linked := timeWeightedIndex(values, flows)
+	report.Observations = len(index)
+	if !linked {
+		return report
+	}
+
+	peak, peakDate := index[0], values[0].Date
+	maxDrawdown := 0.0
+	for i, value := range index {
+		if value > peak {
+			peak, peakDate = value, values[i].Date
+		}
+		if peak <= 0 {
+			continue
+		}
+		if drawdown := (peak - value) / peak; drawdown > maxDrawdown {
+			maxDrawdown = drawdown
+			peakCopy, troughDate := peakDate, values[i].Date
+			report.MaxDrawdownPeakDate = &peakCopy
+			report.MaxDrawdownTroughDate = &troughDate
+		}
+	}
+	report.MaxDrawdownPercentage = percentage(maxDrawdown)
+	if peak > 0 {
+		report.CurrentDrawdownPercentage = percentage((peak - index[len(index)-1]) / peak)
+	}
+
+	returns := make([]float64, 0, len(index)-1)
+	for i := 1; i < len(index); i++ {
+		if index[i-1] > 0 {
+			returns = append(returns, index[i]/index[i-1]-1)
+		}
+	}
+	spanDays := values[len(values)-1].Date.Sub(values[0].Date).Hours() / 24
+	if len(returns) < 2 || spanDays <= 0 {
+		return report
+	}
+	periodsPerYear := float64(len(returns)) * 365.25 / spanDays
+
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	variance := 0.0
+	downside := 0.0
+	riskFreePerPeriod := riskFreeRate / 100 / periodsPerYear
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+		if excess := r - riskFreePerPeriod; excess < 0 {
+			downside += excess * excess
+		}
+	}
+	volatility := math.Sqrt(variance/float64(len(returns)-1)) * math.Sqrt(periodsPerYear)
+	downsideDeviation := math.Sqrt(downside/float64(len(returns))) * math.Sqrt(periodsPerYear)
+	annualReturn := mean * periodsPerYear
+	excessReturn := annualReturn - riskFreeRate/100
+
+	report.AnnualizedReturnPercentage = percentage(annualReturn)
+	report.AnnualizedVolatilityPercentage = percentage(volatility)
+	if volatility > 0 {
+		sharpe := excessReturn / volatility
+		report.SharpeRatio = &sharpe
+	}
+	if downsideDeviation > 0 {
+		sortino := excessReturn / downsideDeviation
+		report.SortinoRatio = &sortino
+	}
+
+	return report
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"asset-diary/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRiskMetrics(t *testing.T) {
+	t.Run("drawdowns and ratios", func(t *testing.T) {
+		values := []cashFlow{
+			{Date: date(2026, 1, 1), Amount: 100},
+			{Date: date(2026, 1, 2), Amount: 110},
+			{Date: date(2026, 1, 3), Amount: 99},
+			{Date: date(2026, 1, 4), Amount: 105},
+		}
+
+		report := riskMetrics(values, nil, 0)
+
+		assert.Equal(t, 4, report.Observations)
+		assert.InDelta(t, 10, *report.MaxDrawdownPercentage, 1e-9)
+		assert.Equal(t, date(2026, 1, 2), *report.MaxDrawdownPeakDate)
+		assert.Equal(t, date(2026, 1, 3), *report.MaxDrawdownTroughDate)
+		assert.InDelta(t, 5.0/110*100, *report.CurrentDrawdownPercentage, 1e-9)
+
+		returns := []float64{0.1, -0.1, 105.0/99 - 1}
+		mean := (returns[0] + returns[1] + returns[2]) / 3
+		variance := 0.0
+		for _, r := range returns {
+			variance += (r - mean) * (r - mean)
+		}
+		periodsPerYear := 365.25
+		volatility := math.Sqrt(variance/2) * math.Sqrt(periodsPerYear)
+		downsideDeviation := math.Sqrt(0.01/3) * math.Sqrt(periodsPerYear)
+
+		assert.InDelta(t, volatility*100, *report.AnnualizedVolatilityPercentage, 1e-6)
+		assert.InDelta(t, mean*periodsPerYear/volatility, *report.SharpeRatio, 1e-6)
+		assert.InDelta(t, mean*periodsPerYear/downsideDeviation, *report.SortinoRatio, 1e-6)
+	})
+
+	t.Run("withdrawals are not drawdowns", func(t *testing.T) {
+		values := []cashFlow{
+			{Date: date(2026, 1, 1), Amount: 1000},
+			{Date: date(2026, 1, 2), Amount: 500},
+			{Date: date(2026, 1, 3), Amount: 510},
+		}
+		withdrawal := []cashFlow{{Date: date(2026, 1, 2), Amount: -500}}
+
+		report := riskMetrics(values, withdrawal, 0)
+
+		assert.InDelta(t, 0, *report.MaxDrawdownPercentage, 1e-9)
+		assert.Nil(t, report.MaxDrawdownPeakDate)
+		assert.Nil(t, report.SortinoRatio)
+	})
+
+	t.Run("sells are not drawdowns", func(t *testing.T) {
+		values := []cashFlow{
+			{Date: date(2026, 1, 1), Amount: 1000},
+			{Date: date(2026, 1, 2), Amount: 400},
+			{Date: date(2026, 1, 3), Amount: 404},
+		}
+		trades := []models.Trade{{Type: "sell", Quantity: 6, Price: 100, Currency: "TWD", TradeDate: date(2026, 1, 2)}}
+		ratesOn := func(time.Time) map[string]float64 { return map[string]float64{} }
+
+		report := riskMetrics(values, portfolioCashFlows(nil, trades, "TWD", ratesOn), 0)
+
+		assert.InDelta(t, 0, *report.MaxDrawdownPercentage, 1e-9)
+		assert.InDelta(t, 0, *report.CurrentDrawdownPercentage, 1e-9)
+	})
+
+	t.Run("too few snapshots", func(t *testing.T) {
+		report := riskMetrics([]cashFlow{{Date: date(2026, 1, 1), Amount: 100}}, nil, 0)
+
+		assert.Equal(t, 1, report.Observations)
+		assert.Nil(t, report.MaxDrawdownPercentage)
+		assert.Nil(t, report.AnnualizedVolatilityPercentage)
+	})
+}
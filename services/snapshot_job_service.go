@@ -0,0 +1,480 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"asset-diary/models"
+	"asset-diary/repositories"
+
+	"gorm.io/gorm"
+)
+
+const (
+	snapshotJobListLimit        = 20
+	snapshotJobProgressInterval = 10
+	snapshotPriceLookbackDays   = 7
+)
+
+type SnapshotJobServiceInterface interface {
+	StartBackfill(userID string, req models.SnapshotBackfillRequest) (*models.SnapshotJob, error)
+	StartBackfillForAllUsers(req models.SnapshotBackfillRequest) ([]models.SnapshotJob, error)
+	GetJob(userID, jobID string) (*models.SnapshotJob, error)
+	ListJobs(userID string) ([]models.SnapshotJob, error)
+	RecalculateFrom(userID string, fromDate time.Time) error
+}
+
+// SnapshotJobService rebuilds past daily snapshots in the background. Jobs are persisted so that
+// progress can be polled and pending work survives a restart; a single worker runs them in order.
+type SnapshotJobService struct {
+	repo           repositories.SnapshotJobRepositoryInterface
+	dailyAssetRepo repositories.UserDailyTotalAssetValueRepositoryInterface
+	tradeRepo      repositories.TradeRepositoryInterface
+	accountSvc     AccountServiceInterface
+	historicalSvc  HistoricalPriceServiceInterface
+	exchangeSvc    ExchangeRateServiceInterface
+	profileSvc     ProfileServiceInterface
+	userSvc        UserServiceInterface
+
+	// mu serializes claiming a pending job against extending it with a new request
+	mu   sync.Mutex
+	wake chan struct{}
+}
+
+func NewSnapshotJobService(
+	repo repositories.SnapshotJobRepositoryInterface,
+	dailyAssetRepo repositories.UserDailyTotalAssetValueRepositoryInterface,
+	tradeRepo repositories.TradeRepositoryInterface,
+	accountSvc AccountServiceInterface,
+	historicalSvc HistoricalPriceServiceInterface,
+	exchangeSvc ExchangeRateServiceInterface,
+	profileSvc ProfileServiceInterface,
+	userSvc UserServiceInterface,
+) *SnapshotJobService {
+	return &SnapshotJobService{
+		repo:           repo,
+		dailyAssetRepo: dailyAssetRepo,
+		tradeRepo:      tradeRepo,
+		accountSvc:     accountSvc,
+		historicalSvc:  historicalSvc,
+		exchangeSvc:    exchangeSvc,
+		profileSvc:     profileSvc,
+		userSvc:        userSvc,
+		wake:           make(chan struct{}, 1),
+	}
+}
+
+// Start launches the background worker. Jobs left running by a previous process are queued again.
+func (s *SnapshotJobService) Start() {
+	interrupted, err := s.repo.ListJobsByStatus(models.SnapshotJobStatusRunning)
+	if err != nil {
+		log.Printf("Failed to load interrupted snapshot jobs: %v", err)
+	}
+	for i := range interrupted {
+		job := interrupted[i]
+		job.Status = models.SnapshotJobStatusPending
+		job.ProcessedDays = 0
+		job.WrittenDays = 0
+		if err := s.repo.SaveJob(&job); err != nil {
+			log.Printf("Failed to requeue snapshot job %s: %v", job.ID, err)
+		}
+	}
+
+	go s.work()
+	s.notify()
+}
+
+func (s *SnapshotJobService) StartBackfill(userID string, req models.SnapshotBackfillRequest) (*models.SnapshotJob, error) {
+	startDate, endDate, err := s.backfillRange(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.enqueue(userID, startDate, endDate, req.Overwrite)
+}
+
+func (s *SnapshotJobService) StartBackfillForAllUsers(req models.SnapshotBackfillRequest) ([]models.SnapshotJob, error) {
+	userIDs, err := s.userSvc.GetAllUserIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := []models.SnapshotJob{}
+	for _, userID := range userIDs {
+		job, err := s.StartBackfill(userID, req)
+		if err != nil {
+			log.Printf("Skipping snapshot backfill for user %s: %v", userID, err)
+			continue
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, nil
+}
+
+func (s *SnapshotJobService) GetJob(userID, jobID string) (*models.SnapshotJob, error) {
+	job, err := s.repo.GetJob(userID, jobID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.NewAppError(models.ErrCodeNotFound, "Backfill job not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *SnapshotJobService) ListJobs(userID string) ([]models.SnapshotJob, error) {
+	return s.repo.ListJobs(userID, snapshotJobListLimit)
+}
+
+// RecalculateFrom queues an overwrite of the user's recorded snapshots from fromDate on, after a
+// change to the trade ledger. Nothing is queued when no snapshot exists on or after fromDate.
+func (s *SnapshotJobService) RecalculateFrom(userID string, fromDate time.Time) error {
+	latest, err := s.dailyAssetRepo.GetLatestUserDailyTotalAssetValue(userID)
+	if err != nil {
+		return err
+	}
+
+	fromDate = fromDate.UTC().Truncate(24 * time.Hour)
+	if latest == nil || latest.Date.Before(fromDate) {
+		return nil
+	}
+
+	_, err = s.enqueue(userID, fromDate, latest.Date.UTC().Truncate(24*time.Hour), true)
+	return err
+}
+
+func (s *SnapshotJobService) backfillRange(userID string, req models.SnapshotBackfillRequest) (time.Time, time.Time, error) {
+	loc, err := s.profileSvc.GetLocation(userID)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	today := LocalDate(time.Now(), loc)
+	endDate := today.AddDate(0, 0, -1)
+	if req.EndDate != "" {
+		parsed, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, models.NewAppError(models.ErrCodeInvalidRequest, "Invalid endDate format, use YYYY-MM-DD")
+		}
+		if parsed.After(today) {
+			return time.Time{}, time.Time{}, models.NewAppError(models.ErrCodeInvalidRequest, "endDate cannot be in the future")
+		}
+		endDate = parsed
+	}
+
+	var startDate time.Time
+	if req.StartDate != "" {
+		parsed, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, models.NewAppError(models.ErrCodeInvalidRequest, "Invalid startDate format, use YYYY-MM-DD")
+		}
+		startDate = parsed
+	} else {
+		trades, err := s.tradeRepo.ListTrades(userID)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		if len(trades) == 0 {
+			return time.Time{}, time.Time{}, models.NewAppError(models.ErrCodeInvalidRequest, "No trades to backfill from")
+		}
+		startDate = trades[0].TradeDate
+		for _, trade := range trades[1:] {
+			if trade.TradeDate.Before(startDate) {
+				startDate = trade.TradeDate
+			}
+		}
+		startDate = startDate.UTC().Truncate(24 * time.Hour)
+	}
+
+	if startDate.After(endDate) {
+		return time.Time{}, time.Time{}, models.NewAppError(models.ErrCodeInvalidRequest, "startDate must not be after endDate")
+	}
+	return startDate, endDate, nil
+}
+
+// enqueue extends the user's pending job when there is one so that repeated requests collapse
+// into a single pass, and creates a new job otherwise
+func (s *SnapshotJobService) enqueue(userID string, startDate, endDate time.Time, overwrite bool) (*models.SnapshotJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, err := s.repo.FindPendingJob(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if job != nil {
+		if startDate.Before(job.StartDate) {
+			job.StartDate = startDate
+		}
+		if endDate.After(job.EndDate) {
+			job.EndDate = endDate
+		}
+		job.Overwrite = job.Overwrite || overwrite
+		job.TotalDays = daysInclusive(job.StartDate, job.EndDate)
+		if err := s.repo.SaveJob(job); err != nil {
+			return nil, err
+		}
+	} else {
+		job = &models.SnapshotJob{
+			UserID:    userID,
+			Status:    models.SnapshotJobStatusPending,
+			StartDate: startDate,
+			EndDate:   endDate,
+			Overwrite: overwrite,
+			TotalDays: daysInclusive(startDate, endDate),
+		}
+		if err := s.repo.CreateJob(job); err != nil {
+			return nil, err
+		}
+	}
+
+	s.notify()
+	return job, nil
+}
+
+func (s *SnapshotJobService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *SnapshotJobService) work() {
+	for range s.wake {
+		for {
+			job, err := s.claimNextJob()
+			if err != nil {
+				log.Printf("Failed to claim snapshot job: %v", err)
+				break
+			}
+			if job == nil {
+				break
+			}
+			s.runJob(job)
+		}
+	}
+}
+
+func (s *SnapshotJobService) claimNextJob() (*models.SnapshotJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs, err := s.repo.ListJobsByStatus(models.SnapshotJobStatusPending)
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+
+	job := jobs[0]
+	now := time.Now().UTC()
+	job.Status = models.SnapshotJobStatusRunning
+	job.StartedAt = &now
+	if err := s.repo.SaveJob(&job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (s *SnapshotJobService) runJob(job *models.SnapshotJob) {
+	err := s.backfill(job)
+
+	finishedAt := time.Now().UTC()
+	job.FinishedAt = &finishedAt
+	if err != nil {
+		log.Printf("Snapshot job %s failed: %v", job.ID, err)
+		message := err.Error()
+		job.Status = models.SnapshotJobStatusFailed
+		job.Error = &message
+	} else {
+		job.Status = models.SnapshotJobStatusCompleted
+	}
+	if err := s.repo.SaveJob(job); err != nil {
+		log.Printf("Failed to finish snapshot job %s: %v", job.ID, err)
+	}
+}
+
+func (s *SnapshotJobService) backfill(job *models.SnapshotJob) error {
+	defaultCurrency, err := s.profileSvc.GetDefaultCurrency(job.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get default currency: %w", err)
+	}
+
+	trades, err := s.tradeRepo.ListTrades(job.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to list trades: %w", err)
+	}
+	accounts, err := s.accountSvc.ListAccounts(job.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to list accounts: %w", err)
+	}
+	txns, err := s.accountSvc.ListUserTransactions(job.UserID, job.StartDate, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to list account transactions: %w", err)
+	}
+
+	existing, err := s.dailyAssetRepo.GetUserDailyTotalAssetValues(job.UserID, job.StartDate, job.EndDate)
+	if err != nil {
+		return fmt.Errorf("failed to load existing snapshots: %w", err)
+	}
+	recorded := make(map[string]bool, len(existing))
+	for _, record := range existing {
+		recorded[record.Date.Format("2006-01-02")] = true
+	}
+
+	prices := s.loadPriceHistory(trades, job.StartDate, job.EndDate)
+	dayRates, err := s.exchangeSvc.GetRangeRates(defaultCurrency, job.StartDate, job.EndDate)
+	if err != nil {
+		return fmt.Errorf("failed to get exchange rates: %w", err)
+	}
+	var missingRates []string
+
+	job.TotalDays = daysInclusive(job.StartDate, job.EndDate)
+	job.ProcessedDays = 0
+	job.WrittenDays = 0
+
+	for day := job.StartDate; !day.After(job.EndDate); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		if job.Overwrite || !recorded[key] {
+			holdings := holdingsOnDate(trades, prices, day)
+			balances := accountsOnDate(accounts, txns, day)
+			// current rates would misstate the past, so a day without its own rates is not written
+			// unless it holds nothing that needs converting
+			record, components := buildSnapshot(job.UserID, day, defaultCurrency, dayRates[key], holdings, balances)
+			if !snapshotConverted(components) {
+				missingRates = append(missingRates, key)
+			} else {
+				if err := s.dailyAssetRepo.SaveSnapshot(record, components); err != nil {
+					return fmt.Errorf("failed to save snapshot on %s: %w", key, err)
+				}
+				job.WrittenDays++
+			}
+		}
+
+		job.ProcessedDays++
+		if job.ProcessedDays%snapshotJobProgressInterval == 0 {
+			if err := s.repo.SaveJob(job); err != nil {
+				log.Printf("Failed to update progress of snapshot job %s: %v", job.ID, err)
+			}
+		}
+	}
+
+	if len(missingRates) > 0 {
+		return fmt.Errorf("no historical exchange rates for %d days from %s to %s, which were not written",
+			len(missingRates), missingRates[0], missingRates[len(missingRates)-1])
+	}
+	return nil
+}
+
+// snapshotConverted reports whether every component of a snapshot was converted into the default
+// currency
+func snapshotConverted(components []models.UserDailyAssetComponent) bool {
+	for _, component := range components {
+		if component.ValueInDefaultCurrency == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// loadPriceHistory fetches daily closes for every traded asset, keyed like priceHistoryKey. Assets
+// whose history cannot be fetched are left out and valued at their last trade price.
+func (s *SnapshotJobService) loadPriceHistory(trades []models.Trade, startDate, endDate time.Time) map[string][]models.PriceHistory {
+	firstTraded := make(map[string]time.Time)
+	assets := make(map[string]models.Trade)
+	for _, trade := range trades {
+		key := priceHistoryKey(trade.AssetType, trade.Ticker)
+		if first, ok := firstTraded[key]; !ok || trade.TradeDate.Before(first) {
+			firstTraded[key] = trade.TradeDate
+		}
+		assets[key] = trade
+	}
+
+	prices := make(map[string][]models.PriceHistory, len(assets))
+	for key, trade := range assets {
+		from := startDate
+		if first := firstTraded[key].UTC().Truncate(24 * time.Hour); first.After(from) {
+			from = first
+		}
+		if from.After(endDate) {
+			continue
+		}
+
+		history, err := s.historicalSvc.GetPriceHistory(trade.AssetType, trade.Ticker, from.AddDate(0, 0, -snapshotPriceLookbackDays), endDate)
+		if err != nil {
+			log.Printf("Failed to load price history for %s %s: %v", trade.AssetType, trade.Ticker, err)
+			continue
+		}
+		prices[key] = history
+	}
+	return prices
+}
+
+func priceHistoryKey(assetType, ticker string) string {
+	return assetType + "_" + ticker
+}
+
+// holdingsOnDate replays the trades made up to the end of day into per-account holdings valued at
+// the latest close on or before day, falling back to the last trade price
+func holdingsOnDate(trades []models.Trade, prices map[string][]models.PriceHistory, day time.Time) []models.Holding {
+	dayEnd := day.AddDate(0, 0, 1)
+
+	tradesMap := make(map[string][]models.Trade)
+	for _, trade := range trades {
+		if !trade.TradeDate.Before(dayEnd) {
+			continue
+		}
+		key := fmt.Sprintf("%s_%s_%s_%s", trade.AccountID, trade.AssetType, trade.Ticker, trade.Currency)
+		tradesMap[key] = append(tradesMap[key], trade)
+	}
+
+	holdings := make([]models.Holding, 0, len(tradesMap))
+	for _, accountTrades := range tradesMap {
+		holding, err := calculateHolding(accountTrades)
+		if err != nil || holding.Quantity <= 0 {
+			continue
+		}
+		holding.AccountID = accountTrades[0].AccountID
+
+		price, ok := closeOnOrBefore(prices[priceHistoryKey(holding.AssetType, holding.Ticker)], day)
+		if !ok {
+			// calculateHolding leaves the trades sorted by date
+			price = accountTrades[len(accountTrades)-1].Price
+		}
+		holding.Price = price
+		holding.TotalValue = price * holding.Quantity
+		holding.TotalCost = holding.AverageCost * holding.Quantity
+		holding.GainLoss = holding.TotalValue - holding.TotalCost
+		holdings = append(holdings, *holding)
+	}
+
+	sort.Slice(holdings, func(i, j int) bool {
+		if holdings[i].AccountID != holdings[j].AccountID {
+			return holdings[i].AccountID < holdings[j].AccountID
+		}
+		return holdings[i].Ticker < holdings[j].Ticker
+	})
+	return holdings
+}
+
+// accountsOnDate rolls current balances back to the end of day by undoing later transactions
+func accountsOnDate(accounts []models.Account, txns []models.AccountTransaction, day time.Time) []models.Account {
+	balances := make(map[string]float64, len(accounts))
+	for _, txn := range txns {
+		if txn.TransactionDate.After(day) {
+			balances[txn.AccountID] += txn.SignedAmount()
+		}
+	}
+
+	result := make([]models.Account, len(accounts))
+	for i, account := range accounts {
+		account.Balance -= balances[account.ID]
+		result[i] = account
+	}
+	return result
+}
+
+func daysInclusive(startDate, endDate time.Time) int {
+	return int(endDate.Sub(startDate).Hours()/24) + 1
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"testing"
+
+	"asset-diary/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHoldingsOnDate(t *testing.T) {
+	trades := []models.Trade{
+		{ID: "t1", AccountID: "acc-1", Type: "buy", AssetType: "stock", Ticker: "2330", Currency: "TWD", TradeDate: date(2026, 1, 5), Quantity: 1000, Price: 500},
+		{ID: "t2", AccountID: "acc-1", Type: "sell", AssetType: "stock", Ticker: "2330", Currency: "TWD", TradeDate: date(2026, 1, 7), Quantity: 400, Price: 520},
+		{ID: "t3", AccountID: "acc-2", Type: "buy", AssetType: "crypto", Ticker: "BTC", Currency: "USD", TradeDate: date(2026, 1, 6), Quantity: 0.5, Price: 90000},
+	}
+	prices := map[string][]models.PriceHistory{
+		priceHistoryKey("stock", "2330"): {
+			{Date: date(2026, 1, 5), Close: 505},
+			{Date: date(2026, 1, 6), Close: 510},
+			{Date: date(2026, 1, 8), Close: 530},
+		},
+	}
+
+	assert.Empty(t, holdingsOnDate(trades, prices, date(2026, 1, 4)))
+
+	onSixth := holdingsOnDate(trades, prices, date(2026, 1, 6))
+	assert.Len(t, onSixth, 2)
+	assert.Equal(t, "acc-1", onSixth[0].AccountID)
+	assert.InDelta(t, 1000, onSixth[0].Quantity, 1e-9)
+	assert.InDelta(t, 510, onSixth[0].Price, 1e-9)
+	// no price history for BTC, so the trade price is used
+	assert.Equal(t, "BTC", onSixth[1].Ticker)
+	assert.InDelta(t, 90000, onSixth[1].Price, 1e-9)
+
+	// the 7th has no close yet, so the close of the 6th carries over
+	onSeventh := holdingsOnDate(trades, prices, date(2026, 1, 7))
+	assert.InDelta(t, 600, onSeventh[0].Quantity, 1e-9)
+	assert.InDelta(t, 510, onSeventh[0].Price, 1e-9)
+	assert.InDelta(t, 600*510, onSeventh[0].TotalValue, 1e-6)
+}
+
+func TestAccountsOnDate(t *testing.T) {
+	accounts := []models.Account{
+		{ID: "acc-1", Currency: "TWD", Balance: 10000},
+		{ID: "acc-2", Currency: "USD", Balance: 500},
+	}
+	txns := []models.AccountTransaction{
+		{AccountID: "acc-1", Type: models.AccountTransactionTypeDeposit, Amount: 3000, TransactionDate: date(2026, 1, 10)},
+		{AccountID: "acc-1", Type: models.AccountTransactionTypeWithdrawal, Amount: 1000, TransactionDate: date(2026, 1, 5)},
+		{AccountID: "acc-2", Type: models.AccountTransactionTypeDeposit, Amount: 200, TransactionDate: date(2026, 1, 6)},
+	}
+
+	balances := accountsOnDate(accounts, txns, date(2026, 1, 5))
+	assert.InDelta(t, 7000, balances[0].Balance, 1e-9)
+	assert.InDelta(t, 300, balances[1].Balance, 1e-9)
+
+	balances = accountsOnDate(accounts, txns, date(2026, 1, 10))
+	assert.InDelta(t, 10000, balances[0].Balance, 1e-9)
+	assert.InDelta(t, 10000, accounts[0].Balance, 1e-9)
+}
+
+func TestCoversRange(t *testing.T) {
+	prices := []models.PriceHistory{{Date: date(2026, 1, 5)}, {Date: date(2026, 3, 27)}}
+
+	assert.True(t, coversRange(prices, date(2026, 1, 3), date(2026, 3, 30)))
+	assert.False(t, coversRange(prices, date(2025, 12, 1), date(2026, 3, 30)))
+	assert.False(t, coversRange(prices, date(2026, 1, 3), date(2026, 5, 1)))
+	assert.False(t, coversRange(nil, date(2026, 1, 3), date(2026, 1, 4)))
+}
+
+func TestRecordedRatesOn(t *testing.T) {
+	recorded := newRecordedRates([]models.ExchangeRateHistory{
+		{BaseCurrency: "TWD", TargetCurrency: "USD", Date: date(2026, 1, 9), Rate: 0.031},
+		{BaseCurrency: "TWD", TargetCurrency: "USD", Date: date(2026, 1, 2), Rate: 0.030},
+	})
+	recorded.add([]models.ExchangeRateHistory{{BaseCurrency: "TWD", TargetCurrency: "USD", Date: date(2026, 1, 5), Rate: 0.032}})
+
+	_, ok := recorded.on(date(2026, 1, 1))
+	assert.False(t, ok)
+
+	rates, ok := recorded.on(date(2026, 1, 7))
+	assert.True(t, ok)
+	assert.InDelta(t, 0.032, rates["USD"], 1e-9)
+
+	rates, ok = recorded.on(date(2026, 1, 16))
+	assert.True(t, ok)
+	assert.InDelta(t, 0.031, rates["USD"], 1e-9)
+
+	// more than historicalRateMaxAge after the latest recorded day
+	_, ok = recorded.on(date(2026, 1, 17))
+	assert.False(t, ok)
+}
+
+func TestSnapshotConverted(t *testing.T) {
+	value := 1000.0
+	assert.True(t, snapshotConverted(nil))
+	assert.True(t, snapshotConverted([]models.UserDailyAssetComponent{{ValueInDefaultCurrency: &value}}))
+	assert.False(t, snapshotConverted([]models.UserDailyAssetComponent{{ValueInDefaultCurrency: &value}, {}}))
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"html/template"
+	"io"
+	"time"
+
+	"asset-diary/models"
+)
+
+var statementTemplate = template.Must(template.New("statement").Funcs(template.FuncMap{
+	"date":    formatDate,
+	"amount":  formatAmount,
+	"percent": formatOptionalAmount,
+	"optionalDate": func(date *time.Time) string {
+		if date == nil {
+			return "-"
+		}
+		return formatDate(*date)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Portfolio statement {{.Period}}</title>
+<style>
+body { font-family: sans-serif; font-size: 12px; margin: 24px; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 24px; }
+th, td { border: 1px solid #999; padding: 4px 6px; text-align: left; }
+td.number { text-align: right; }
+tr.total th, tr.total td { font-weight: bold; }
+@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>Portfolio statement {{.Period}}</h1>
+<p>{{date .StartDate}} to {{date .EndDate}}, in {{.Currency}}. Generated {{.GeneratedAt.Format "2006-01-02 15:04 MST"}}.{{if .UnconvertedCurrencies}} No rate was found for: {{range $i, $c := .UnconvertedCurrencies}}{{if $i}}, {{end}}{{$c}}{{end}}.{{end}}</p>
+
+<h2>Summary</h2>
+<table>
+<tr><th>Opening value ({{optionalDate .OpeningDate}})</th><td class="number">{{amount .OpeningValue}}</td></tr>
+<tr><th>Contributions</th><td class="number">{{amount .Contributions}}</td></tr>
+<tr><th>Withdrawals</th><td class="number">{{amount .Withdrawals}}</td></tr>
+<tr><th>Income</th><td class="number">{{amount .Income}}</td></tr>
+<tr><th>Fees</th><td class="number">{{amount .Fees}}</td></tr>
+<tr><th>Realized gains</th><td class="number">{{amount .RealizedGain}}</td></tr>
+<tr><th>Change in unrealized gains</th><td class="number">{{amount .UnrealizedGain}}</td></tr>
+<tr class="total"><th>Closing value ({{optionalDate .ClosingDate}})</th><td class="number">{{amount .ClosingValue}}</td></tr>
+</table>
+
+<h2>Top movers</h2>
+<table>
+<tr><th>Ticker</th><th>Name</th><th>Opening value</th><th>Net trades</th><th>Closing value</th><th>Change</th><th>Change %</th></tr>
+{{range .TopMovers}}<tr><td>{{.Ticker}}</td><td>{{.Name}}</td><td class="number">{{amount .OpeningValue}}</td><td class="number">{{amount .NetTrades}}</td><td class="number">{{amount .ClosingValue}}</td><td class="number">{{amount .Change}}</td><td class="number">{{percent .ChangePercentage}}</td></tr>
+{{else}}<tr><td colspan="7">No holdings changed in value.</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// WriteStatementHTML renders the statement as a self-contained page that can be archived or printed
+func WriteStatementHTML(w io.Writer, statement *models.Statement) error {
+	return statementTemplate.Execute(w, statement)
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"asset-diary/models"
+)
+
+const statementTopMovers = 5
+
+type StatementServiceInterface interface {
+	GetStatement(userID, period string) (*models.Statement, error)
+}
+
+type StatementService struct {
+	dailyAssetSvc DailyTotalAssetValueServiceInterface
+	accountSvc    AccountServiceInterface
+	tradeSvc      TradeServiceInterface
+	profileSvc    ProfileServiceInterface
+	exchangeSvc   ExchangeRateServiceInterface
+}
+
+func NewStatementService(
+	dailyAssetSvc DailyTotalAssetValueServiceInterface,
+	accountSvc AccountServiceInterface,
+	tradeSvc TradeServiceInterface,
+	profileSvc ProfileServiceInterface,
+	exchangeSvc ExchangeRateServiceInterface,
+) *StatementService {
+	return &StatementService{
+		dailyAssetSvc: dailyAssetSvc,
+		accountSvc:    accountSvc,
+		tradeSvc:      tradeSvc,
+		profileSvc:    profileSvc,
+		exchangeSvc:   exchangeSvc,
+	}
+}
+
+// GetStatement builds the statement of a month (YYYY-MM) or a year (YYYY). A period that is not
+// over yet ends on the user's local today.
+func (s *StatementService) GetStatement(userID, period string) (*models.Statement, error) {
+	periodType, startDate, endDate, err := parseStatementPeriod(period)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := s.profileSvc.GetLocation(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user timezone: %w", err)
+	}
+	today := LocalDate(time.Now(), loc)
+	if startDate.After(today) {
+		return nil, models.NewAppError(models.ErrCodeInvalidRequest, "Period has not started yet")
+	}
+	endDate = earliest(endDate, today)
+
+	defaultCurrency, err := s.profileSvc.GetDefaultCurrency(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user profile: %w", err)
+	}
+
+	rates, err := s.exchangeSvc.GetRatesByBaseCurrency(defaultCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rates: %w", err)
+	}
+
+	snapshots, err := s.dailyAssetSvc.GetUserDailyTotalAssetValues(userID, startDate.AddDate(0, 0, -1), endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var opening, closing *models.DailyAssetBreakdown
+	if len(snapshots) > 0 {
+		sort.Slice(snapshots, func(i, j int) bool {
+			return snapshots[i].Date.Before(snapshots[j].Date)
+		})
+		opening, err = s.dailyAssetSvc.GetBreakdown(userID, snapshots[0].Date)
+		if err != nil {
+			return nil, err
+		}
+		closing, err = s.dailyAssetSvc.GetBreakdown(userID, snapshots[len(snapshots)-1].Date)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	txns, err := s.accountSvc.ListUserTransactions(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	trades, err := s.tradeSvc.ListTrades(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// cash movements and sales are valued at the rate of their date, like the income report
+	historicalRates := make(map[string]map[string]float64)
+	ratesOn := func(date time.Time) map[string]float64 {
+		day := date.Format("2006-01-02")
+		if cached, ok := historicalRates[day]; ok {
+			return cached
+		}
+		dayRates, err := s.exchangeSvc.GetHistoricalRates(defaultCurrency, date)
+		if err != nil {
+			log.Printf("Error getting exchange rates on %s, using today's: %v", day, err)
+			dayRates = rates
+		}
+		historicalRates[day] = dayRates
+		return dayRates
+	}
+
+	statement := &models.Statement{
+		Period:      period,
+		PeriodType:  periodType,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Currency:    defaultCurrency,
+		GeneratedAt: time.Now().UTC(),
+	}
+	buildStatement(statement, opening, closing, txns, trades, ratesOn)
+	return statement, nil
+}
+
+// parseStatementPeriod turns YYYY-MM into a month and YYYY into a year
+func parseStatementPeriod(period string) (string, time.Time, time.Time, error) {
+	if month, err := time.Parse("2006-01", period); err == nil {
+		return models.StatementPeriodMonth, month, month.AddDate(0, 1, -1), nil
+	}
+	if year, err := time.Parse("2006", period); err == nil {
+		return models.StatementPeriodYear, year, year.AddDate(1, 0, -1), nil
+	}
+	return "", time.Time{}, time.Time{}, models.NewAppError(models.ErrCodeInvalidRequest, "period must be YYYY-MM or YYYY")
+}
+
+// buildStatement fills the values of statement from the opening and closing snapshots, which are
+// nil when no snapshot was recorded, and the transactions and trades between them
+func buildStatement(
+	statement *models.Statement,
+	opening, closing *models.DailyAssetBreakdown,
+	txns []models.AccountTransaction,
+	trades []models.Trade,
+	ratesOn func(time.Time) map[string]float64,
+) {
+	from := statement.StartDate.AddDate(0, 0, -1)
+	to := statement.EndDate
+	if opening != nil {
+		from = opening.Date
+		statement.OpeningDate = &opening.Date
+		statement.OpeningValue = opening.TotalValue
+	}
+	if closing != nil {
+		to = closing.Date
+		statement.ClosingDate = &closing.Date
+		statement.ClosingValue = closing.TotalValue
+	}
+	inPeriod := func(date time.Time) bool {
+		return date.After(from) && !date.After(to)
+	}
+
+	unconverted := map[string]bool{}
+	toDefault := func(amount float64, currency string, date time.Time) float64 {
+		value, ok := convertWithRates(amount, currency, statement.Currency, ratesOn(date))
+		if !ok {
+			unconverted[currency] = true
+			return 0
+		}
+		return value
+	}
+
+	for _, txn := range txns {
+		if !inPeriod(txn.TransactionDate) {
+			continue
+		}
+		amount := toDefault(txn.Amount, txn.Currency, txn.TransactionDate)
+		switch txn.Type {
+		case models.AccountTransactionTypeDeposit:
+			statement.Contributions += amount
+		case models.AccountTransactionTypeWithdrawal:
+			statement.Withdrawals += amount
+		case models.AccountTransactionTypeDividend, models.AccountTransactionTypeInterest:
+			statement.Income += amount
+		case models.AccountTransactionTypeFee:
+			statement.Fees += amount
+		}
+	}
+
+	positions := make(map[string][]models.Trade)
+	for _, trade := range trades {
+		key := fmt.Sprintf("%s_%s_%s", trade.AccountID, trade.AssetType, strings.ToUpper(trade.Ticker))
+		positions[key] = append(positions[key], trade)
+	}
+
+	for _, positionTrades := range positions {
+		holding, err := calculateHolding(append([]models.Trade(nil), positionTrades...))
+		if err != nil {
+			log.Printf("Error calculating realized gains for trade %s: %v", positionTrades[0].ID, err)
+			continue
+		}
+		for _, lot := range holding.RealizedLots {
+			if !inPeriod(lot.DisposalDate) {
+				continue
+			}
+			gain := lot.Quantity * (lot.SalePrice - lot.CostPrice)
+			statement.RealizedGain += toDefault(gain, holding.Currency, lot.DisposalDate)
+		}
+	}
+
+	if opening != nil && closing != nil {
+		statement.UnrealizedGain = unrealizedGainOf(closing, positions) - unrealizedGainOf(opening, positions)
+	}
+
+	statement.TopMovers = topMovers(opening, closing, trades, inPeriod, toDefault)
+
+	statement.UnconvertedCurrencies = make([]string, 0, len(unconverted))
+	for currency := range unconverted {
+		statement.UnconvertedCurrencies = append(statement.UnconvertedCurrencies, currency)
+	}
+	sort.Strings(statement.UnconvertedCurrencies)
+}
+
+// unrealizedGainOf is the value of the snapshot's holdings less their FIFO cost on the snapshot
+// date, converted at the snapshot's rates. Holdings without a rate are left out, as in the total.
+func unrealizedGainOf(breakdown *models.DailyAssetBreakdown, positions map[string][]models.Trade) float64 {
+	gain := 0.0
+	for _, component := range breakdown.Holdings {
+		if component.ValueInDefaultCurrency == nil || component.FXRate == nil || *component.FXRate <= 0 {
+			continue
+		}
+
+		var held []models.Trade
+		key := fmt.Sprintf("%s_%s_%s", component.AccountID, component.AssetType, strings.ToUpper(component.Ticker))
+		for _, trade := range positions[key] {
+			if !trade.TradeDate.After(breakdown.Date) {
+				held = append(held, trade)
+			}
+		}
+		cost := 0.0
+		if len(held) > 0 {
+			holding, err := calculateHolding(held)
+			if err != nil {
+				log.Printf("Error calculating cost of %s on %s: %v", component.Ticker, breakdown.Date.Format("2006-01-02"), err)
+				continue
+			}
+			cost = holding.TotalCost
+		}
+
+		gain += *component.ValueInDefaultCurrency - cost / *component.FXRate
+	}
+	return gain
+}
+
+// topMovers ranks the securities by the absolute size of their market gain over the period
+func topMovers(
+	opening, closing *models.DailyAssetBreakdown,
+	trades []models.Trade,
+	inPeriod func(time.Time) bool,
+	toDefault func(amount float64, currency string, date time.Time) float64,
+) []models.StatementMover {
+	movers := make(map[string]*models.StatementMover)
+	mover := func(assetType, ticker, name string) *models.StatementMover {
+		key := priceHistoryKey(assetType, strings.ToUpper(ticker))
+		if movers[key] == nil {
+			movers[key] = &models.StatementMover{AssetType: assetType, Ticker: strings.ToUpper(ticker), Name: name}
+		}
+		return movers[key]
+	}
+
+	if opening != nil {
+		for _, component := range opening.Holdings {
+			if component.ValueInDefaultCurrency != nil {
+				mover(component.AssetType, component.Ticker, component.Name).OpeningValue += *component.ValueInDefaultCurrency
+			}
+		}
+	}
+	if closing != nil {
+		for _, component := range closing.Holdings {
+			if component.ValueInDefaultCurrency != nil {
+				mover(component.AssetType, component.Ticker, component.Name).ClosingValue += *component.ValueInDefaultCurrency
+			}
+		}
+	}
+
+	bought := make(map[*models.StatementMover]float64)
+	for _, trade := range trades {
+		if !inPeriod(trade.TradeDate) {
+			continue
+		}
+		m := mover(trade.AssetType, trade.Ticker, trade.TickerName)
+		amount := toDefault(trade.Quantity*trade.Price, trade.Currency, trade.TradeDate)
+		if trade.Type == "sell" {
+			m.NetTrades -= amount
+		} else {
+			m.NetTrades += amount
+			bought[m] += amount
+		}
+	}
+
+	result := make([]models.StatementMover, 0, len(movers))
+	for _, m := range movers {
+		m.Change = m.ClosingValue - m.OpeningValue - m.NetTrades
+		if invested := m.OpeningValue + bought[m]; invested > 0 {
+			m.ChangePercentage = percentage(m.Change / invested)
+		}
+		if m.Change != 0 {
+			result = append(result, *m)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if math.Abs(result[i].Change) != math.Abs(result[j].Change) {
+			return math.Abs(result[i].Change) > math.Abs(result[j].Change)
+		}
+		return result[i].Ticker < result[j].Ticker
+	})
+	if len(result) > statementTopMovers {
+		result = result[:statementTopMovers]
+	}
+	return result
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"asset-diary/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func holdingComponent(accountID, ticker string, quantity, price, fxRate float64) models.UserDailyAssetComponent {
+	value := quantity * price
+	valueInDefault := value / fxRate
+	return models.UserDailyAssetComponent{
+		ComponentType:          models.SnapshotComponentHolding,
+		AccountID:              accountID,
+		AssetType:              "stock",
+		Ticker:                 ticker,
+		Name:                   ticker,
+		Quantity:               quantity,
+		Price:                  price,
+		Value:                  value,
+		FXRate:                 &fxRate,
+		ValueInDefaultCurrency: &valueInDefault,
+	}
+}
+
+func TestParseStatementPeriod(t *testing.T) {
+	periodType, start, end, err := parseStatementPeriod("2026-02")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatementPeriodMonth, periodType)
+	assert.Equal(t, date(2026, 2, 1), start)
+	assert.Equal(t, date(2026, 2, 28), end)
+
+	periodType, start, end, err = parseStatementPeriod("2025")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatementPeriodYear, periodType)
+	assert.Equal(t, date(2025, 1, 1), start)
+	assert.Equal(t, date(2025, 12, 31), end)
+
+	_, _, _, err = parseStatementPeriod("2026-13")
+	assert.Error(t, err)
+}
+
+func TestBuildStatement(t *testing.T) {
+	opening := &models.DailyAssetBreakdown{
+		Date:       date(2026, 8, 31),
+		TotalValue: 600000,
+		Holdings:   []models.UserDailyAssetComponent{holdingComponent("acc-1", "2330", 1000, 600, 1)},
+	}
+	closing := &models.DailyAssetBreakdown{
+		Date:       date(2026, 9, 30),
+		TotalValue: 425100,
+		Holdings: []models.UserDailyAssetComponent{
+			holdingComponent("acc-1", "2330", 500, 700, 1),
+			holdingComponent("acc-2", "AAPL", 10, 110, 1.0/32),
+		},
+	}
+	trades := []models.Trade{
+		{AccountID: "acc-1", Type: "buy", AssetType: "stock", Ticker: "2330", Quantity: 1000, Price: 500, Currency: "TWD", TradeDate: date(2026, 1, 5)},
+		{AccountID: "acc-1", Type: "sell", AssetType: "stock", Ticker: "2330", Quantity: 500, Price: 650, Currency: "TWD", TradeDate: date(2026, 9, 10)},
+		{AccountID: "acc-2", Type: "buy", AssetType: "stock", Ticker: "AAPL", Quantity: 10, Price: 100, Currency: "USD", TradeDate: date(2026, 9, 15)},
+	}
+	txns := []models.AccountTransaction{
+		// already part of the opening snapshot
+		{AccountID: "acc-1", Type: models.AccountTransactionTypeDeposit, Amount: 1000, Currency: "TWD", TransactionDate: date(2026, 8, 31)},
+		{AccountID: "acc-1", Type: models.AccountTransactionTypeDeposit, Amount: 50000, Currency: "TWD", TransactionDate: date(2026, 9, 1)},
+		{AccountID: "acc-1", Type: models.AccountTransactionTypeWithdrawal, Amount: 10000, Currency: "TWD", TransactionDate: date(2026, 9, 20)},
+		dividend("acc-1", "2330", date(2026, 9, 25), 1000, "TWD"),
+		{AccountID: "acc-2", Type: models.AccountTransactionTypeFee, Amount: 5, Currency: "USD", TransactionDate: date(2026, 9, 30)},
+	}
+	ratesOn := func(day time.Time) map[string]float64 {
+		return map[string]float64{"USD": 1.0 / 32}
+	}
+
+	statement := &models.Statement{Period: "2026-09", StartDate: date(2026, 9, 1), EndDate: date(2026, 9, 30), Currency: "TWD"}
+	buildStatement(statement, opening, closing, txns, trades, ratesOn)
+
+	assert.Equal(t, date(2026, 8, 31), *statement.OpeningDate)
+	assert.InDelta(t, 600000, statement.OpeningValue, 1e-9)
+	assert.InDelta(t, 425100, statement.ClosingValue, 1e-9)
+	assert.InDelta(t, 50000, statement.Contributions, 1e-9)
+	assert.InDelta(t, 10000, statement.Withdrawals, 1e-9)
+	assert.InDelta(t, 1000, statement.Income, 1e-9)
+	assert.InDelta(t, 160, statement.Fees, 1e-9)
+	assert.InDelta(t, 75000, statement.RealizedGain, 1e-9)
+	// 2330 stays 100 per share above its remaining cost and AAPL gained 10 USD a share
+	assert.InDelta(t, 3200, statement.UnrealizedGain, 1e-6)
+	assert.Empty(t, statement.UnconvertedCurrencies)
+
+	require.Len(t, statement.TopMovers, 2)
+	assert.Equal(t, "2330", statement.TopMovers[0].Ticker)
+	assert.InDelta(t, -325000, statement.TopMovers[0].NetTrades, 1e-9)
+	assert.InDelta(t, 75000, statement.TopMovers[0].Change, 1e-9)
+	assert.InDelta(t, 12.5, *statement.TopMovers[0].ChangePercentage, 1e-9)
+	assert.Equal(t, "AAPL", statement.TopMovers[1].Ticker)
+	assert.InDelta(t, 3200, statement.TopMovers[1].Change, 1e-6)
+	assert.InDelta(t, 10, *statement.TopMovers[1].ChangePercentage, 1e-6)
+}
+
+func TestBuildStatementWithoutSnapshots(t *testing.T) {
+	txns := []models.AccountTransaction{
+		{AccountID: "acc-1", Type: models.AccountTransactionTypeDeposit, Amount: 100, Currency: "JPY", TransactionDate: date(2026, 9, 1)},
+	}
+	ratesOn := func(day time.Time) map[string]float64 { return map[string]float64{} }
+
+	statement := &models.Statement{Period: "2026-09", StartDate: date(2026, 9, 1), EndDate: date(2026, 9, 30), Currency: "TWD"}
+	buildStatement(statement, nil, nil, txns, nil, ratesOn)
+
+	assert.Nil(t, statement.OpeningDate)
+	assert.Nil(t, statement.ClosingDate)
+	assert.Zero(t, statement.Contributions)
+	assert.Equal(t, []string{"JPY"}, statement.UnconvertedCurrencies)
+	assert.Empty(t, statement.TopMovers)
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Markets of exchange-suffixed international symbols
+const (
+	MarketHK = "HK"
+	MarketJP = "JP"
+	MarketUK = "UK"
+	MarketCN = "CN"
+	MarketKR = "KR"
+	MarketSG = "SG"
+	MarketAU = "AU"
+	MarketCA = "CA"
+	MarketDE = "DE"
+	MarketFR = "FR"
+	MarketNL = "NL"
+	MarketCH = "CH"
+)
+
+type stockExchange struct {
+	market   string
+	currency string
+	// minorUnits is the number of quoted units per currency unit, for exchanges that quote in
+	// subunits such as pence
+	minorUnits float64
+}
+
+// stockExchanges maps the exchange suffixes used by FMP and Yahoo Finance (0700.HK, 7203.T,
+// VWRL.L) to the market and currency of their prices
+var stockExchanges = map[string]stockExchange{
+	"HK": {market: MarketHK, currency: "HKD"},
+	"T":  {market: MarketJP, currency: "JPY"},
+	"L":  {market: MarketUK, currency: "GBP", minorUnits: 100},
+	"SS": {market: MarketCN, currency: "CNY"},
+	"SZ": {market: MarketCN, currency: "CNY"},
+	"KS": {market: MarketKR, currency: "KRW"},
+	"SI": {market: MarketSG, currency: "SGD"},
+	"AX": {market: MarketAU, currency: "AUD"},
+	"TO": {market: MarketCA, currency: "CAD"},
+	"DE": {market: MarketDE, currency: "EUR"},
+	"PA": {market: MarketFR, currency: "EUR"},
+	"AS": {market: MarketNL, currency: "EUR"},
+	"SW": {market: MarketCH, currency: "CHF"},
+}
+
+var taiwanTickerPattern = regexp.MustCompile(`^\d`)
+
+// StockSymbol is a stock symbol with the market it trades on and the currency of its price
+type StockSymbol struct {
+	Symbol     string
+	Market     string
+	Currency   string
+	minorUnits float64
+}
+
+// ParseStockSymbol routes a stock symbol. A known exchange suffix decides the market; otherwise
+// codes starting with a digit are Taiwan stocks and the rest US stocks, so US share classes such
+// as BRK.B keep working.
+func ParseStockSymbol(symbol string) StockSymbol {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+
+	if dot := strings.LastIndex(symbol, "."); dot > 0 {
+		if exchange, ok := stockExchanges[symbol[dot+1:]]; ok {
+			return StockSymbol{Symbol: symbol, Market: exchange.market, Currency: exchange.currency, minorUnits: exchange.minorUnits}
+		}
+	}
+	if taiwanTickerPattern.MatchString(symbol) {
+		return StockSymbol{Symbol: symbol, Market: MarketTW, Currency: "TWD"}
+	}
+	return StockSymbol{Symbol: symbol, Market: MarketUS, Currency: "USD"}
+}
+
+// International reports whether the symbol trades outside Taiwan and the US
+func (s StockSymbol) International() bool {
+	return s.Market != MarketTW && s.Market != MarketUS
+}
+
+// price converts a price as quoted by the exchange into the symbol's currency
+func (s StockSymbol) price(quoted float64) float64 {
+	if s.minorUnits > 0 {
+		return quoted / s.minorUnits
+	}
+	return quoted
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStockSymbol(t *testing.T) {
+	tests := []struct {
+		symbol   string
+		want     string
+		market   string
+		currency string
+	}{
+		{" aapl ", "AAPL", MarketUS, "USD"},
+		{"BRK.B", "BRK.B", MarketUS, "USD"},
+		{"2330", "2330", MarketTW, "TWD"},
+		{"00679B", "00679B", MarketTW, "TWD"},
+		{"0700.hk", "0700.HK", MarketHK, "HKD"},
+		{"7203.T", "7203.T", MarketJP, "JPY"},
+		{"VWRL.L", "VWRL.L", MarketUK, "GBP"},
+		{"SAP.DE", "SAP.DE", MarketDE, "EUR"},
+	}
+
+	for _, tt := range tests {
+		parsed := ParseStockSymbol(tt.symbol)
+		assert.Equal(t, tt.want, parsed.Symbol, tt.symbol)
+		assert.Equal(t, tt.market, parsed.Market, tt.symbol)
+		assert.Equal(t, tt.currency, parsed.Currency, tt.symbol)
+	}
+
+	// London prices are quoted in pence
+	assert.Equal(t, 98.5, ParseStockSymbol("VWRL.L").price(9850))
+	assert.Equal(t, 9850.0, ParseStockSymbol("0700.HK").price(9850))
+
+	assert.Equal(t, PriceChainInternational, priceChainOf("stock", "0700.HK"))
+	assert.Equal(t, MarketUS, priceChainOf("stock", "BRK.B"))
+	assert.Equal(t, MarketCrypto, priceChainOf("crypto", "BTC"))
+}
@@ -0,0 +1,453 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"asset-diary/models"
+	"asset-diary/repositories"
+)
+
+const (
+	defaultSymbolSearchLimit = 10
+	// symbolSearchCandidates is how many matches are read from the database before ranking
+	symbolSearchCandidates = 100
+	defaultSymbolsDataFile = "data/symbols.csv"
+	SymbolExchangeBinance  = "BINANCE"
+)
+
+// taiwanSymbolExchanges names the Taiwan exchanges in the symbol master
+var taiwanSymbolExchanges = map[string]string{
+	TaiwanExchangeTWSE:     "TWSE",
+	TaiwanExchangeTPEx:     "TPEX",
+	TaiwanExchangeEmerging: "ESB",
+}
+
+// symbolsFileColumns are the columns of a symbol data file, in any order after the header row
+var symbolsFileColumns = []string{"asset_type", "symbol", "name_en", "name_zh", "exchange", "currency"}
+
+type SymbolServiceInterface interface {
+	SearchSymbols(query, assetType string, limit int) ([]models.Symbol, error)
+	RefreshSymbols() error
+	ImportSymbols(r io.Reader) (int, error)
+}
+
+type SymbolService struct {
+	repo       repositories.SymbolRepositoryInterface
+	httpClient *http.Client
+	dataFile   string
+}
+
+func NewSymbolService(repo repositories.SymbolRepositoryInterface) *SymbolService {
+	dataFile := os.Getenv("SYMBOLS_DATA_FILE")
+	if dataFile == "" {
+		dataFile = defaultSymbolsDataFile
+	}
+
+	return &SymbolService{
+		repo:       repo,
+		httpClient: &http.Client{},
+		dataFile:   dataFile,
+	}
+}
+
+// SearchSymbols returns the symbols best matching query by code or English or Chinese name,
+// tolerating small typos
+func (s *SymbolService) SearchSymbols(query, assetType string, limit int) ([]models.Symbol, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, models.NewAppError(models.ErrCodeInvalidRequest, "q is required")
+	}
+	if limit <= 0 {
+		limit = defaultSymbolSearchLimit
+	}
+
+	candidates, err := s.repo.SearchSymbols(query, assetType, symbolSearchCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := rankSymbols(query, candidates)
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked, nil
+}
+
+// RefreshSymbols reloads the symbol master from the TWSE and TPEx code lists, the FMP stock list
+// and the Binance spot markets, then applies the data file so its curated names win. Sources that
+// fail are reported together after trying all of them.
+func (s *SymbolService) RefreshSymbols() error {
+	var failed []string
+
+	sources := []struct {
+		name  string
+		fetch func() ([]models.Symbol, error)
+	}{
+		{"taiwan", s.fetchTaiwanSymbols},
+		{"fmp", s.fetchFMPSymbols},
+		{"binance", s.fetchBinanceSymbols},
+	}
+	for _, source := range sources {
+		symbols, err := source.fetch()
+		if err == nil {
+			err = s.repo.UpsertSymbols(symbols)
+		}
+		if err != nil {
+			log.Printf("Failed to refresh %s symbols: %v", source.name, err)
+			failed = append(failed, source.name)
+		}
+	}
+
+	file, err := os.Open(s.dataFile)
+	if err != nil {
+		log.Printf("Failed to open symbols data file %s: %v", s.dataFile, err)
+		failed = append(failed, s.dataFile)
+	} else {
+		defer file.Close()
+		if _, err := s.ImportSymbols(file); err != nil {
+			log.Printf("Failed to import symbols data file %s: %v", s.dataFile, err)
+			failed = append(failed, s.dataFile)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to refresh symbols from %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// ImportSymbols stores the symbols of a CSV data file with the header
+// asset_type,symbol,name_en,name_zh,exchange,currency. Exchange and currency may be left empty
+// for stocks and crypto quoted by the price service.
+func (s *SymbolService) ImportSymbols(r io.Reader) (int, error) {
+	symbols, err := parseSymbolsFile(r)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.repo.UpsertSymbols(symbols); err != nil {
+		return 0, err
+	}
+	return len(symbols), nil
+}
+
+func parseSymbolsFile(r io.Reader) ([]models.Symbol, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, models.NewAppError(models.ErrCodeInvalidRequest, "symbols file must start with a header row")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range symbolsFileColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, models.NewAppError(models.ErrCodeInvalidRequest, fmt.Sprintf("symbols file is missing the %s column", name))
+		}
+	}
+
+	var symbols []models.Symbol
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, models.NewAppError(models.ErrCodeInvalidRequest, fmt.Sprintf("line %d: %v", line, err))
+		}
+		field := func(name string) string {
+			return strings.TrimSpace(record[columns[name]])
+		}
+
+		symbol := models.Symbol{
+			AssetType: strings.ToLower(field("asset_type")),
+			Symbol:    strings.ToUpper(field("symbol")),
+			NameEn:    field("name_en"),
+			NameZh:    field("name_zh"),
+			Exchange:  strings.ToUpper(field("exchange")),
+			Currency:  strings.ToUpper(field("currency")),
+		}
+		if symbol.Symbol == "" {
+			return nil, models.NewAppError(models.ErrCodeInvalidRequest, fmt.Sprintf("line %d: symbol is required", line))
+		}
+		switch symbol.AssetType {
+		case "stock":
+			parsed := ParseStockSymbol(symbol.Symbol)
+			symbol.Exchange = firstNonEmpty(symbol.Exchange, parsed.Market)
+			symbol.Currency = firstNonEmpty(symbol.Currency, parsed.Currency)
+		case "crypto":
+			symbol.Exchange = firstNonEmpty(symbol.Exchange, SymbolExchangeBinance)
+			symbol.Currency = firstNonEmpty(symbol.Currency, "USDT")
+		default:
+			return nil, models.NewAppError(models.ErrCodeInvalidRequest, fmt.Sprintf("line %d: asset_type must be stock or crypto", line))
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// taiwanCodeLists are the daily open data lists of every code traded on each exchange
+var taiwanCodeLists = []struct {
+	exchange  string
+	url       string
+	codeField string
+	nameField string
+}{
+	{TaiwanExchangeTWSE, "https://openapi.twse.com.tw/v1/exchangeReport/STOCK_DAY_ALL", "Code", "Name"},
+	{TaiwanExchangeTPEx, "https://www.tpex.org.tw/openapi/v1/tpex_mainboard_daily_close_quotes", "SecuritiesCompanyCode", "CompanyName"},
+	{TaiwanExchangeEmerging, "https://www.tpex.org.tw/openapi/v1/tpex_esb_latest_statistics", "SecuritiesCompanyCode", "CompanyName"},
+}
+
+// fetchTaiwanSymbols reads the listed, OTC and emerging codes with their Chinese names
+func (s *SymbolService) fetchTaiwanSymbols() ([]models.Symbol, error) {
+	var symbols []models.Symbol
+	for _, list := range taiwanCodeLists {
+		rows, err := fetchTaiwanOpenData(s.httpClient, list.url)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			code := strings.ToUpper(openDataField(row, list.codeField))
+			if code == "" {
+				continue
+			}
+			symbols = append(symbols, models.Symbol{
+				AssetType: "stock",
+				Symbol:    code,
+				NameZh:    openDataField(row, list.nameField),
+				Exchange:  taiwanSymbolExchanges[list.exchange],
+				Currency:  "TWD",
+			})
+		}
+	}
+	return symbols, nil
+}
+
+// fetchFMPSymbols reads the US and international stocks of the FMP stock list. Taiwan codes come
+// from the Taiwan exchanges, and other markets the price service cannot quote are left out.
+func (s *SymbolService) fetchFMPSymbols() ([]models.Symbol, error) {
+	apiKey := os.Getenv("FMP_API_KEY")
+	resp, err := s.httpClient.Get(fmt.Sprintf("https://financialmodelingprep.com/stable/stock-list?apikey=%s", apiKey))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch FMP stock list: %s", resp.Status)
+	}
+
+	var list []struct {
+		Symbol      string `json:"symbol"`
+		CompanyName string `json:"companyName"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, err
+	}
+
+	symbols := make([]models.Symbol, 0, len(list))
+	for _, item := range list {
+		parsed := ParseStockSymbol(item.Symbol)
+		if parsed.Symbol == "" || parsed.Market == MarketTW || !quotableStockSymbol(parsed) {
+			continue
+		}
+		symbols = append(symbols, models.Symbol{
+			AssetType: "stock",
+			Symbol:    parsed.Symbol,
+			NameEn:    strings.TrimSpace(item.CompanyName),
+			Exchange:  parsed.Market,
+			Currency:  parsed.Currency,
+		})
+	}
+	return symbols, nil
+}
+
+// quotableStockSymbol rejects symbols of exchanges without a known suffix, which would otherwise
+// pass as US stocks. Single letter suffixes are US share classes such as BRK.B.
+func quotableStockSymbol(parsed StockSymbol) bool {
+	if parsed.Market != MarketUS {
+		return true
+	}
+	dot := strings.LastIndex(parsed.Symbol, ".")
+	return dot < 0 || len(parsed.Symbol)-dot-1 == 1
+}
+
+// fetchBinanceSymbols reads the assets trading against USDT, the pairs the price service quotes
+func (s *SymbolService) fetchBinanceSymbols() ([]models.Symbol, error) {
+	resp, err := s.httpClient.Get("https://data-api.binance.vision/api/v3/exchangeInfo?permissions=SPOT")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch Binance markets: %s", resp.Status)
+	}
+
+	var info struct {
+		Symbols []struct {
+			Status     string `json:"status"`
+			BaseAsset  string `json:"baseAsset"`
+			QuoteAsset string `json:"quoteAsset"`
+		} `json:"symbols"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, err
+	}
+
+	var symbols []models.Symbol
+	for _, market := range info.Symbols {
+		if market.QuoteAsset != "USDT" || market.Status != "TRADING" {
+			continue
+		}
+		symbols = append(symbols, models.Symbol{
+			AssetType: "crypto",
+			Symbol:    market.BaseAsset,
+			NameEn:    market.BaseAsset,
+			Exchange:  SymbolExchangeBinance,
+			Currency:  "USDT",
+		})
+	}
+	return symbols, nil
+}
+
+// rankSymbols orders the candidates by how well they match query: exact code, code prefix, exact
+// name, name prefix, name word prefix, name substring, then codes and name words within a typo or
+// two. Candidates the database found by trigram similarity alone come last. Ties prefer shorter
+// codes.
+func rankSymbols(query string, candidates []models.Symbol) []models.Symbol {
+	type scored struct {
+		symbol models.Symbol
+		score  int
+	}
+	matches := make([]scored, len(candidates))
+	for i, candidate := range candidates {
+		matches[i] = scored{candidate, symbolMatchScore(query, candidate)}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if len(a.symbol.Symbol) != len(b.symbol.Symbol) {
+			return len(a.symbol.Symbol) < len(b.symbol.Symbol)
+		}
+		return a.symbol.Symbol < b.symbol.Symbol
+	})
+
+	ranked := make([]models.Symbol, len(matches))
+	for i, match := range matches {
+		ranked[i] = match.symbol
+	}
+	return ranked
+}
+
+func symbolMatchScore(query string, symbol models.Symbol) int {
+	q := strings.ToLower(strings.TrimSpace(query))
+	code := strings.ToLower(symbol.Symbol)
+	names := []string{strings.ToLower(symbol.NameEn), strings.ToLower(symbol.NameZh)}
+
+	switch {
+	case code == q:
+		return 100
+	case strings.HasPrefix(code, q):
+		return 90
+	}
+
+	best := 0
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		switch {
+		case name == q:
+			best = max(best, 85)
+		case strings.HasPrefix(name, q):
+			best = max(best, 80)
+		case hasWordPrefix(name, q):
+			best = max(best, 70)
+		case strings.Contains(name, q):
+			best = max(best, 60)
+		}
+	}
+	if best > 0 {
+		return best
+	}
+
+	allowed := 1
+	if len([]rune(q)) > 4 {
+		allowed = 2
+	}
+	if d := editDistance(q, code); d <= allowed {
+		return 50 - 10*d
+	}
+	for _, word := range strings.FieldsFunc(names[0], isNameSeparator) {
+		if d := editDistance(q, word); d <= allowed {
+			return 40 - 10*d
+		}
+	}
+	return 1
+}
+
+func isNameSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+func hasWordPrefix(name, prefix string) bool {
+	for _, word := range strings.FieldsFunc(name, isNameSeparator) {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// editDistance is the optimal string alignment distance between a and b: insertions, deletions,
+// substitutions and swaps of adjacent characters each count as one edit
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"asset-diary/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSymbolRepository is a mock implementation of SymbolRepositoryInterface
+type MockSymbolRepository struct {
+	mock.Mock
+}
+
+func (m *MockSymbolRepository) UpsertSymbols(symbols []models.Symbol) error {
+	args := m.Called(symbols)
+	return args.Error(0)
+}
+
+func (m *MockSymbolRepository) SearchSymbols(query, assetType string, limit int) ([]models.Symbol, error) {
+	args := m.Called(query, assetType, limit)
+	return args.Get(0).([]models.Symbol), args.Error(1)
+}
+
+func (m *MockSymbolRepository) ListStocksByExchange(exchanges []string) ([]models.Symbol, error) {
+	args := m.Called(exchanges)
+	return args.Get(0).([]models.Symbol), args.Error(1)
+}
+
+func symbolCodes(symbols []models.Symbol) []string {
+	codes := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		codes[i] = symbol.Symbol
+	}
+	return codes
+}
+
+func TestSearchSymbols(t *testing.T) {
+	repo := new(MockSymbolRepository)
+	service := NewSymbolService(repo)
+
+	candidates := []models.Symbol{
+		{AssetType: "stock", Symbol: "AAPLX", NameEn: "Some Apple Fund"},
+		{AssetType: "stock", Symbol: "PAPL", NameEn: "Pineapple Energy"},
+		{AssetType: "stock", Symbol: "APLE", NameEn: "Apple Hospitality REIT"},
+		{AssetType: "stock", Symbol: "AAPL", NameEn: "Apple Inc.", NameZh: "蘋果"},
+	}
+	repo.On("SearchSymbols", "aapl", "stock", symbolSearchCandidates).Return(candidates, nil)
+	repo.On("SearchSymbols", "apple", "", symbolSearchCandidates).Return(candidates, nil)
+	repo.On("SearchSymbols", "APPL", "", symbolSearchCandidates).Return(candidates, nil)
+
+	results, err := service.SearchSymbols(" aapl ", "stock", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL", "AAPLX"}, symbolCodes(results[:2]))
+
+	// name prefixes beat word prefixes, which beat substrings
+	results, err = service.SearchSymbols("apple", "", 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL", "APLE", "AAPLX"}, symbolCodes(results))
+
+	// a swapped letter still finds the code
+	results, err = service.SearchSymbols("APPL", "", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL"}, symbolCodes(results))
+
+	_, err = service.SearchSymbols("  ", "", 0)
+	assert.IsType(t, &models.AppError{}, err)
+}
+
+func TestSymbolMatchScoreChineseName(t *testing.T) {
+	tsmc := models.Symbol{Symbol: "2330", NameEn: "Taiwan Semiconductor Manufacturing", NameZh: "台積電"}
+	adr := models.Symbol{Symbol: "TSM", NameEn: "Taiwan Semiconductor Manufacturing ADR", NameZh: "台積電ADR"}
+
+	assert.Equal(t, 80, symbolMatchScore("台積", tsmc))
+	assert.Equal(t, []string{"2330", "TSM"}, symbolCodes(rankSymbols("台積電", []models.Symbol{adr, tsmc})))
+	assert.Equal(t, 70, symbolMatchScore("semi", tsmc))
+}
+
+func TestImportSymbols(t *testing.T) {
+	repo := new(MockSymbolRepository)
+	service := NewSymbolService(repo)
+	repo.On("UpsertSymbols", mock.Anything).Return(nil)
+
+	imported, err := service.ImportSymbols(strings.NewReader(
+		"symbol,asset_type,name_en,name_zh,exchange,currency\n" +
+			"2330,stock,Taiwan Semiconductor Manufacturing,台積電,TWSE,TWD\n" +
+			"0700.hk,stock,Tencent Holdings,騰訊控股,,\n" +
+			"btc,crypto,Bitcoin,比特幣,,\n"))
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, imported)
+	repo.AssertCalled(t, "UpsertSymbols", []models.Symbol{
+		{AssetType: "stock", Symbol: "2330", NameEn: "Taiwan Semiconductor Manufacturing", NameZh: "台積電", Exchange: "TWSE", Currency: "TWD"},
+		{AssetType: "stock", Symbol: "0700.HK", NameEn: "Tencent Holdings", NameZh: "騰訊控股", Exchange: MarketHK, Currency: "HKD"},
+		{AssetType: "crypto", Symbol: "BTC", NameEn: "Bitcoin", NameZh: "比特幣", Exchange: SymbolExchangeBinance, Currency: "USDT"},
+	})
+
+	_, err = service.ImportSymbols(strings.NewReader("asset_type,symbol,name_en,name_zh,exchange,currency\nbond,X,,,,\n"))
+	assert.EqualError(t, err, "line 2: asset_type must be stock or crypto")
+
+	_, err = service.ImportSymbols(strings.NewReader("asset_type,symbol\nstock,AAPL\n"))
+	assert.EqualError(t, err, "symbols file is missing the name_en column")
+}
+
+func TestImportSymbolsDataFile(t *testing.T) {
+	repo := new(MockSymbolRepository)
+	service := NewSymbolService(repo)
+	repo.On("UpsertSymbols", mock.Anything).Return(nil)
+
+	file, err := os.Open("../data/symbols.csv")
+	require.NoError(t, err)
+	defer file.Close()
+
+	imported, err := service.ImportSymbols(file)
+	require.NoError(t, err)
+	assert.Positive(t, imported)
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("aapl", "aapl"))
+	assert.Equal(t, 1, editDistance("appl", "apl"))
+	assert.Equal(t, 1, editDistance("apal", "aapl"))
+	assert.Equal(t, 2, editDistance("nvda", "nvidia"))
+	assert.Equal(t, 1, editDistance("台機電", "台積電"))
+}
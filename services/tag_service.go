@@ -0,0 +1,65 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"asset-diary/models"
+	"asset-diary/repositories"
+
+	"gorm.io/gorm"
+)
+
+type TagServiceInterface interface {
+	ListTags(userID string) ([]models.Tag, error)
+	CreateTag(userID string, req models.TagCreateRequest) (*models.Tag, error)
+	UpdateTag(userID, tagID string, req models.TagUpdateRequest) (*models.Tag, error)
+	DeleteTag(userID, tagID string) (bool, error)
+}
+
+type TagService struct {
+	repo repositories.TagRepositoryInterface
+}
+
+// NewTagService creates a new TagService instance with a repository
+func NewTagService(repo repositories.TagRepositoryInterface) *TagService {
+	return &TagService{repo: repo}
+}
+
+func (s *TagService) ListTags(userID string) ([]models.Tag, error) {
+	return s.repo.ListTags(userID)
+}
+
+func (s *TagService) CreateTag(userID string, req models.TagCreateRequest) (*models.Tag, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, models.NewAppError(models.ErrCodeInvalidRequest, "Tag name is required")
+	}
+
+	tag, err := s.repo.CreateTag(userID, models.Tag{
+		Name:        name,
+		Color:       req.Color,
+		Description: req.Description,
+	})
+	if models.IsDuplicateError(err, "idx_tags_user_id_lower_name") {
+		return nil, models.NewAppError(models.ErrCodeInvalidRequest, "A tag with this name already exists")
+	}
+	return tag, err
+}
+
+func (s *TagService) UpdateTag(userID, tagID string, req models.TagUpdateRequest) (*models.Tag, error) {
+	req.Name = strings.TrimSpace(req.Name)
+
+	tag, err := s.repo.UpdateTag(userID, tagID, req)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.NewAppError(models.ErrCodeNotFound, "Tag not found")
+	}
+	if models.IsDuplicateError(err, "idx_tags_user_id_lower_name") {
+		return nil, models.NewAppError(models.ErrCodeInvalidRequest, "A tag with this name already exists")
+	}
+	return tag, err
+}
+
+func (s *TagService) DeleteTag(userID, tagID string) (bool, error) {
+	return s.repo.DeleteTag(userID, tagID)
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"asset-diary/repositories"
+)
+
+// Taiwan exchanges, named after their TWSE MIS channel prefixes
+const (
+	TaiwanExchangeTWSE     = "tse" // listed on the Taiwan Stock Exchange
+	TaiwanExchangeTPEx     = "otc" // traded over the counter on TPEx
+	TaiwanExchangeEmerging = "esb" // TPEx emerging stock board
+)
+
+// taiwanSymbolMasterTTL is how long the exchanges read from the symbol master are used before
+// they are read again
+const taiwanSymbolMasterTTL = time.Hour
+
+// TaiwanSymbolMaster knows which exchange each Taiwan code trades on. It is filled from the Taiwan
+// stocks of the symbols table, which the symbol refresh keeps up to date with the daily code lists
+// of TWSE and TPEx, and from the exchange reported with each quote.
+type TaiwanSymbolMaster struct {
+	mu        sync.Mutex
+	exchanges map[string]string
+	loadedAt  time.Time
+	loading   bool
+	load      func() (map[string]string, error)
+}
+
+func NewTaiwanSymbolMaster(symbolRepo repositories.SymbolRepositoryInterface) *TaiwanSymbolMaster {
+	return &TaiwanSymbolMaster{
+		exchanges: make(map[string]string),
+		load: func() (map[string]string, error) {
+			return loadTaiwanExchanges(symbolRepo)
+		},
+	}
+}
+
+// Exchange returns the exchange of code if known. Outdated exchanges are read again in the
+// background, so the lookup never waits on the database.
+func (m *TaiwanSymbolMaster) Exchange(code string) (string, bool) {
+	m.mu.Lock()
+	if !m.loading && time.Since(m.loadedAt) > taiwanSymbolMasterTTL {
+		m.loading = true
+		go m.refresh()
+	}
+	exchange, ok := m.exchanges[code]
+	m.mu.Unlock()
+	return exchange, ok
+}
+
+// Remember records the exchange a code was quoted on
+func (m *TaiwanSymbolMaster) Remember(code, exchange string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.exchanges[code] = exchange
+}
+
+func (m *TaiwanSymbolMaster) refresh() {
+	exchanges, err := m.load()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.loading = false
+	m.loadedAt = time.Now()
+	if err != nil {
+		log.Printf("Failed to load Taiwan symbol master: %v", err)
+		return
+	}
+	for code, exchange := range exchanges {
+		m.exchanges[code] = exchange
+	}
+}
+
+// loadTaiwanExchanges reads the exchange of every Taiwan stock in the symbol master
+func loadTaiwanExchanges(symbolRepo repositories.SymbolRepositoryInterface) (map[string]string, error) {
+	channels := make(map[string]string, len(taiwanSymbolExchanges))
+	names := make([]string, 0, len(taiwanSymbolExchanges))
+	for channel, name := range taiwanSymbolExchanges {
+		channels[name] = channel
+		names = append(names, name)
+	}
+
+	symbols, err := symbolRepo.ListStocksByExchange(names)
+	if err != nil {
+		return nil, err
+	}
+
+	exchanges := make(map[string]string, len(symbols))
+	for _, symbol := range symbols {
+		exchanges[symbol.Symbol] = channels[symbol.Exchange]
+	}
+	return exchanges, nil
+}
+
+// fetchTaiwanOpenData reads a TWSE or TPEx open data list
+func fetchTaiwanOpenData(httpClient *http.Client, url string) ([]map[string]any, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", url, resp.Status)
+	}
+
+	var rows []map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// openDataField returns a field of an open data row as trimmed text, whether it came as a string
+// or a number
+func openDataField(row map[string]any, field string) string {
+	switch v := row[field].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// taiwanStockChannels returns the TWSE MIS channels to query for symbols: the known exchange's
+// channel, or both the listed and OTC channels for codes not in the master, which are also
+// returned as unknown. Emerging stocks, which MIS does not quote, are returned separately.
+func taiwanStockChannels(symbols []string, master *TaiwanSymbolMaster) (channels, emerging, unknown []string) {
+	for _, symbol := range symbols {
+		exchange, known := master.Exchange(symbol)
+		switch {
+		case !known:
+			channels = append(channels,
+				fmt.Sprintf("%s_%s.tw", TaiwanExchangeTWSE, symbol),
+				fmt.Sprintf("%s_%s.tw", TaiwanExchangeTPEx, symbol))
+			unknown = append(unknown, symbol)
+		case exchange == TaiwanExchangeEmerging:
+			emerging = append(emerging, symbol)
+		default:
+			channels = append(channels, fmt.Sprintf("%s_%s.tw", exchange, symbol))
+		}
+	}
+	return channels, emerging, unknown
+}
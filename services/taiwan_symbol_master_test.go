@@ -0,0 +1,68 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"asset-diary/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaiwanStockChannels(t *testing.T) {
+	loaded := make(chan struct{})
+	master := &TaiwanSymbolMaster{
+		exchanges: map[string]string{},
+		load: func() (map[string]string, error) {
+			defer close(loaded)
+			return map[string]string{"2330": TaiwanExchangeTWSE, "6488": TaiwanExchangeTPEx, "7795": TaiwanExchangeEmerging}, nil
+		},
+	}
+
+	// the first lookup starts loading the code lists without waiting for them
+	_, known := master.Exchange("2330")
+	assert.False(t, known)
+	select {
+	case <-loaded:
+	case <-time.After(time.Second):
+		t.Fatal("symbol master was not loaded")
+	}
+	assert.Eventually(t, func() bool {
+		_, known := master.Exchange("2330")
+		return known
+	}, time.Second, 10*time.Millisecond)
+
+	master.Remember("00679B", TaiwanExchangeTPEx)
+
+	channels, emerging, unknown := taiwanStockChannels([]string{"2330", "6488", "00679B", "7795", "1234"}, master)
+
+	assert.Equal(t, []string{"tse_2330.tw", "otc_6488.tw", "otc_00679B.tw", "tse_1234.tw", "otc_1234.tw"}, channels)
+	assert.Equal(t, []string{"7795"}, emerging)
+	assert.Equal(t, []string{"1234"}, unknown)
+}
+
+func TestOpenDataField(t *testing.T) {
+	row := map[string]any{"Code": " 2330 ", "Close": 1025.5, "Missing": nil}
+
+	assert.Equal(t, "2330", openDataField(row, "Code"))
+	assert.Equal(t, "1025.5", openDataField(row, "Close"))
+	assert.Equal(t, "", openDataField(row, "Missing"))
+}
+
+func TestLoadTaiwanExchanges(t *testing.T) {
+	repo := new(MockSymbolRepository)
+	repo.On("ListStocksByExchange", mock.MatchedBy(func(exchanges []string) bool {
+		return assert.ElementsMatch(t, []string{"TWSE", "TPEX", "ESB"}, exchanges)
+	})).Return([]models.Symbol{
+		{Symbol: "2330", Exchange: "TWSE"},
+		{Symbol: "6488", Exchange: "TPEX"},
+		{Symbol: "7795", Exchange: "ESB"},
+	}, nil)
+
+	exchanges, err := loadTaiwanExchanges(repo)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"2330": TaiwanExchangeTWSE, "6488": TaiwanExchangeTPEx, "7795": TaiwanExchangeEmerging}, exchanges)
+}
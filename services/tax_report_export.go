@@ -0,0 +1,135 @@
+package services
+
+import (
+	"encoding/csv"
+	"html/template"
+	"io"
+	"strconv"
+	"time"
+
+	"asset-diary/models"
+)
+
+var taxReportCSVHeader = []string{
+	"section", "type", "account", "asset_type", "ticker", "foreign_source",
+	"acquisition_date", "date", "quantity", "currency", "cost", "proceeds", "amount",
+	"cost_twd", "proceeds_twd", "amount_twd",
+}
+
+// WriteTaxReportCSV writes the realized lots, income and fees as one table; the section column
+// tells them apart and columns that do not apply to a row are left empty
+func WriteTaxReportCSV(w io.Writer, report *models.TaxReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(taxReportCSVHeader); err != nil {
+		return err
+	}
+
+	for _, lot := range report.RealizedLots {
+		record := []string{
+			"realized_gain", "sell", lot.AccountName, lot.AssetType, lot.Ticker, strconv.FormatBool(lot.ForeignSource),
+			formatDate(lot.AcquisitionDate), formatDate(lot.DisposalDate), formatAmount(lot.Quantity), lot.Currency,
+			formatAmount(lot.Cost), formatAmount(lot.Proceeds), formatAmount(lot.Gain),
+			formatOptionalAmount(lot.CostTWD), formatOptionalAmount(lot.ProceedsTWD), formatOptionalAmount(lot.GainTWD),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writeItems := func(section string, items []models.TaxCashItem) error {
+		for _, item := range items {
+			record := []string{
+				section, item.Type, item.AccountName, item.AssetType, item.Ticker, strconv.FormatBool(item.ForeignSource),
+				"", formatDate(item.Date), "", item.Currency,
+				"", "", formatAmount(item.Amount),
+				"", "", formatOptionalAmount(item.AmountTWD),
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := writeItems("income", report.Income); err != nil {
+		return err
+	}
+	if err := writeItems("fee", report.Fees); err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+var taxReportTemplate = template.Must(template.New("tax-report").Funcs(template.FuncMap{
+	"date":     formatDate,
+	"amount":   formatAmount,
+	"optional": formatOptionalAmount,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Tax report {{.Year}}</title>
+<style>
+body { font-family: sans-serif; font-size: 12px; margin: 24px; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 24px; }
+th, td { border: 1px solid #999; padding: 4px 6px; }
+td.number { text-align: right; }
+@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>Tax report {{.Year}}</h1>
+<p>Amounts in {{.Currency}} are converted at the exchange rate of each date.{{if .UnconvertedCurrencies}} No rate was found for: {{range $i, $c := .UnconvertedCurrencies}}{{if $i}}, {{end}}{{$c}}{{end}}.{{end}}</p>
+
+<h2>Summary ({{.Currency}})</h2>
+<table>
+<tr><th>Realized gain</th><td class="number">{{amount .Summary.RealizedGain}}</td></tr>
+<tr><th>Domestic realized gain</th><td class="number">{{amount .Summary.DomesticRealizedGain}}</td></tr>
+<tr><th>Foreign realized gain</th><td class="number">{{amount .Summary.ForeignRealizedGain}}</td></tr>
+<tr><th>Dividend income</th><td class="number">{{amount .Summary.DividendIncome}}</td></tr>
+<tr><th>Interest income</th><td class="number">{{amount .Summary.InterestIncome}}</td></tr>
+<tr><th>Fees</th><td class="number">{{amount .Summary.Fees}}</td></tr>
+<tr><th>Overseas income</th><td class="number">{{amount .Summary.ForeignIncome}}</td></tr>
+</table>
+
+<h2>Realized gains</h2>
+<table>
+<tr><th>Account</th><th>Ticker</th><th>Foreign</th><th>Acquired</th><th>Disposed</th><th>Quantity</th><th>Currency</th><th>Cost</th><th>Proceeds</th><th>Gain</th><th>Cost ({{.Currency}})</th><th>Proceeds ({{.Currency}})</th><th>Gain ({{.Currency}})</th></tr>
+{{range .RealizedLots}}<tr><td>{{.AccountName}}</td><td>{{.Ticker}}</td><td>{{if .ForeignSource}}yes{{end}}</td><td>{{date .AcquisitionDate}}</td><td>{{date .DisposalDate}}</td><td class="number">{{amount .Quantity}}</td><td>{{.Currency}}</td><td class="number">{{amount .Cost}}</td><td class="number">{{amount .Proceeds}}</td><td class="number">{{amount .Gain}}</td><td class="number">{{optional .CostTWD}}</td><td class="number">{{optional .ProceedsTWD}}</td><td class="number">{{optional .GainTWD}}</td></tr>
+{{end}}</table>
+
+<h2>Income</h2>
+<table>
+<tr><th>Date</th><th>Type</th><th>Account</th><th>Ticker</th><th>Foreign</th><th>Currency</th><th>Amount</th><th>Amount ({{.Currency}})</th></tr>
+{{range .Income}}<tr><td>{{date .Date}}</td><td>{{.Type}}</td><td>{{.AccountName}}</td><td>{{.Ticker}}</td><td>{{if .ForeignSource}}yes{{end}}</td><td>{{.Currency}}</td><td class="number">{{amount .Amount}}</td><td class="number">{{optional .AmountTWD}}</td></tr>
+{{end}}</table>
+
+<h2>Fees</h2>
+<table>
+<tr><th>Date</th><th>Account</th><th>Currency</th><th>Amount</th><th>Amount ({{.Currency}})</th></tr>
+{{range .Fees}}<tr><td>{{date .Date}}</td><td>{{.AccountName}}</td><td>{{.Currency}}</td><td class="number">{{amount .Amount}}</td><td class="number">{{optional .AmountTWD}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// WriteTaxReportHTML renders the report as a standalone page meant to be printed
+func WriteTaxReportHTML(w io.Writer, report *models.TaxReport) error {
+	return taxReportTemplate.Execute(w, report)
+}
+
+func formatDate(date time.Time) string {
+	return date.Format("2006-01-02")
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+func formatOptionalAmount(amount *float64) string {
+	if amount == nil {
+		return ""
+	}
+	return formatAmount(*amount)
+}
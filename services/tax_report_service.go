@@ -0,0 +1,233 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"asset-diary/models"
+)
+
+// taxReportCurrency is the currency of Taiwan's overseas income declaration
+const taxReportCurrency = "TWD"
+
+type TaxReportServiceInterface interface {
+	GetTaxReport(userID string, year int) (*models.TaxReport, error)
+}
+
+type TaxReportService struct {
+	tradeSvc    TradeServiceInterface
+	accountSvc  AccountServiceInterface
+	exchangeSvc ExchangeRateServiceInterface
+}
+
+func NewTaxReportService(
+	tradeSvc TradeServiceInterface,
+	accountSvc AccountServiceInterface,
+	exchangeSvc ExchangeRateServiceInterface,
+) *TaxReportService {
+	return &TaxReportService{
+		tradeSvc:    tradeSvc,
+		accountSvc:  accountSvc,
+		exchangeSvc: exchangeSvc,
+	}
+}
+
+// GetTaxReport lists the realized gains, income and fees of a calendar year in TWD
+func (s *TaxReportService) GetTaxReport(userID string, year int) (*models.TaxReport, error) {
+	if year < 1900 || year > time.Now().Year() {
+		return nil, models.NewAppError(models.ErrCodeInvalidRequest, "Invalid year")
+	}
+
+	trades, err := s.tradeSvc.ListTrades(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.accountSvc.ListAccounts(userID)
+	if err != nil {
+		return nil, err
+	}
+	accountNames := make(map[string]string, len(accounts))
+	for _, account := range accounts {
+		accountNames[account.ID] = account.Name
+	}
+
+	yearStart := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	yearEnd := time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC)
+	txns, err := s.accountSvc.ListUserTransactions(userID, yearStart, yearEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	historicalRates := make(map[string]map[string]float64)
+	ratesOn := func(date time.Time) map[string]float64 {
+		day := date.Format("2006-01-02")
+		if cached, ok := historicalRates[day]; ok {
+			return cached
+		}
+		// no fallback to the current rates: amounts without the rates of their own day are left
+		// unconverted and their currency reported, rather than declared at the wrong rate
+		dayRates, err := s.exchangeSvc.GetRecordedRates(taxReportCurrency, date)
+		if err != nil {
+			log.Printf("Error getting exchange rates on %s: %v", day, err)
+		}
+		historicalRates[day] = dayRates
+		return dayRates
+	}
+
+	return buildTaxReport(year, trades, txns, accountNames, ratesOn), nil
+}
+
+func buildTaxReport(
+	year int,
+	trades []models.Trade,
+	txns []models.AccountTransaction,
+	accountNames map[string]string,
+	ratesOn func(time.Time) map[string]float64,
+) *models.TaxReport {
+	report := &models.TaxReport{
+		Year:         year,
+		Currency:     taxReportCurrency,
+		RealizedLots: []models.TaxRealizedLot{},
+		Income:       []models.TaxCashItem{},
+		Fees:         []models.TaxCashItem{},
+	}
+	unconverted := map[string]bool{}
+
+	toTWD := func(amount float64, currency string, date time.Time) *float64 {
+		value, ok := convertWithRates(amount, currency, taxReportCurrency, ratesOn(date))
+		if !ok {
+			unconverted[currency] = true
+			return nil
+		}
+		return &value
+	}
+
+	// sells are matched per account, like the holdings of ListAccountHoldings
+	tradesMap := make(map[string][]models.Trade)
+	for _, trade := range trades {
+		key := fmt.Sprintf("%s_%s_%s_%s", trade.AccountID, trade.AssetType, trade.Ticker, trade.Currency)
+		tradesMap[key] = append(tradesMap[key], trade)
+	}
+
+	for _, accountTrades := range tradesMap {
+		holding, err := calculateHolding(append([]models.Trade(nil), accountTrades...))
+		if err != nil {
+			log.Printf("Error calculating realized gains for trade %s: %v", accountTrades[0].ID, err)
+			continue
+		}
+
+		accountID := accountTrades[0].AccountID
+		market := marketOf(holding.AssetType, holding.Ticker)
+		for _, lot := range holding.RealizedLots {
+			if lot.DisposalDate.Year() != year {
+				continue
+			}
+
+			realized := models.TaxRealizedLot{
+				AccountID:       accountID,
+				AccountName:     accountNames[accountID],
+				AssetType:       holding.AssetType,
+				Ticker:          holding.Ticker,
+				TickerName:      holding.TickerName,
+				Market:          market,
+				ForeignSource:   market != MarketTW,
+				AcquisitionDate: lot.AcquisitionDate,
+				DisposalDate:    lot.DisposalDate,
+				Quantity:        lot.Quantity,
+				Currency:        holding.Currency,
+				Cost:            lot.Quantity * lot.CostPrice,
+				Proceeds:        lot.Quantity * lot.SalePrice,
+			}
+			realized.Gain = realized.Proceeds - realized.Cost
+			realized.CostTWD = toTWD(realized.Cost, realized.Currency, realized.AcquisitionDate)
+			realized.ProceedsTWD = toTWD(realized.Proceeds, realized.Currency, realized.DisposalDate)
+			if realized.CostTWD != nil && realized.ProceedsTWD != nil {
+				gain := *realized.ProceedsTWD - *realized.CostTWD
+				realized.GainTWD = &gain
+
+				report.Summary.RealizedGain += gain
+				if realized.ForeignSource {
+					report.Summary.ForeignRealizedGain += gain
+				} else {
+					report.Summary.DomesticRealizedGain += gain
+				}
+			}
+			report.RealizedLots = append(report.RealizedLots, realized)
+		}
+	}
+
+	for _, txn := range txns {
+		if !txn.IsIncome() && txn.Type != models.AccountTransactionTypeFee {
+			continue
+		}
+
+		item := models.TaxCashItem{
+			Date:          txn.TransactionDate,
+			Type:          txn.Type,
+			AccountID:     txn.AccountID,
+			AccountName:   accountNames[txn.AccountID],
+			ForeignSource: txn.Currency != taxReportCurrency,
+			Currency:      txn.Currency,
+			Amount:        txn.Amount,
+			Note:          txn.Note,
+		}
+		if txn.Ticker != nil && txn.AssetType != nil {
+			item.Ticker = *txn.Ticker
+			item.AssetType = *txn.AssetType
+			item.ForeignSource = marketOf(item.AssetType, item.Ticker) != MarketTW
+		}
+		item.AmountTWD = toTWD(item.Amount, item.Currency, item.Date)
+
+		if txn.Type == models.AccountTransactionTypeFee {
+			report.Fees = append(report.Fees, item)
+			if item.AmountTWD != nil {
+				report.Summary.Fees += *item.AmountTWD
+			}
+			continue
+		}
+
+		report.Income = append(report.Income, item)
+		if item.AmountTWD == nil {
+			continue
+		}
+		if txn.Type == models.AccountTransactionTypeDividend {
+			report.Summary.DividendIncome += *item.AmountTWD
+		} else {
+			report.Summary.InterestIncome += *item.AmountTWD
+		}
+		if item.ForeignSource {
+			report.Summary.ForeignIncome += *item.AmountTWD
+		}
+	}
+	report.Summary.ForeignIncome += report.Summary.ForeignRealizedGain
+
+	sort.Slice(report.RealizedLots, func(i, j int) bool {
+		a, b := report.RealizedLots[i], report.RealizedLots[j]
+		if !a.DisposalDate.Equal(b.DisposalDate) {
+			return a.DisposalDate.Before(b.DisposalDate)
+		}
+		if a.Ticker != b.Ticker {
+			return a.Ticker < b.Ticker
+		}
+		return a.AcquisitionDate.Before(b.AcquisitionDate)
+	})
+	sortCashItems(report.Income)
+	sortCashItems(report.Fees)
+
+	report.UnconvertedCurrencies = make([]string, 0, len(unconverted))
+	for currency := range unconverted {
+		report.UnconvertedCurrencies = append(report.UnconvertedCurrencies, currency)
+	}
+	sort.Strings(report.UnconvertedCurrencies)
+
+	return report
+}
+
+func sortCashItems(items []models.TaxCashItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Date.Before(items[j].Date)
+	})
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"asset-diary/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTaxReport(t *testing.T) {
+	trades := []models.Trade{
+		{ID: "t1", AccountID: "acc-1", Type: "buy", AssetType: "stock", Ticker: "2330", Quantity: 1000, Price: 500, Currency: "TWD", TradeDate: date(2025, 3, 1)},
+		{ID: "t2", AccountID: "acc-1", Type: "buy", AssetType: "stock", Ticker: "2330", Quantity: 1000, Price: 600, Currency: "TWD", TradeDate: date(2026, 1, 5)},
+		{ID: "t3", AccountID: "acc-1", Type: "sell", AssetType: "stock", Ticker: "2330", Quantity: 1500, Price: 700, Currency: "TWD", TradeDate: date(2026, 6, 1)},
+		{ID: "t4", AccountID: "acc-2", Type: "buy", AssetType: "stock", Ticker: "AAPL", Quantity: 10, Price: 100, Currency: "USD", TradeDate: date(2025, 6, 1)},
+		{ID: "t5", AccountID: "acc-2", Type: "sell", AssetType: "stock", Ticker: "AAPL", Quantity: 10, Price: 150, Currency: "USD", TradeDate: date(2026, 2, 1)},
+		// sold in the previous year, not part of the report
+		{ID: "t6", AccountID: "acc-2", Type: "buy", AssetType: "stock", Ticker: "MSFT", Quantity: 5, Price: 300, Currency: "USD", TradeDate: date(2024, 6, 1)},
+		{ID: "t7", AccountID: "acc-2", Type: "sell", AssetType: "stock", Ticker: "MSFT", Quantity: 5, Price: 350, Currency: "USD", TradeDate: date(2025, 6, 1)},
+	}
+	txns := []models.AccountTransaction{
+		dividend("acc-2", "AAPL", date(2026, 2, 13), 10, "USD"),
+		dividend("acc-1", "2330", date(2026, 1, 15), 4500, "TWD"),
+		{AccountID: "acc-2", Type: models.AccountTransactionTypeInterest, Amount: 2, Currency: "USD", TransactionDate: date(2026, 3, 31)},
+		{AccountID: "acc-2", Type: models.AccountTransactionTypeFee, Amount: 5, Currency: "USD", TransactionDate: date(2026, 3, 31)},
+		{AccountID: "acc-1", Type: models.AccountTransactionTypeDeposit, Amount: 100000, Currency: "TWD", TransactionDate: date(2026, 1, 2)},
+		dividend("acc-3", "7203", date(2026, 4, 1), 100, "JPY"),
+	}
+	// the US dollar is worth 30 TWD in 2025 and 32 TWD in 2026
+	ratesOn := func(day time.Time) map[string]float64 {
+		if day.Year() == 2025 {
+			return map[string]float64{"USD": 1.0 / 30}
+		}
+		return map[string]float64{"USD": 1.0 / 32}
+	}
+
+	report := buildTaxReport(2026, trades, txns, map[string]string{"acc-1": "Broker", "acc-2": "US Broker"}, ratesOn)
+
+	assert.Equal(t, 2026, report.Year)
+	assert.Equal(t, "TWD", report.Currency)
+	require.Len(t, report.RealizedLots, 3)
+
+	aapl := report.RealizedLots[0]
+	assert.Equal(t, "AAPL", aapl.Ticker)
+	assert.True(t, aapl.ForeignSource)
+	assert.InDelta(t, 500, aapl.Gain, 1e-9)
+	assert.InDelta(t, 30000, *aapl.CostTWD, 1e-6)
+	assert.InDelta(t, 48000, *aapl.ProceedsTWD, 1e-6)
+	assert.InDelta(t, 18000, *aapl.GainTWD, 1e-6)
+
+	// the sale of 2330 is matched against the 2025 lot first, then half of the 2026 lot
+	first, second := report.RealizedLots[1], report.RealizedLots[2]
+	assert.Equal(t, date(2025, 3, 1), first.AcquisitionDate)
+	assert.InDelta(t, 1000, first.Quantity, 1e-9)
+	assert.InDelta(t, 200000, *first.GainTWD, 1e-6)
+	assert.Equal(t, date(2026, 1, 5), second.AcquisitionDate)
+	assert.InDelta(t, 500, second.Quantity, 1e-9)
+	assert.InDelta(t, 50000, *second.GainTWD, 1e-6)
+	assert.False(t, second.ForeignSource)
+	assert.Equal(t, "Broker", second.AccountName)
+
+	require.Len(t, report.Income, 4)
+	assert.Equal(t, "2330", report.Income[0].Ticker)
+	assert.False(t, report.Income[0].ForeignSource)
+	assert.True(t, report.Income[1].ForeignSource)
+	assert.InDelta(t, 320, *report.Income[1].AmountTWD, 1e-6)
+	assert.Nil(t, report.Income[3].AmountTWD)
+	assert.Equal(t, []string{"JPY"}, report.UnconvertedCurrencies)
+
+	require.Len(t, report.Fees, 1)
+	assert.InDelta(t, 160, *report.Fees[0].AmountTWD, 1e-6)
+
+	assert.InDelta(t, 268000, report.Summary.RealizedGain, 1e-6)
+	assert.InDelta(t, 250000, report.Summary.DomesticRealizedGain, 1e-6)
+	assert.InDelta(t, 18000, report.Summary.ForeignRealizedGain, 1e-6)
+	assert.InDelta(t, 4820, report.Summary.DividendIncome, 1e-6)
+	assert.InDelta(t, 64, report.Summary.InterestIncome, 1e-6)
+	assert.InDelta(t, 160, report.Summary.Fees, 1e-6)
+	assert.InDelta(t, 18000+320+64, report.Summary.ForeignIncome, 1e-6)
+}
+
+func TestWriteTaxReportCSV(t *testing.T) {
+	gain := 18000.0
+	report := &models.TaxReport{
+		Year:     2026,
+		Currency: "TWD",
+		RealizedLots: []models.TaxRealizedLot{
+			{AccountName: "US Broker", AssetType: "stock", Ticker: "AAPL", ForeignSource: true, AcquisitionDate: date(2025, 6, 1), DisposalDate: date(2026, 2, 1),
+				Quantity: 10, Currency: "USD", Cost: 1000, Proceeds: 1500, Gain: 500, GainTWD: &gain},
+		},
+		Fees: []models.TaxCashItem{
+			{Date: date(2026, 3, 31), Type: models.AccountTransactionTypeFee, AccountName: "US Broker", ForeignSource: true, Currency: "USD", Amount: 5},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTaxReportCSV(&buf, report))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[0], "section,type,account"))
+	assert.Equal(t, "realized_gain,sell,US Broker,stock,AAPL,true,2025-06-01,2026-02-01,10.00,USD,1000.00,1500.00,500.00,,,18000.00", lines[1])
+	assert.Equal(t, "fee,fee,US Broker,,,true,,2026-03-31,,USD,,,5.00,,,", lines[2])
+}
+
+func TestWriteTaxReportHTML(t *testing.T) {
+	report := &models.TaxReport{
+		Year:                  2026,
+		Currency:              "TWD",
+		Income:                []models.TaxCashItem{{Date: date(2026, 1, 15), Type: models.AccountTransactionTypeDividend, AccountName: "<Broker>", Currency: "TWD", Amount: 4500}},
+		UnconvertedCurrencies: []string{"JPY"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTaxReportHTML(&buf, report))
+
+	page := buf.String()
+	assert.Contains(t, page, "<h1>Tax report 2026</h1>")
+	assert.Contains(t, page, "&lt;Broker&gt;")
+	assert.Contains(t, page, "No rate was found for: JPY.")
+}
@@ -1,26 +1,33 @@
 package services
 
 import (
+	"log"
+	"time"
+
 	"asset-diary/models"
 	"asset-diary/repositories"
 )
 
 type TradeServiceInterface interface {
 	ListTrades(userID string) ([]models.Trade, error)
+	ListTradesByTag(userID, tag string) ([]models.Trade, error)
 	CreateTrade(userID string, trade models.Trade) (*models.Trade, error)
 	UpdateTrade(userID, tradeID string, req models.TradeUpdateRequest) (*models.Trade, error)
 	DeleteTrade(userID, tradeID string) (bool, error)
 	IsAccountOwnedByUser(accountID, userID string) (bool, error)
 	IsTradeOwnedByUser(tradeID, userID string) (bool, error)
+	AreTagsOwnedByUser(tagIDs []string, userID string) (bool, error)
 }
 
 type TradeService struct {
-	repo repositories.TradeRepositoryInterface
+	repo           repositories.TradeRepositoryInterface
+	snapshotJobSvc SnapshotJobServiceInterface
 }
 
-// NewTradeService creates a new TradeService instance with a repository
-func NewTradeService(repo repositories.TradeRepositoryInterface) *TradeService {
-	return &TradeService{repo: repo}
+// NewTradeService creates a new TradeService instance with a repository. Changes to past trades
+// queue a recalculation of the daily snapshots they affect.
+func NewTradeService(repo repositories.TradeRepositoryInterface, snapshotJobSvc SnapshotJobServiceInterface) *TradeService {
+	return &TradeService{repo: repo, snapshotJobSvc: snapshotJobSvc}
 }
 
 // ListTrades retrieves all trades for a given user
@@ -28,16 +35,81 @@ func (s *TradeService) ListTrades(userID string) ([]models.Trade, error) {
 	return s.repo.ListTrades(userID)
 }
 
+// ListTradesByTag retrieves the user's trades labelled with the given tag id or name
+func (s *TradeService) ListTradesByTag(userID, tag string) ([]models.Trade, error) {
+	return s.repo.ListTradesByTag(userID, tag)
+}
+
 func (s *TradeService) CreateTrade(userID string, trade models.Trade) (*models.Trade, error) {
-	return s.repo.CreateTrade(userID, trade)
+	if len(trade.Tags) > 0 {
+		tagIDs := make([]string, len(trade.Tags))
+		for i, tag := range trade.Tags {
+			tagIDs[i] = tag.ID
+		}
+		trade.Tags = nil
+		for _, tagID := range uniqueTagIDs(tagIDs) {
+			trade.Tags = append(trade.Tags, models.Tag{ID: tagID})
+		}
+	}
+
+	created, err := s.repo.CreateTrade(userID, trade)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recalculateSnapshots(userID, created.TradeDate)
+	return created, nil
 }
 
 func (s *TradeService) UpdateTrade(userID, tradeID string, req models.TradeUpdateRequest) (*models.Trade, error) {
-	return s.repo.UpdateTrade(userID, tradeID, req)
+	if req.TagIDs != nil {
+		tagIDs := uniqueTagIDs(*req.TagIDs)
+		req.TagIDs = &tagIDs
+	}
+
+	previous, err := s.repo.GetTrade(userID, tradeID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.UpdateTrade(userID, tradeID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// moving a trade later still invalidates the days it used to count on
+	affectedFrom := updated.TradeDate
+	if previous.TradeDate.Before(affectedFrom) {
+		affectedFrom = previous.TradeDate
+	}
+	s.recalculateSnapshots(userID, affectedFrom)
+	return updated, nil
 }
 
 func (s *TradeService) DeleteTrade(userID, tradeID string) (bool, error) {
-	return s.repo.DeleteTrade(userID, tradeID)
+	previous, err := s.repo.GetTrade(userID, tradeID)
+	if err != nil {
+		// missing trades are reported by the delete itself
+		return s.repo.DeleteTrade(userID, tradeID)
+	}
+
+	deleted, err := s.repo.DeleteTrade(userID, tradeID)
+	if err != nil {
+		return false, err
+	}
+
+	if deleted {
+		s.recalculateSnapshots(userID, previous.TradeDate)
+	}
+	return deleted, nil
+}
+
+// recalculateSnapshots queues the rebuild of snapshots from tradeDate on. The trade change itself
+// has already been saved, so a failure here is only logged.
+func (s *TradeService) recalculateSnapshots(userID string, tradeDate time.Time) {
+	if err := s.snapshotJobSvc.RecalculateFrom(userID, tradeDate); err != nil {
+		log.Printf("Failed to queue snapshot recalculation for user %s: %v", userID, err)
+	}
 }
 
 func (s *TradeService) IsAccountOwnedByUser(accountID, userID string) (bool, error) {
@@ -47,3 +119,21 @@ func (s *TradeService) IsAccountOwnedByUser(accountID, userID string) (bool, err
 func (s *TradeService) IsTradeOwnedByUser(tradeID, userID string) (bool, error) {
 	return s.repo.IsTradeOwnedByUser(tradeID, userID)
 }
+
+func (s *TradeService) AreTagsOwnedByUser(tagIDs []string, userID string) (bool, error) {
+	return s.repo.AreTagsOwnedByUser(uniqueTagIDs(tagIDs), userID)
+}
+
+// uniqueTagIDs drops repeated tag ids, so naming a tag twice neither fails the ownership check
+// nor links the tag to the trade twice
+func uniqueTagIDs(tagIDs []string) []string {
+	seen := make(map[string]bool, len(tagIDs))
+	unique := make([]string, 0, len(tagIDs))
+	for _, tagID := range tagIDs {
+		if !seen[tagID] {
+			seen[tagID] = true
+			unique = append(unique, tagID)
+		}
+	}
+	return unique
+}
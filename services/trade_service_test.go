@@ -0,0 +1,207 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"asset-diary/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTradeRepository is a mock implementation of TradeRepositoryInterface
+type MockTradeRepository struct {
+	mock.Mock
+}
+
+func (m *MockTradeRepository) ListTrades(userID string) ([]models.Trade, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.Trade), args.Error(1)
+}
+
+func (m *MockTradeRepository) ListTradesByTag(userID, tag string) ([]models.Trade, error) {
+	args := m.Called(userID, tag)
+	return args.Get(0).([]models.Trade), args.Error(1)
+}
+
+func (m *MockTradeRepository) GetTrade(userID, tradeID string) (*models.Trade, error) {
+	args := m.Called(userID, tradeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Trade), args.Error(1)
+}
+
+func (m *MockTradeRepository) CreateTrade(userID string, trade models.Trade) (*models.Trade, error) {
+	args := m.Called(userID, trade)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Trade), args.Error(1)
+}
+
+func (m *MockTradeRepository) UpdateTrade(userID, tradeID string, req models.TradeUpdateRequest) (*models.Trade, error) {
+	args := m.Called(userID, tradeID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Trade), args.Error(1)
+}
+
+func (m *MockTradeRepository) DeleteTrade(userID, tradeID string) (bool, error) {
+	args := m.Called(userID, tradeID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTradeRepository) IsAccountOwnedByUser(accountID, userID string) (bool, error) {
+	args := m.Called(accountID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTradeRepository) IsTradeOwnedByUser(tradeID, userID string) (bool, error) {
+	args := m.Called(tradeID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTradeRepository) AreTagsOwnedByUser(tagIDs []string, userID string) (bool, error) {
+	args := m.Called(tagIDs, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTradeRepository) ListHeldSymbols() ([]models.PriceSymbol, error) {
+	args := m.Called()
+	return args.Get(0).([]models.PriceSymbol), args.Error(1)
+}
+
+// MockSnapshotJobService is a mock implementation of SnapshotJobServiceInterface
+type MockSnapshotJobService struct {
+	mock.Mock
+}
+
+func (m *MockSnapshotJobService) StartBackfill(userID string, req models.SnapshotBackfillRequest) (*models.SnapshotJob, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SnapshotJob), args.Error(1)
+}
+
+func (m *MockSnapshotJobService) StartBackfillForAllUsers(req models.SnapshotBackfillRequest) ([]models.SnapshotJob, error) {
+	args := m.Called(req)
+	return args.Get(0).([]models.SnapshotJob), args.Error(1)
+}
+
+func (m *MockSnapshotJobService) GetJob(userID, jobID string) (*models.SnapshotJob, error) {
+	args := m.Called(userID, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SnapshotJob), args.Error(1)
+}
+
+func (m *MockSnapshotJobService) ListJobs(userID string) ([]models.SnapshotJob, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.SnapshotJob), args.Error(1)
+}
+
+func (m *MockSnapshotJobService) RecalculateFrom(userID string, fromDate time.Time) error {
+	args := m.Called(userID, fromDate)
+	return args.Error(0)
+}
+
+func TestTradeChangesRecalculateSnapshots(t *testing.T) {
+	t.Run("create recalculates from the trade date", func(t *testing.T) {
+		repo := new(MockTradeRepository)
+		jobs := new(MockSnapshotJobService)
+		service := NewTradeService(repo, jobs)
+
+		trade := models.Trade{Ticker: "2330", TradeDate: date(2025, 3, 1)}
+		repo.On("CreateTrade", "user1", trade).Return(&models.Trade{ID: "t1", Ticker: "2330", TradeDate: date(2025, 3, 1)}, nil)
+		jobs.On("RecalculateFrom", "user1", date(2025, 3, 1)).Return(nil)
+
+		_, err := service.CreateTrade("user1", trade)
+
+		assert.NoError(t, err)
+		jobs.AssertExpectations(t)
+	})
+
+	t.Run("update recalculates from the earlier of the old and new dates", func(t *testing.T) {
+		repo := new(MockTradeRepository)
+		jobs := new(MockSnapshotJobService)
+		service := NewTradeService(repo, jobs)
+
+		req := models.TradeUpdateRequest{TradeDate: "2025-06-01"}
+		repo.On("GetTrade", "user1", "t1").Return(&models.Trade{ID: "t1", TradeDate: date(2025, 3, 1)}, nil)
+		repo.On("UpdateTrade", "user1", "t1", req).Return(&models.Trade{ID: "t1", TradeDate: date(2025, 6, 1)}, nil)
+		jobs.On("RecalculateFrom", "user1", date(2025, 3, 1)).Return(nil)
+
+		_, err := service.UpdateTrade("user1", "t1", req)
+
+		assert.NoError(t, err)
+		jobs.AssertExpectations(t)
+	})
+
+	t.Run("delete of a missing trade does not recalculate", func(t *testing.T) {
+		repo := new(MockTradeRepository)
+		jobs := new(MockSnapshotJobService)
+		service := NewTradeService(repo, jobs)
+
+		repo.On("GetTrade", "user1", "t1").Return(&models.Trade{ID: "t1", TradeDate: date(2025, 3, 1)}, nil)
+		repo.On("DeleteTrade", "user1", "t1").Return(false, nil)
+
+		deleted, err := service.DeleteTrade("user1", "t1")
+
+		assert.NoError(t, err)
+		assert.False(t, deleted)
+		jobs.AssertNotCalled(t, "RecalculateFrom", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTradeTagsAreDeduplicated(t *testing.T) {
+	t.Run("a repeated tag passes the ownership check", func(t *testing.T) {
+		repo := new(MockTradeRepository)
+		service := NewTradeService(repo, new(MockSnapshotJobService))
+
+		repo.On("AreTagsOwnedByUser", []string{"tag-1", "tag-2"}, "user1").Return(true, nil)
+
+		owned, err := service.AreTagsOwnedByUser([]string{"tag-1", "tag-2", "tag-1"}, "user1")
+
+		assert.NoError(t, err)
+		assert.True(t, owned)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("create links a repeated tag once", func(t *testing.T) {
+		repo := new(MockTradeRepository)
+		jobs := new(MockSnapshotJobService)
+		service := NewTradeService(repo, jobs)
+
+		trade := models.Trade{Ticker: "2330", TradeDate: date(2025, 3, 1), Tags: []models.Tag{{ID: "tag-1"}, {ID: "tag-1"}}}
+		expected := models.Trade{Ticker: "2330", TradeDate: date(2025, 3, 1), Tags: []models.Tag{{ID: "tag-1"}}}
+		repo.On("CreateTrade", "user1", expected).Return(&expected, nil)
+		jobs.On("RecalculateFrom", "user1", date(2025, 3, 1)).Return(nil)
+
+		_, err := service.CreateTrade("user1", trade)
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("update replaces the tags with each tag once", func(t *testing.T) {
+		repo := new(MockTradeRepository)
+		jobs := new(MockSnapshotJobService)
+		service := NewTradeService(repo, jobs)
+
+		tagIDs := []string{"tag-1", "tag-1"}
+		expected := []string{"tag-1"}
+		repo.On("GetTrade", "user1", "t1").Return(&models.Trade{ID: "t1", TradeDate: date(2025, 3, 1)}, nil)
+		repo.On("UpdateTrade", "user1", "t1", models.TradeUpdateRequest{TagIDs: &expected}).Return(&models.Trade{ID: "t1", TradeDate: date(2025, 3, 1)}, nil)
+		jobs.On("RecalculateFrom", "user1", date(2025, 3, 1)).Return(nil)
+
+		_, err := service.UpdateTrade("user1", "t1", models.TradeUpdateRequest{TagIDs: &tagIDs})
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+}